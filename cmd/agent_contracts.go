@@ -12,6 +12,7 @@ type commandTrait struct {
 
 var commandTraits = map[string]commandTrait{
 	"list":               {Intent: "discover", SideEffects: "none", Idempotent: true},
+	"aliases":            {Intent: "discover", SideEffects: "none", Idempotent: true},
 	"print-default":      {Intent: "discover", SideEffects: "none", Idempotent: true},
 	"schema":             {Intent: "discover", SideEffects: "none", Idempotent: true},
 	"search":             {Intent: "read", SideEffects: "none", Idempotent: true},
@@ -32,6 +33,7 @@ var commandTraits = map[string]commandTrait{
 	"note prepend":       {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
 	"note delete":        {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
 	"note move":          {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
+	"folder move":        {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
 	"prop set":           {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
 	"prop delete":        {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
 	"prop get":           {Intent: "read", SideEffects: "none", Idempotent: true},
@@ -40,6 +42,18 @@ var commandTraits = map[string]commandTrait{
 	"tag search":         {Intent: "discover", SideEffects: "none", Idempotent: true},
 	"links list":         {Intent: "read", SideEffects: "none", Idempotent: true},
 	"links backlinks":    {Intent: "read", SideEffects: "none", Idempotent: true},
+	"links unresolved":   {Intent: "read", SideEffects: "none", Idempotent: true},
+	"links orphans":      {Intent: "read", SideEffects: "none", Idempotent: true},
+	"links deadends":     {Intent: "read", SideEffects: "none", Idempotent: true},
+	"links anchors":      {Intent: "read", SideEffects: "none", Idempotent: true},
+	"links mentions":     {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
+	"trash list":         {Intent: "discover", SideEffects: "none", Idempotent: true},
+	"trash restore":      {Intent: "mutate", SideEffects: "writes", Mutating: true},
+	"trash empty":        {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
+	"history list":       {Intent: "discover", SideEffects: "none", Idempotent: true},
+	"history show":       {Intent: "read", SideEffects: "none", Idempotent: true},
+	"history restore":    {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
+	"diff":               {Intent: "read", SideEffects: "none", Idempotent: true},
 	"task":               {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
 	"tasks":              {Intent: "read", SideEffects: "none", Idempotent: true},
 	"template read":      {Intent: "read", SideEffects: "none", Idempotent: true},
@@ -55,7 +69,29 @@ var commandTraits = map[string]commandTrait{
 	"vault init":         {Intent: "mutate", SideEffects: "writes", Mutating: true},
 	"vault migrate":      {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
 	"vault status":       {Intent: "discover", SideEffects: "none", Idempotent: true},
+	"vault commit":       {Intent: "mutate", SideEffects: "writes", Mutating: true},
+	"vault log":          {Intent: "discover", SideEffects: "none", Idempotent: true},
+	"index rebuild":      {Intent: "maintain", SideEffects: "writes_index", Idempotent: true},
+	"index status":       {Intent: "discover", SideEffects: "none", Idempotent: true},
+	"index clear":        {Intent: "maintain", SideEffects: "writes_index", Idempotent: true},
+	"query":              {Intent: "read", SideEffects: "none", Idempotent: true},
+	"base list":          {Intent: "discover", SideEffects: "none", Idempotent: true},
+	"base views":         {Intent: "read", SideEffects: "none", Idempotent: true},
+	"base query":         {Intent: "read", SideEffects: "none", Idempotent: true},
+	"canvas list":        {Intent: "discover", SideEffects: "none", Idempotent: true},
+	"canvas read":        {Intent: "read", SideEffects: "none", Idempotent: true},
+	"canvas add-node":    {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
+	"canvas add-edge":    {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
 	"ops apply":          {Intent: "mutate", SideEffects: "writes", Mutating: true},
+
+	"note heading rename": {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
+	"note split":          {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
+	"note merge":          {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
+
+	"attachments list":    {Intent: "discover", SideEffects: "none", Idempotent: true},
+	"attachments unused":  {Intent: "read", SideEffects: "none", Idempotent: true},
+	"attachments missing": {Intent: "read", SideEffects: "none", Idempotent: true},
+	"attachments move":    {Intent: "mutate", SideEffects: "writes", Mutating: true, SupportsDryRun: true},
 }
 
 func traitForCommand(cmd *cobra.Command) commandTrait {
@@ -4,6 +4,8 @@ import (
 	"os"
 	"time"
 
+	"github.com/nightisyang/obsidian-cli/internal/app"
+	"github.com/nightisyang/obsidian-cli/internal/backend"
 	"github.com/nightisyang/obsidian-cli/internal/index"
 )
 
@@ -13,6 +15,8 @@ type operationMetadata struct {
 	CacheStatus        string `json:"cache_status,omitempty"`
 	Truncated          bool   `json:"truncated"`
 	Strict             bool   `json:"strict"`
+	RequestedMode      string `json:"requested_mode,omitempty"`
+	ServedBy           string `json:"served_by,omitempty"`
 }
 
 func newOperationMetadata(strict bool) operationMetadata {
@@ -22,6 +26,13 @@ func newOperationMetadata(strict bool) operationMetadata {
 	}
 }
 
+// withBackendRoute records which backend served the call that produced the payload.
+func (m operationMetadata) withBackendRoute(rt *app.Runtime) operationMetadata {
+	m.RequestedMode = rt.RequestedMode
+	m.ServedBy = backend.ServedBy(rt.Backend)
+	return m
+}
+
 func sourceFileMTimeMax(vaultRoot string) (string, error) {
 	files, err := index.ListMarkdownFiles(vaultRoot)
 	if err != nil {
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newAliasesCmd() *cobra.Command {
+	var conflictsOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "aliases",
+		Short: "List frontmatter aliases and the notes they resolve to",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			snap, _, err := store.Open(rt.VaultRoot, rt.Config.IndexDir).Refresh()
+			if err != nil {
+				return err
+			}
+			aliases := []store.Alias{}
+			for _, alias := range snap.Aliases() {
+				if !conflictsOnly || alias.Conflict {
+					aliases = append(aliases, alias)
+				}
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(aliases)
+			}
+			for _, alias := range aliases {
+				line := fmt.Sprintf("%s\t%s", alias.Alias, strings.Join(alias.Paths, ", "))
+				if alias.Shadowed != "" {
+					line += "\tshadowed by " + alias.Shadowed
+				} else if alias.Conflict {
+					line += "\tconflict"
+				}
+				rt.Printer.Println(line)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&conflictsOnly, "conflicts", false, "Only list aliases shared by several notes or shadowed by a file name")
+	return cmd
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nightisyang/obsidian-cli/internal/index"
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+)
+
+func TestAliasesResolveLinksAndNoteLookup(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"projects/Roadmap.md": "---\ntitle: Product Roadmap\naliases: [Q3 Plan, Shared]\n---\nroadmap",
+		"Plan.md":             "---\naliases: [Shared]\n---\nplan",
+		"daily.md":            "See [[Q3 Plan]] and [[Q3 Plan#Goals|goals]].",
+	}
+	for name, content := range files {
+		abs := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(abs, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "links", "backlinks", "projects/Roadmap.md")
+	if err != nil {
+		t.Fatalf("backlinks: %v (stderr=%q)", err, stderr)
+	}
+	var backlinks []index.Backlink
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &backlinks); err != nil || len(backlinks) != 1 || len(backlinks[0].Links) != 2 {
+		t.Fatalf("expected alias backlinks from daily.md, got %s (%v)", stdout, err)
+	}
+
+	for _, ref := range []string{"Q3 Plan", "product roadmap", "Roadmap"} {
+		stdout, stderr, err = runCLI(t, "--vault", root, "--json", "note", "get", ref)
+		if err != nil {
+			t.Fatalf("note get %q: %v (stderr=%q)", ref, err, stderr)
+		}
+		var n note.Note
+		if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &n); err != nil || n.Path != "projects/Roadmap.md" {
+			t.Fatalf("note get %q resolved to %s (%v)", ref, stdout, err)
+		}
+	}
+	if _, _, err := runCLI(t, "--vault", root, "note", "get", "Shared"); err == nil || !strings.Contains(err.Error(), "matches several notes") {
+		t.Fatalf("expected ambiguous alias error, got %v", err)
+	}
+
+	stdout, _, err = runCLI(t, "--vault", root, "--json", "aliases", "--conflicts")
+	if err != nil {
+		t.Fatalf("aliases: %v", err)
+	}
+	var aliases []store.Alias
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &aliases); err != nil {
+		t.Fatalf("decode aliases: %v", err)
+	}
+	if len(aliases) != 1 || aliases[0].Alias != "Shared" || len(aliases[0].Paths) != 2 || !aliases[0].Conflict {
+		t.Fatalf("unexpected alias conflicts: %+v", aliases)
+	}
+}
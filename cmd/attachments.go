@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/app"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newAttachmentsCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "attachments", Short: "Attachment operations"}
+	cmd.AddCommand(newAttachmentsListCmd())
+	cmd.AddCommand(newAttachmentsUnusedCmd())
+	cmd.AddCommand(newAttachmentsMissingCmd())
+	cmd.AddCommand(newAttachmentsMoveCmd())
+	return cmd
+}
+
+type attachmentsReport struct {
+	Folder      string             `json:"folder"`
+	Count       int                `json:"count"`
+	Bytes       int64              `json:"bytes"`
+	Attachments []store.Attachment `json:"attachments"`
+}
+
+// loadAttachments lists the attachments under prefix with their
+// referencing notes, using a freshly refreshed index.
+func loadAttachments(rt *app.Runtime, prefix string) ([]store.Attachment, error) {
+	s := store.Open(rt.VaultRoot, rt.Config.IndexDir)
+	snap, _, err := s.Refresh()
+	if err != nil {
+		return nil, err
+	}
+	resolver, err := s.Resolver(snap)
+	if err != nil {
+		return nil, err
+	}
+	return s.Attachments(snap, resolver, prefix)
+}
+
+func printAttachments(rt *app.Runtime, folder string, attachments []store.Attachment) error {
+	report := attachmentsReport{Folder: folder, Count: len(attachments), Attachments: attachments}
+	for _, a := range attachments {
+		report.Bytes += a.Size
+	}
+	if rt.Printer.JSON {
+		return rt.Printer.PrintJSON(report)
+	}
+	for _, a := range attachments {
+		line := fmt.Sprintf("%s\t%d", a.Path, a.Size)
+		if len(a.EmbeddedBy) > 0 {
+			line += "\tembedded by: " + strings.Join(a.EmbeddedBy, ", ")
+		}
+		if len(a.LinkedBy) > 0 {
+			line += "\tlinked by: " + strings.Join(a.LinkedBy, ", ")
+		}
+		rt.Printer.Println(line)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newAttachmentsListCmd() *cobra.Command {
+	var prefix string
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List attachments and the notes that embed them",
+		Long:  "List attachments in the folder set by attachmentFolderPath in .obsidian/app.json, or the whole vault when attachments are stored next to notes.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			folder := store.AttachmentFolder(rt.VaultRoot)
+			if all {
+				prefix = ""
+			} else if prefix == "" {
+				prefix = store.AttachmentScope(folder)
+			}
+			attachments, err := loadAttachments(rt, prefix)
+			if err != nil {
+				return err
+			}
+			return printAttachments(rt, folder, attachments)
+		},
+	}
+	cmd.Flags().StringVar(&prefix, "path", "", "Only list attachments under this folder instead of the attachment folder")
+	cmd.Flags().BoolVar(&all, "all", false, "List attachments anywhere in the vault")
+	return cmd
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+func newAttachmentsMissingCmd() *cobra.Command {
+	var prefix string
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "missing",
+		Short: "List embeds and links to attachments that do not exist",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			snap, resolver, err := linkReportSnapshot(rt)
+			if err != nil {
+				return err
+			}
+			return printUnresolved(rt, snap.MissingAttachments(resolver, prefix), strict, "missing_attachments_found", "Restore the missing files or fix the embed targets.")
+		},
+	}
+	cmd.Flags().StringVar(&prefix, "path", "", "Only report links from notes under this folder")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail when any missing attachment is found")
+	return cmd
+}
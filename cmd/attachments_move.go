@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/spf13/cobra"
+)
+
+func newAttachmentsMoveCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "move <src> <dst>",
+		Short: "Move or rename an attachment and rewrite the embeds and links to it",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			_, resolver, err := linkReportSnapshot(rt)
+			if err != nil {
+				return err
+			}
+			res, err := note.MoveAttachment(rt.VaultRoot, rt.History, args[0], args[1], func(sourceRel string, link note.Link) string {
+				return resolver.Resolve(sourceRel, link.Key(sourceRel)).Path
+			}, dryRun)
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(res)
+			}
+			prefix := "moved"
+			if dryRun {
+				prefix = "dry-run: would move"
+			}
+			rt.Printer.Println(prefix + " " + res.From + " -> " + res.To)
+			for _, rel := range res.Rewritten {
+				rt.Printer.Println("rewrite: " + rel)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview operation without writing files")
+	return cmd
+}
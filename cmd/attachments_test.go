@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAttachmentsListUnusedMissingAndMove(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".obsidian/app.json": `{"attachmentFolderPath":"assets"}`,
+		"assets/diagram.png": "png",
+		"assets/spec.pdf":    "pdf",
+		"assets/unused.jpg":  "jpg-bytes",
+		"notes/loose.gif":    "gif",
+		"Plan.md":            "![[diagram.png]] ![d](assets/diagram.png) [spec](assets/spec.pdf) ![[gone.png]]\n",
+		"board.canvas":       `{"nodes":[{"id":"1","type":"file","file":"notes/loose.gif","x":0,"y":0,"width":10,"height":10}],"edges":[]}`,
+		"notes/Other.md":     "no embeds\n",
+	}
+	for name, content := range files {
+		abs := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(abs, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "attachments", "list")
+	if err != nil {
+		t.Fatalf("list: %v (stderr=%q)", err, stderr)
+	}
+	var list attachmentsReport
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if list.Folder != "assets" || list.Count != 3 || list.Attachments[0].Path != "assets/diagram.png" || len(list.Attachments[0].EmbeddedBy) != 1 || list.Attachments[1].LinkedBy[0] != "Plan.md" {
+		t.Fatalf("unexpected list %+v", list)
+	}
+
+	stdout, _, err = runCLI(t, "--vault", root, "--json", "attachments", "unused")
+	if err != nil {
+		t.Fatalf("unused: %v", err)
+	}
+	var unused attachmentsReport
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &unused); err != nil || unused.Count != 1 || unused.Attachments[0].Path != "assets/unused.jpg" || unused.Bytes != 9 {
+		t.Fatalf("unexpected unused report %s (%v)", stdout, err)
+	}
+
+	stdout, _, err = runCLI(t, "--vault", root, "--json", "attachments", "missing")
+	if err != nil {
+		t.Fatalf("missing: %v", err)
+	}
+	var missing unresolvedReport
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &missing); err != nil || missing.Count != 1 || missing.Targets[0].Target != "gone.png" {
+		t.Fatalf("unexpected missing report %s (%v)", stdout, err)
+	}
+
+	if _, stderr, err := runCLI(t, "--vault", root, "attachments", "move", "assets/diagram.png", "assets/img/"); err != nil {
+		t.Fatalf("move: %v (stderr=%q)", err, stderr)
+	}
+	raw, err := os.ReadFile(filepath.Join(root, "Plan.md"))
+	if want := "![[assets/img/diagram.png]] ![d](assets/img/diagram.png) [spec](assets/spec.pdf) ![[gone.png]]\n"; err != nil || string(raw) != want {
+		t.Fatalf("Plan.md = %q (%v), want %q", raw, err, want)
+	}
+	if _, err := os.Stat(filepath.Join(root, "assets", "img", "diagram.png")); err != nil {
+		t.Fatalf("expected moved attachment: %v", err)
+	}
+	if _, _, err := runCLI(t, "--vault", root, "attachments", "move", "assets/spec.pdf", "assets/unused.jpg"); err == nil {
+		t.Fatal("expected refusal to overwrite an existing file")
+	}
+}
+
+func TestAttachmentsUnusedIgnoresHiddenAndIndexFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".obsidian-cli.yaml": "index_dir: cache\n",
+		".DS_Store":          "finder",
+		"cache/blob.png":     "index",
+		"notes.txt":          "plain",
+		"photo.png":          "png",
+		"Plan.md":            "no embeds\n",
+	}
+	for name, content := range files {
+		abs := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(abs, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "attachments", "unused")
+	if err != nil {
+		t.Fatalf("unused: %v (stderr=%q)", err, stderr)
+	}
+	var unused attachmentsReport
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &unused); err != nil || unused.Count != 1 || unused.Attachments[0].Path != "photo.png" {
+		t.Fatalf("unexpected unused report %s (%v)", stdout, err)
+	}
+}
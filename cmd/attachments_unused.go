@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newAttachmentsUnusedCmd() *cobra.Command {
+	var prefix string
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "unused",
+		Short: "List attachments that no note or canvas embeds or links to",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			attachments, err := loadAttachments(rt, prefix)
+			if err != nil {
+				return err
+			}
+			unused := []store.Attachment{}
+			for _, a := range attachments {
+				if !a.Used() {
+					unused = append(unused, a)
+				}
+			}
+			if strict && len(unused) > 0 {
+				return errs.NewDetailed(
+					errs.ExitValidation,
+					"unused_attachments_found",
+					"Delete or embed the listed attachments, or rerun without --strict.",
+					fmt.Sprintf("%d unused attachments", len(unused)),
+				)
+			}
+			return printAttachments(rt, store.AttachmentFolder(rt.VaultRoot), unused)
+		},
+	}
+	cmd.Flags().StringVar(&prefix, "path", "", "Only report attachments under this folder")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail when any unused attachment is found")
+	return cmd
+}
@@ -0,0 +1,11 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+func newBaseCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "base", Short: "Obsidian Bases (.base files)"}
+	cmd.AddCommand(newBaseListCmd())
+	cmd.AddCommand(newBaseViewsCmd())
+	cmd.AddCommand(newBaseQueryCmd())
+	return cmd
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/bases"
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newBaseQueryCmd() *cobra.Command {
+	var view string
+	var format string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "query <file>",
+		Short: "Evaluate a base view against the vault",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(strings.TrimSpace(format)) {
+			case "":
+				format = "csv"
+				if rt.Printer.JSON {
+					format = "json"
+				}
+			case "json", "csv":
+				format = strings.ToLower(strings.TrimSpace(format))
+			default:
+				return errs.New(errs.ExitValidation, "unsupported --format "+format+", use json or csv")
+			}
+			if format == "csv" && rt.Printer.JSON {
+				return errs.New(errs.ExitValidation, "--format csv cannot be combined with --json")
+			}
+
+			b, err := bases.Load(rt.VaultRoot, args[0])
+			if err != nil {
+				return err
+			}
+			snap, _, err := store.Open(rt.VaultRoot, rt.Config.IndexDir).Refresh()
+			if err != nil {
+				return err
+			}
+			result, err := bases.Query(rt.VaultRoot, b, snap, bases.QueryOptions{View: view, Limit: limit})
+			if err != nil {
+				return err
+			}
+			if format == "json" {
+				return rt.Printer.PrintJSON(result)
+			}
+			return bases.WriteCSV(os.Stdout, result)
+		},
+	}
+
+	cmd.Flags().StringVar(&view, "view", "", "View name (default: first view)")
+	cmd.Flags().StringVar(&format, "format", "", "Output format: json|csv (default csv, or json with --json)")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Override the view's row limit")
+	return cmd
+}
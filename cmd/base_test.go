@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBaseCommands(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"a.md":       "---\nstatus: active\npriority: 2\n---\nbody\n",
+		"b.md":       "---\nstatus: active\npriority: 7\n---\nbody\n",
+		"c.md":       "---\nstatus: done\n---\nbody\n",
+		"tasks.base": "filters: 'status == \"active\"'\nviews:\n  - type: table\n    name: Top\n    order: [file.basename, priority]\n    sort:\n      - property: priority\n        direction: DESC\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "base", "list")
+	if err != nil {
+		t.Fatalf("base list error: %v (stderr=%q)", err, stderr)
+	}
+	var paths []string
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &paths); err != nil || len(paths) != 1 || paths[0] != "tasks.base" {
+		t.Fatalf("unexpected base list: %s (%v)", stdout, err)
+	}
+
+	stdout, stderr, err = runCLI(t, "--vault", root, "--json", "base", "query", "tasks", "--view", "Top")
+	if err != nil {
+		t.Fatalf("base query error: %v (stderr=%q)", err, stderr)
+	}
+	var result struct {
+		View string           `json:"view"`
+		Rows []map[string]any `json:"rows"`
+	}
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &result); err != nil {
+		t.Fatalf("decode base query: %v", err)
+	}
+	if result.View != "Top" || len(result.Rows) != 2 || result.Rows[0]["path"] != "b.md" || result.Rows[0]["priority"] != 7.0 {
+		t.Fatalf("unexpected base query result: %+v", result)
+	}
+
+	stdout, stderr, err = runCLI(t, "--vault", root, "base", "query", "tasks.base", "--format", "csv")
+	if err != nil {
+		t.Fatalf("base csv error: %v (stderr=%q)", err, stderr)
+	}
+	if stdout != "path,file.basename,priority\nb.md,b,7\na.md,a,2\n" {
+		t.Fatalf("unexpected csv: %q", stdout)
+	}
+
+	if _, _, err := runCLI(t, "--vault", root, "base", "query", "tasks", "--view", "missing"); err == nil {
+		t.Fatalf("expected error for unknown view")
+	}
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nightisyang/obsidian-cli/internal/bases"
+	"github.com/spf13/cobra"
+)
+
+func newBaseViewsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "views <file>",
+		Short: "Show the views, filters and formulas defined in a base",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			b, err := bases.Load(rt.VaultRoot, args[0])
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(b)
+			}
+			for _, v := range b.Views {
+				rt.Printer.Println(fmt.Sprintf("%s\t%s", v.Name, v.Type))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/nightisyang/obsidian-cli/internal/canvas"
+	"github.com/spf13/cobra"
+)
+
+func newCanvasCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "canvas", Short: "JSON Canvas (.canvas) operations"}
+	cmd.AddCommand(newCanvasListCmd())
+	cmd.AddCommand(newCanvasReadCmd())
+	cmd.AddCommand(newCanvasAddNodeCmd())
+	cmd.AddCommand(newCanvasAddEdgeCmd())
+	return cmd
+}
+
+type canvasPayload struct {
+	Path  string        `json:"path"`
+	Nodes []canvas.Node `json:"nodes"`
+	Edges []canvas.Edge `json:"edges"`
+}
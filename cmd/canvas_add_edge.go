@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"github.com/nightisyang/obsidian-cli/internal/canvas"
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/spf13/cobra"
+)
+
+func newCanvasAddEdgeCmd() *cobra.Command {
+	var edge canvas.Edge
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "add-edge <file>",
+		Short: "Connect two nodes of a canvas",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			if edge.FromNode == "" || edge.ToNode == "" {
+				return errs.New(errs.ExitValidation, "--from and --to are required")
+			}
+			c, rel, err := canvas.Load(rt.VaultRoot, args[0])
+			if err != nil {
+				return err
+			}
+			added, err := c.AddEdge(edge)
+			if err != nil {
+				return err
+			}
+			if !dryRun {
+				if err := canvas.Save(rt.VaultRoot, rt.History, rel, c); err != nil {
+					return err
+				}
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(map[string]any{"path": rel, "edge": added, "dry_run": dryRun})
+			}
+			if dryRun {
+				rt.Printer.Println("dry-run: would add edge " + added.ID + " to " + rel)
+				return nil
+			}
+			rt.Printer.Println("added edge " + added.ID + " to " + rel)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&edge.FromNode, "from", "", "Source node id")
+	cmd.Flags().StringVar(&edge.ToNode, "to", "", "Target node id")
+	cmd.Flags().StringVar(&edge.FromSide, "from-side", "", "Source side: top|right|bottom|left")
+	cmd.Flags().StringVar(&edge.ToSide, "to-side", "", "Target side: top|right|bottom|left")
+	cmd.Flags().StringVar(&edge.Label, "label", "", "Edge label")
+	cmd.Flags().StringVar(&edge.Color, "color", "", "Preset color 1-6 or hex color")
+	cmd.Flags().StringVar(&edge.ID, "id", "", "Explicit edge id (default: derived from its ends)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview operation without writing files")
+	return cmd
+}
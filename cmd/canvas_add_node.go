@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/canvas"
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/spf13/cobra"
+)
+
+func newCanvasAddNodeCmd() *cobra.Command {
+	var node canvas.Node
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "add-node <file>",
+		Short: "Add a text, file, link or group node to a canvas (creating it if missing)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			switch node.Type {
+			case canvas.NodeText:
+				if node.Text == "" {
+					return errs.New(errs.ExitValidation, "--text is required for text nodes")
+				}
+			case canvas.NodeFile:
+				node.File = filepath.ToSlash(filepath.Clean(strings.TrimPrefix(strings.TrimSpace(node.File), "/")))
+				if node.File == "." {
+					return errs.New(errs.ExitValidation, "--file is required for file nodes")
+				}
+				if _, err := os.Stat(filepath.Join(rt.VaultRoot, filepath.FromSlash(node.File))); err != nil {
+					return errs.New(errs.ExitNotFound, "file not found in vault: "+node.File)
+				}
+			case canvas.NodeLink:
+				if strings.TrimSpace(node.URL) == "" {
+					return errs.New(errs.ExitValidation, "--url is required for link nodes")
+				}
+			}
+
+			c, rel, err := canvas.Load(rt.VaultRoot, args[0])
+			var appErr *errs.AppError
+			if err != nil && !(errors.As(err, &appErr) && appErr.Code == errs.ExitNotFound) {
+				return err
+			}
+			if err != nil {
+				c = canvas.Canvas{}
+			}
+			added, err := c.AddNode(node)
+			if err != nil {
+				return err
+			}
+			if !dryRun {
+				if err := canvas.Save(rt.VaultRoot, rt.History, rel, c); err != nil {
+					return err
+				}
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(map[string]any{"path": rel, "node": added, "dry_run": dryRun})
+			}
+			if dryRun {
+				rt.Printer.Println("dry-run: would add node " + added.ID + " to " + rel)
+				return nil
+			}
+			rt.Printer.Println("added node " + added.ID + " to " + rel)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&node.Type, "type", canvas.NodeText, "Node type: text|file|link|group")
+	cmd.Flags().StringVar(&node.Text, "text", "", "Markdown text for text nodes")
+	cmd.Flags().StringVar(&node.File, "file", "", "Vault path for file nodes")
+	cmd.Flags().StringVar(&node.Subpath, "subpath", "", "Heading or block subpath for file nodes (e.g. #Heading)")
+	cmd.Flags().StringVar(&node.URL, "url", "", "URL for link nodes")
+	cmd.Flags().StringVar(&node.Label, "label", "", "Label for group nodes")
+	cmd.Flags().StringVar(&node.Color, "color", "", "Preset color 1-6 or hex color")
+	cmd.Flags().StringVar(&node.ID, "id", "", "Explicit node id (default: derived from content)")
+	cmd.Flags().IntVar(&node.X, "x", 0, "X position (default: below existing nodes)")
+	cmd.Flags().IntVar(&node.Y, "y", 0, "Y position (default: below existing nodes)")
+	cmd.Flags().IntVar(&node.Width, "width", 0, "Width (default 400)")
+	cmd.Flags().IntVar(&node.Height, "height", 0, "Height (default 200)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview operation without writing files")
+	return cmd
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"github.com/nightisyang/obsidian-cli/internal/canvas"
+	"github.com/spf13/cobra"
+)
+
+func newCanvasListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List .canvas files in the vault",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			paths, err := canvas.List(rt.VaultRoot)
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(paths)
+			}
+			for _, p := range paths {
+				rt.Printer.Println(p)
+			}
+			return nil
+		},
+	}
+}
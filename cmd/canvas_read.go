@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nightisyang/obsidian-cli/internal/canvas"
+	"github.com/spf13/cobra"
+)
+
+func newCanvasReadCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "read <file>",
+		Short: "Dump the nodes and edges of a canvas",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			c, rel, err := canvas.Load(rt.VaultRoot, args[0])
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(canvasPayload{Path: rel, Nodes: c.Nodes, Edges: c.Edges})
+			}
+			for _, n := range c.Nodes {
+				rt.Printer.Println(fmt.Sprintf("node\t%s\t%s\t%s", n.ID, n.Type, canvasNodeContent(n)))
+			}
+			for _, e := range c.Edges {
+				rt.Printer.Println(fmt.Sprintf("edge\t%s\t%s -> %s\t%s", e.ID, e.FromNode, e.ToNode, e.Label))
+			}
+			return nil
+		},
+	}
+}
+
+func canvasNodeContent(n canvas.Node) string {
+	switch n.Type {
+	case canvas.NodeFile:
+		return n.File + n.Subpath
+	case canvas.NodeLink:
+		return n.URL
+	case canvas.NodeGroup:
+		return n.Label
+	default:
+		return n.Text
+	}
+}
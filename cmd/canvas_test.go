@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nightisyang/obsidian-cli/internal/index"
+)
+
+func TestCanvasCommandsAndGraphEdges(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.md"), []byte("alpha"), 0o644); err != nil {
+		t.Fatalf("write a: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "b.md"), []byte("beta"), 0o644); err != nil {
+		t.Fatalf("write b: %v", err)
+	}
+
+	addNode := func(args ...string) string {
+		t.Helper()
+		stdout, stderr, err := runCLI(t, append([]string{"--vault", root, "--json", "canvas", "add-node", "board"}, args...)...)
+		if err != nil {
+			t.Fatalf("add-node %v: %v (stderr=%q)", args, err, stderr)
+		}
+		var payload struct {
+			Node struct {
+				ID string `json:"id"`
+			} `json:"node"`
+		}
+		if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &payload); err != nil {
+			t.Fatalf("decode add-node: %v", err)
+		}
+		return payload.Node.ID
+	}
+	fileID := addNode("--type", "file", "--file", "a.md")
+	textID := addNode("--text", "Related: [[b]]")
+	if _, _, err := runCLI(t, "--vault", root, "canvas", "add-node", "board", "--type", "file", "--file", "nope.md"); err == nil {
+		t.Fatalf("expected error for missing file node target")
+	}
+	if _, stderr, err := runCLI(t, "--vault", root, "canvas", "add-edge", "board", "--from", fileID, "--to", textID, "--label", "see"); err != nil {
+		t.Fatalf("add-edge: %v (stderr=%q)", err, stderr)
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "canvas", "read", "board.canvas")
+	if err != nil {
+		t.Fatalf("canvas read: %v (stderr=%q)", err, stderr)
+	}
+	var dump canvasPayload
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &dump); err != nil {
+		t.Fatalf("decode canvas read: %v", err)
+	}
+	if dump.Path != "board.canvas" || len(dump.Nodes) != 2 || len(dump.Edges) != 1 || dump.Edges[0].Label != "see" {
+		t.Fatalf("unexpected canvas: %+v", dump)
+	}
+
+	stdout, _, err = runCLI(t, "--vault", root, "--json", "links", "backlinks", "a.md")
+	if err != nil {
+		t.Fatalf("backlinks: %v", err)
+	}
+	var backlinks []index.Backlink
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &backlinks); err != nil || len(backlinks) != 1 || backlinks[0].Source != "board.canvas" {
+		t.Fatalf("expected canvas backlink, got %s (%v)", stdout, err)
+	}
+
+	stdout, stderr, err = runCLI(t, "--vault", root, "--json", "graph", "neighborhood", "a.md", "--depth", "2")
+	if err != nil {
+		t.Fatalf("graph neighborhood: %v (stderr=%q)", err, stderr)
+	}
+	var graph struct {
+		Edges []graphEdge `json:"edges"`
+	}
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &graph); err != nil {
+		t.Fatalf("decode graph: %v", err)
+	}
+	want := map[graphEdge]bool{
+		{From: "board.canvas", To: "a.md", Kind: "canvas"}: false,
+		{From: "board.canvas", To: "b.md", Kind: "canvas"}: false,
+	}
+	for _, edge := range graph.Edges {
+		if _, ok := want[edge]; ok {
+			want[edge] = true
+		}
+	}
+	for edge, seen := range want {
+		if !seen {
+			t.Fatalf("missing edge %+v in %+v", edge, graph.Edges)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,3 +19,18 @@ func parseDateFlag(raw string) (time.Time, error) {
 	}
 	return t, nil
 }
+
+// parseAgeFlag parses an age such as "30d", "2w" or any Go duration.
+func parseAgeFlag(raw string) (time.Duration, error) {
+	clean := strings.TrimSpace(raw)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, err := strconv.Atoi(strings.TrimSuffix(clean, suffix)); err == nil && strings.HasSuffix(clean, suffix) && n >= 0 {
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(clean)
+	if err != nil || d < 0 {
+		return 0, errs.New(errs.ExitValidation, "age must look like 30d, 2w or 12h")
+	}
+	return d, nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/history"
+	"github.com/spf13/cobra"
+)
+
+func newDiffCmd() *cobra.Command {
+	var from string
+	var to string
+
+	cmd := &cobra.Command{
+		Use:   "diff <path>",
+		Short: "Show a unified diff between prior versions of a note or the current content",
+		Long:  "Versions are numbers from history list (git revisions in a git-backed vault) or current. --from defaults to the newest prior version and --to to the current content.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			h, err := openHistory(rt, args[0])
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(from) == "" {
+				if from, err = h.latest(); err != nil {
+					return err
+				}
+				if from == "" {
+					return errs.New(errs.ExitNotFound, "no prior versions of "+h.rel)
+				}
+			}
+			a, fromName, err := h.read(from)
+			if err != nil {
+				return err
+			}
+			b, toName, err := h.read(to)
+			if err != nil {
+				return err
+			}
+			diff := history.Unified(fromName, toName, a, b)
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(map[string]any{"path": h.rel, "from": fromName, "to": toName, "changed": diff != "", "diff": diff})
+			}
+			if diff != "" {
+				rt.Printer.Println(strings.TrimSuffix(diff, "\n"))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "Version to diff from (default: newest prior version)")
+	cmd.Flags().StringVar(&to, "to", "current", "Version to diff to (default: current content)")
+	return cmd
+}
@@ -265,3 +265,81 @@ func TestMaxCharsOnNoteGetAndSearch(t *testing.T) {
 		t.Fatalf("expected json search snippet <= 6 chars, got %q", results[0].Snippet)
 	}
 }
+
+func TestSearchQueryExpression(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"alpha.md": "---\nstatus: active\n---\n# Plan\n- [ ] ship release #work\n",
+		"beta.md":  "---\nstatus: draft\n---\nrelease notes #work\n",
+		"gamma.md": "release notes\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "search", "--query", "release tag:work -[status:draft]")
+	if err != nil {
+		t.Fatalf("search --query error: %v (stderr=%q)", err, stderr)
+	}
+	env := parseEnvelope(t, stdout)
+	var results []search.SearchResult
+	if err := json.Unmarshal(env.Data, &results); err != nil {
+		t.Fatalf("decode search json: %v", err)
+	}
+	if len(results) != 1 || results[0].Path != "alpha.md" || results[0].MatchType != "query" || results[0].Line != 5 {
+		t.Fatalf("unexpected query results: %+v", results)
+	}
+
+	if _, _, err := runCLI(t, "--vault", root, "search", "release", "--query", "notes"); err == nil {
+		t.Fatalf("expected error combining query text with --query")
+	}
+	if _, _, err := runCLI(t, "--vault", root, "search", "--query", "(release"); err == nil {
+		t.Fatalf("expected parse error for unbalanced query")
+	}
+}
+
+func TestSearchTypedPropFilters(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"a.md": "---\npriority: 5\ndue: 2024-03-01\ntags: [work, q3]\n---\nA\n",
+		"b.md": "---\npriority: 10\ndue: 2024-07-01\ntags: [work]\n---\nB\n",
+		"c.md": "---\npriority: 1\nowner: ana\n---\nC\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	paths := func(args ...string) []string {
+		t.Helper()
+		stdout, stderr, err := runCLI(t, append([]string{"--vault", root, "--json", "search"}, args...)...)
+		if err != nil {
+			t.Fatalf("search %v error: %v (stderr=%q)", args, err, stderr)
+		}
+		var results []search.SearchResult
+		if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &results); err != nil {
+			t.Fatalf("decode search json: %v", err)
+		}
+		out := []string{}
+		for _, r := range results {
+			out = append(out, r.Path)
+		}
+		return out
+	}
+
+	if got := strings.Join(paths("--prop", "priority>=5"), ","); got != "a.md,b.md" {
+		t.Fatalf("numeric filter: got %q", got)
+	}
+	if got := strings.Join(paths("--prop", "priority>=5", "--prop", "due<2024-06-01"), ","); got != "a.md" {
+		t.Fatalf("combined filters: got %q", got)
+	}
+	if got := strings.Join(paths("--prop", "tags contains q3"), ","); got != "a.md" {
+		t.Fatalf("contains filter: got %q", got)
+	}
+	if got := strings.Join(paths("--prop", "missing:owner"), ","); got != "a.md,b.md" {
+		t.Fatalf("missing filter: got %q", got)
+	}
+}
@@ -0,0 +1,9 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+func newFolderCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "folder", Short: "Folder operations"}
+	cmd.AddCommand(newFolderMoveCmd())
+	return cmd
+}
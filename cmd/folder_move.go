@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newFolderMoveCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "move <src> <dst>",
+		Short: "Move or rename a folder, with its attachments, and rewrite links into it",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			s := store.Open(rt.VaultRoot, rt.Config.IndexDir)
+			snap, _, err := s.Refresh()
+			if err != nil {
+				return err
+			}
+			resolver, err := s.Resolver(snap)
+			if err != nil {
+				return err
+			}
+			res, err := note.MoveFolder(rt.VaultRoot, rt.History, args[0], args[1], func(sourceRel string, link note.Link) string {
+				return resolver.Resolve(sourceRel, link.Key(sourceRel)).Path
+			}, dryRun)
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(res)
+			}
+			prefix := "moved"
+			if dryRun {
+				prefix = "dry-run: would move"
+			}
+			rt.Printer.Println(fmt.Sprintf("%s %s to %s (%d files, %d notes rewritten)", prefix, res.Source, res.Destination, len(res.Moves), len(res.Rewritten)))
+			for _, m := range res.Moves {
+				rt.Printer.Println(m.From + " -> " + m.To)
+			}
+			for _, rel := range res.Rewritten {
+				rt.Printer.Println("rewrite: " + rel)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the full change set without writing files")
+	return cmd
+}
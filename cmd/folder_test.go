@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nightisyang/obsidian-cli/internal/note"
+)
+
+func TestFolderMoveRewritesLinksInOnePass(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"projects/Plan.md":        "See [[Spec]], [spec](./sub/Spec.md) and [home](../Home.md).\n![[diagram.png]]\n",
+		"projects/sub/Spec.md":    "Back to [[projects/Plan#Goals|plan]].\n",
+		"projects/diagram.png":    "png",
+		"Home.md":                 "[[Plan]] [p](projects/Plan.md) ![[projects/diagram.png]] [[Other]]\n",
+		"Other.md":                "other\n",
+		"archive/old/Existing.md": "existing\n",
+	}
+	for name, content := range files {
+		abs := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(abs, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "folder", "move", "projects", "archive/projects", "--dry-run")
+	if err != nil {
+		t.Fatalf("dry run: %v (stderr=%q)", err, stderr)
+	}
+	var res note.FolderMove
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &res); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(res.Moves) != 3 || strings.Join(res.Rewritten, ",") != "Home.md,archive/projects/Plan.md,archive/projects/sub/Spec.md" {
+		t.Fatalf("unexpected dry run %+v", res)
+	}
+	if _, err := os.Stat(filepath.Join(root, "projects", "Plan.md")); err != nil {
+		t.Fatalf("dry run moved files: %v", err)
+	}
+
+	if _, stderr, err := runCLI(t, "--vault", root, "folder", "move", "projects", "archive/projects"); err != nil {
+		t.Fatalf("move: %v (stderr=%q)", err, stderr)
+	}
+	want := map[string]string{
+		"archive/projects/Plan.md":     "See [[archive/projects/sub/Spec]], [spec](./sub/Spec.md) and [home](../../Home.md).\n![[archive/projects/diagram.png]]\n",
+		"archive/projects/sub/Spec.md": "Back to [[archive/projects/Plan#Goals|plan]].\n",
+		"archive/projects/diagram.png": "png",
+		"Home.md":                      "[[archive/projects/Plan]] [p](archive/projects/Plan.md) ![[archive/projects/diagram.png]] [[Other]]\n",
+	}
+	for name, content := range want {
+		raw, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil || string(raw) != content {
+			t.Fatalf("%s = %q (%v), want %q", name, raw, err, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "projects")); !os.IsNotExist(err) {
+		t.Fatalf("expected source folder to be gone, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "archive", "projects", "Readme.md"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, _, err := runCLI(t, "--vault", root, "folder", "move", "archive/old", "archive/projects"); err != nil {
+		t.Fatalf("merge into existing folder: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "archive", "projects", "Existing.md")); err != nil {
+		t.Fatalf("expected merged note: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "archive", "old")); !os.IsNotExist(err) {
+		t.Fatalf("expected emptied folder to be removed, got %v", err)
+	}
+	if _, _, err := runCLI(t, "--vault", root, "folder", "move", "archive/projects", "Other2"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "clash"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "clash", "Plan.md"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, _, err := runCLI(t, "--vault", root, "folder", "move", "Other2", "clash"); err == nil || !strings.Contains(err.Error(), "overwrite existing files: clash/Plan.md") {
+		t.Fatalf("expected overwrite refusal, got %v", err)
+	}
+}
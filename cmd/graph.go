@@ -3,11 +3,13 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/nightisyang/obsidian-cli/internal/app"
+	"github.com/nightisyang/obsidian-cli/internal/canvas"
 	"github.com/nightisyang/obsidian-cli/internal/errs"
 	"github.com/nightisyang/obsidian-cli/internal/search"
 	"github.com/spf13/cobra"
@@ -78,10 +80,11 @@ func newGraphContextCmd() *cobra.Command {
 				nodeLimit = 200
 			}
 
-			q, err := search.BuildQuery(args[0], "", "", seedLimit, 80, pathPrefix, caseSensitive)
+			q, err := search.BuildQuery(args[0], "", nil, seedLimit, 80, pathPrefix, caseSensitive)
 			if err != nil {
 				return err
 			}
+			q.Ranked = true
 			seeds, err := rt.Backend.Search(rt.Context, q)
 			if err != nil {
 				return err
@@ -91,7 +94,7 @@ func newGraphContextCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			metadata := newOperationMetadata(strict)
+			metadata := newOperationMetadata(strict).withBackendRoute(rt)
 			metadata.CacheStatus = "backlinks_in_memory_auto"
 			metadata.Truncated = truncated
 			if max, err := sourceFileMTimeMax(rt.VaultRoot); err == nil {
@@ -163,7 +166,7 @@ func newGraphNeighborhoodCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			metadata := newOperationMetadata(strict)
+			metadata := newOperationMetadata(strict).withBackendRoute(rt)
 			metadata.CacheStatus = "backlinks_in_memory_auto"
 			metadata.Truncated = truncated
 			if max, err := sourceFileMTimeMax(rt.VaultRoot); err == nil {
@@ -221,12 +224,17 @@ func normalizeGraphPath(raw string) string {
 	if trimmed == "" || trimmed == "." {
 		return ""
 	}
-	if !strings.HasSuffix(strings.ToLower(trimmed), ".md") {
+	lower := strings.ToLower(trimmed)
+	if !strings.HasSuffix(lower, ".md") && !strings.HasSuffix(lower, canvas.Ext) {
 		trimmed += ".md"
 	}
 	return filepath.ToSlash(filepath.Clean(trimmed))
 }
 
+func isCanvasPath(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), canvas.Ext)
+}
+
 func expandNeighborhood(rt *app.Runtime, seeds []string, maxDepth int, nodeLimit int, strict bool) ([]graphNode, []graphEdge, []string, bool, error) {
 	nodes := map[string]*graphNode{}
 	edges := []graphEdge{}
@@ -308,6 +316,11 @@ func expandNeighborhood(rt *app.Runtime, seeds []string, maxDepth int, nodeLimit
 		if exists, ok := noteExists[path]; ok {
 			return exists, nil
 		}
+		if isCanvasPath(path) {
+			_, statErr := os.Stat(filepath.Join(rt.VaultRoot, filepath.FromSlash(path)))
+			noteExists[path] = statErr == nil
+			return statErr == nil, nil
+		}
 		_, err := rt.Backend.GetNote(rt.Context, path)
 		if err != nil {
 			var appErr *errs.AppError
@@ -347,6 +360,31 @@ func expandNeighborhood(rt *app.Runtime, seeds []string, maxDepth int, nodeLimit
 			continue
 		}
 
+		if isCanvasPath(item.Path) {
+			c, _, err := canvas.Load(rt.VaultRoot, item.Path)
+			if err != nil {
+				addWarning(fmt.Sprintf("failed to read canvas %s: %v", item.Path, err))
+				if strict {
+					return nil, nil, nil, truncated, err
+				}
+				continue
+			}
+			targets := c.Files()
+			for _, link := range c.TextLinks() {
+				targets = append(targets, normalizeGraphPath(link))
+			}
+			for _, target := range targets {
+				if !addNode(target, false) {
+					continue
+				}
+				addEdge(item.Path, target, "canvas")
+				if targetExists, _ := hasNote(target); targetExists {
+					enqueue(target, item.Depth+1)
+				}
+			}
+			continue
+		}
+
 		outgoing, err := rt.Backend.OutgoingLinks(rt.Context, item.Path)
 		if err != nil {
 			addWarning(fmt.Sprintf("failed to list outgoing links for %s: %v", item.Path, err))
@@ -354,8 +392,18 @@ func expandNeighborhood(rt *app.Runtime, seeds []string, maxDepth int, nodeLimit
 				return nil, nil, nil, truncated, err
 			}
 		} else {
-			for _, raw := range outgoing {
-				target := normalizeGraphPath(raw)
+			for _, link := range outgoing {
+				if len(link.Candidates) > 1 {
+					addWarning(fmt.Sprintf("ambiguous link %s in %s matches %s", link.Raw, item.Path, strings.Join(link.Candidates, ", ")))
+					continue
+				}
+				target := link.Path
+				if target == "" {
+					target = normalizeGraphPath(link.Key(item.Path))
+				}
+				if target == item.Path {
+					continue
+				}
 				if !addNode(target, false) {
 					continue
 				}
@@ -381,12 +429,16 @@ func expandNeighborhood(rt *app.Runtime, seeds []string, maxDepth int, nodeLimit
 				return nil, nil, nil, truncated, err
 			}
 		} else {
-			for _, raw := range backlinks {
-				source := normalizeGraphPath(raw)
+			for _, backlink := range backlinks {
+				source := normalizeGraphPath(backlink.Source)
 				if !addNode(source, false) {
 					continue
 				}
-				addEdge(source, item.Path, "linked_to")
+				kind := "linked_to"
+				if isCanvasPath(source) {
+					kind = "canvas"
+				}
+				addEdge(source, item.Path, kind)
 				enqueue(source, item.Depth+1)
 			}
 		}
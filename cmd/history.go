@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/app"
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/gitvault"
+	"github.com/nightisyang/obsidian-cli/internal/history"
+	"github.com/nightisyang/obsidian-cli/internal/vault"
+	"github.com/spf13/cobra"
+)
+
+func newHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "history", Short: "Prior versions of notes, from git when git_auto_commit is set"}
+	cmd.AddCommand(newHistoryListCmd())
+	cmd.AddCommand(newHistoryShowCmd())
+	cmd.AddCommand(newHistoryRestoreCmd())
+	return cmd
+}
+
+// noteHistory reads the versions of one note, from git commits in a
+// git-backed vault and from the local history store otherwise.
+type noteHistory struct {
+	rt    *app.Runtime
+	rel   string
+	local *history.Store
+	repo  *gitvault.Repo
+}
+
+func openHistory(rt *app.Runtime, path string) (*noteHistory, error) {
+	_, rel, err := vault.ResolveNoteAbs(rt.VaultRoot, path)
+	if err != nil {
+		return nil, err
+	}
+	h := &noteHistory{rt: rt, rel: filepath.ToSlash(rel), local: rt.History}
+	if rt.Config.GitAutoCommit {
+		if h.repo, err = openVaultRepo(rt); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// latest returns the newest version ref whose content differs from the
+// current note, or "" when there is none. In a git-backed vault the newest
+// commit usually matches the note, so older commits are checked too.
+func (h *noteHistory) latest() (string, error) {
+	if h.repo != nil {
+		commits, err := h.repo.Log(h.rel, 0)
+		if err != nil {
+			return "", err
+		}
+		current, _, err := h.read("current")
+		if err != nil {
+			return "", err
+		}
+		for _, c := range commits {
+			content, _, err := h.read(c.Short)
+			if err == nil && content != current {
+				return c.Short, nil
+			}
+		}
+		return "", nil
+	}
+	versions, err := h.local.List(h.rel)
+	if err != nil || len(versions) == 0 {
+		return "", err
+	}
+	return strconv.Itoa(versions[0].Version), nil
+}
+
+// read returns the content of a version ref and a label for it. The ref
+// is a history version number, a git revision in git-backed vaults, or
+// "current" for the note as it is now.
+func (h *noteHistory) read(ref string) (string, string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "current" {
+		content, err := os.ReadFile(filepath.Join(h.rt.VaultRoot, filepath.FromSlash(h.rel)))
+		if os.IsNotExist(err) {
+			return "", h.rel + "@current", nil
+		}
+		return string(content), h.rel + "@current", err
+	}
+	if h.repo != nil {
+		content, err := h.repo.Show(ref, h.rel)
+		return string(content), h.rel + "@" + ref, err
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(ref, "v"))
+	if err != nil || n < 1 {
+		return "", "", errs.New(errs.ExitValidation, "version must be a positive number or current")
+	}
+	content, _, err := h.local.Read(h.rel, n)
+	return string(content), fmt.Sprintf("%s@%d", h.rel, n), err
+}
+
+// restore writes the content of version ref back to the note, keeping the
+// content it replaces in the local history store.
+func (h *noteHistory) restore(ref, content string) error {
+	if h.repo == nil {
+		if n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(ref), "v")); err == nil {
+			_, err = h.local.Restore(h.rel, n)
+			return err
+		}
+	}
+	return h.local.WriteFile(filepath.Join(h.rt.VaultRoot, filepath.FromSlash(h.rel)), []byte(content))
+}
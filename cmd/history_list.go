@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newHistoryListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list <path>",
+		Short: "List prior versions of a note, newest first",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			h, err := openHistory(rt, args[0])
+			if err != nil {
+				return err
+			}
+			if h.repo != nil {
+				commits, err := h.repo.Log(h.rel, 0)
+				if err != nil {
+					return err
+				}
+				if rt.Printer.JSON {
+					return rt.Printer.PrintJSON(map[string]any{"path": h.rel, "source": "git", "commits": commits})
+				}
+				for _, c := range commits {
+					rt.Printer.Println(fmt.Sprintf("%s\t%s\t%s", c.Short, c.Date.Format(time.RFC3339), c.Subject))
+				}
+				return nil
+			}
+			versions, err := h.local.List(h.rel)
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(map[string]any{"path": h.rel, "source": "local", "versions": versions})
+			}
+			for _, v := range versions {
+				rt.Printer.Println(fmt.Sprintf("%d\t%s\t%d bytes", v.Version, v.SavedAt.Format(time.RFC3339), v.Size))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/spf13/cobra"
+)
+
+func newHistoryRestoreCmd() *cobra.Command {
+	var dryRun bool
+	var ifHash string
+
+	cmd := &cobra.Command{
+		Use:   "restore <path> <version>",
+		Short: "Restore a note to a prior version, keeping the current content in history",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			if args[1] == "current" {
+				return errs.New(errs.ExitValidation, "restore needs a prior version")
+			}
+			h, err := openHistory(rt, args[0])
+			if err != nil {
+				return err
+			}
+			if err := verifyHashPrecondition(rt, h.rel, ifHash); err != nil {
+				return err
+			}
+			content, _, err := h.read(args[1])
+			if err != nil {
+				return err
+			}
+			if !dryRun {
+				if err := h.restore(args[1], content); err != nil {
+					return err
+				}
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(map[string]any{"path": h.rel, "version": args[1], "bytes": len(content), "dry_run": dryRun})
+			}
+			prefix := "restored"
+			if dryRun {
+				prefix = "dry-run: would restore"
+			}
+			rt.Printer.Println(fmt.Sprintf("%s %s to version %s", prefix, h.rel, args[1]))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview operation without writing files")
+	cmd.Flags().StringVar(&ifHash, "if-hash", "", "Require current note SHA256 hash before writing")
+	return cmd
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/spf13/cobra"
+)
+
+func newHistoryShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <path> <version>",
+		Short: "Print a prior version of a note",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			if args[1] == "current" {
+				return errs.New(errs.ExitValidation, "use note get for the current content")
+			}
+			h, err := openHistory(rt, args[0])
+			if err != nil {
+				return err
+			}
+			content, _, err := h.read(args[1])
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(map[string]any{"path": h.rel, "version": args[1], "content": content})
+			}
+			rt.Printer.Println(content)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nightisyang/obsidian-cli/internal/history"
+)
+
+func TestHistoryRecordsWritesAndRestores(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "plan.md"), []byte("first draft\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, stderr, err := runCLI(t, "--vault", root, "--mode", "native", "note", "append", "plan.md", "second line"); err != nil {
+		t.Fatalf("append: %v (stderr=%q)", err, stderr)
+	}
+
+	stdout, _, err := runCLI(t, "--vault", root, "--mode", "native", "--json", "history", "list", "plan")
+	if err != nil {
+		t.Fatalf("history list: %v", err)
+	}
+	var listed struct {
+		Path     string            `json:"path"`
+		Versions []history.Version `json:"versions"`
+	}
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &listed); err != nil || listed.Path != "plan.md" || len(listed.Versions) != 1 {
+		t.Fatalf("unexpected history %s (%v)", stdout, err)
+	}
+
+	stdout, _, err = runCLI(t, "--vault", root, "--mode", "native", "diff", "plan.md")
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if !strings.Contains(stdout, "--- plan.md@1") || !strings.Contains(stdout, "+second line") {
+		t.Fatalf("unexpected diff:\n%s", stdout)
+	}
+
+	stdout, _, err = runCLI(t, "--vault", root, "--mode", "native", "history", "show", "plan.md", "1")
+	if err != nil || !strings.Contains(stdout, "first draft") {
+		t.Fatalf("show = %q (%v)", stdout, err)
+	}
+	if _, stderr, err := runCLI(t, "--vault", root, "--mode", "native", "history", "restore", "plan.md", "1"); err != nil {
+		t.Fatalf("restore: %v (stderr=%q)", err, stderr)
+	}
+	if raw, _ := os.ReadFile(filepath.Join(root, "plan.md")); string(raw) != "first draft\n" {
+		t.Fatalf("restored content = %q", raw)
+	}
+	stdout, _, err = runCLI(t, "--vault", root, "--mode", "native", "diff", "plan.md", "--from", "1", "--to", "2")
+	if err != nil || !strings.Contains(stdout, "+second line") {
+		t.Fatalf("diff between versions = %q (%v)", stdout, err)
+	}
+	if _, _, err := runCLI(t, "--vault", root, "history", "show", "plan.md", "9"); err == nil {
+		t.Fatal("expected missing version to fail")
+	}
+
+	// Moving a note keeps its last content in the history of the old path.
+	if _, stderr, err := runCLI(t, "--vault", root, "--mode", "native", "note", "move", "plan.md", "archive/plan.md"); err != nil {
+		t.Fatalf("move: %v (stderr=%q)", err, stderr)
+	}
+	stdout, _, err = runCLI(t, "--vault", root, "--mode", "native", "--json", "history", "list", "plan.md")
+	if err != nil {
+		t.Fatalf("history list after move: %v", err)
+	}
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &listed); err != nil || len(listed.Versions) != 3 {
+		t.Fatalf("unexpected history after move %s (%v)", stdout, err)
+	}
+}
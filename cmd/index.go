@@ -0,0 +1,11 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+func newIndexCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "index", Short: "Persistent vault index operations"}
+	cmd.AddCommand(newIndexRebuildCmd())
+	cmd.AddCommand(newIndexStatusCmd())
+	cmd.AddCommand(newIndexClearCmd())
+	return cmd
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/search"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newIndexClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Delete the persistent vault index",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			s := store.Open(rt.VaultRoot, rt.Config.IndexDir)
+			removed, err := s.Clear()
+			if err != nil {
+				return err
+			}
+			searchIndex := filepath.Join(s.Dir(), search.BM25IndexFile)
+			if err := os.Remove(searchIndex); err == nil {
+				removed = true
+			} else if !os.IsNotExist(err) {
+				return errs.Wrap(errs.ExitGeneric, "failed to clear search index", err)
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(map[string]any{"path": s.Path(), "removed": removed})
+			}
+			if removed {
+				rt.Printer.Println("Cleared index: " + s.Path())
+			} else {
+				rt.Printer.Println("No index at " + s.Path())
+			}
+			return nil
+		},
+	}
+}
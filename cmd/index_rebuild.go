@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newIndexRebuildCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rebuild",
+		Short: "Rebuild the persistent vault index from scratch",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			s := store.Open(rt.VaultRoot, rt.Config.IndexDir)
+			snap, stats, err := s.Rebuild()
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(map[string]any{
+					"path":  s.Path(),
+					"files": len(snap.Files),
+					"stats": stats,
+				})
+			}
+			rt.Printer.Println(fmt.Sprintf("indexed %d files into %s", len(snap.Files), s.Path()))
+			return nil
+		},
+	}
+}
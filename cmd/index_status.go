@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newIndexStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show persistent vault index status",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			status, err := store.Open(rt.VaultRoot, rt.Config.IndexDir).Status()
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(status)
+			}
+			rt.Printer.Printf("path: %s\n", status.Path)
+			rt.Printer.Printf("exists: %t\n", status.Exists)
+			rt.Printer.Printf("files: %d\n", status.Files)
+			if status.RefreshedAt != "" {
+				rt.Printer.Printf("refreshed: %s\n", status.RefreshedAt)
+			}
+			rt.Printer.Println(fmt.Sprintf("pending: %d added, %d updated, %d removed", status.Pending.Added, status.Pending.Updated, status.Pending.Removed))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nightisyang/obsidian-cli/internal/index"
+)
+
+func TestIndexRebuildStatusClear(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.md"), []byte("[[b]] #topic"), 0o644); err != nil {
+		t.Fatalf("write a: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "b.md"), []byte("target"), 0o644); err != nil {
+		t.Fatalf("write b: %v", err)
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "index", "rebuild")
+	if err != nil {
+		t.Fatalf("index rebuild error: %v (stderr=%q)", err, stderr)
+	}
+	var rebuilt struct {
+		Files int `json:"files"`
+	}
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &rebuilt); err != nil || rebuilt.Files != 2 {
+		t.Fatalf("unexpected rebuild payload: %s (%v)", stdout, err)
+	}
+
+	stdout, stderr, err = runCLI(t, "--vault", root, "--json", "index", "status")
+	if err != nil {
+		t.Fatalf("index status error: %v (stderr=%q)", err, stderr)
+	}
+	var status struct {
+		Exists bool `json:"exists"`
+		Stale  bool `json:"stale"`
+		Files  int  `json:"files"`
+	}
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if !status.Exists || status.Stale || status.Files != 2 {
+		t.Fatalf("unexpected index status: %+v", status)
+	}
+
+	stdout, stderr, err = runCLI(t, "--vault", root, "--json", "links", "backlinks", "b.md")
+	if err != nil {
+		t.Fatalf("links backlinks error: %v (stderr=%q)", err, stderr)
+	}
+	var backlinks []index.Backlink
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &backlinks); err != nil || len(backlinks) != 1 {
+		t.Fatalf("unexpected backlinks: %s (%v)", stdout, err)
+	}
+
+	if _, stderr, err := runCLI(t, "--vault", root, "--json", "index", "clear"); err != nil {
+		t.Fatalf("index clear error: %v (stderr=%q)", err, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".obsidian-cli-index", "vault-index.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected index file to be removed, got %v", err)
+	}
+}
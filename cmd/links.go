@@ -1,10 +1,64 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/app"
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/index"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
 
 func newLinksCmd() *cobra.Command {
 	cmd := &cobra.Command{Use: "links", Short: "Link index operations"}
 	cmd.AddCommand(newLinksListCmd())
 	cmd.AddCommand(newLinksBacklinksCmd())
+	cmd.AddCommand(newLinksUnresolvedCmd())
+	cmd.AddCommand(newLinksOrphansCmd())
+	cmd.AddCommand(newLinksDeadEndsCmd())
+	cmd.AddCommand(newLinksAnchorsCmd())
+	cmd.AddCommand(newLinksMentionsCmd())
 	return cmd
 }
+
+type linkPathsReport struct {
+	Count int      `json:"count"`
+	Paths []string `json:"paths"`
+}
+
+// linkReportSnapshot refreshes the index and returns it with a resolver
+// that also knows the vault's attachments.
+func linkReportSnapshot(rt *app.Runtime) (store.Snapshot, *index.Resolver, error) {
+	s := store.Open(rt.VaultRoot, rt.Config.IndexDir)
+	snap, _, err := s.Refresh()
+	if err != nil {
+		return store.Snapshot{}, nil, err
+	}
+	resolver, err := s.Resolver(snap)
+	if err != nil {
+		return store.Snapshot{}, nil, err
+	}
+	return snap, resolver, nil
+}
+
+// printLinkPaths prints an orphan or dead-end report, failing under
+// --strict when it is not empty.
+func printLinkPaths(rt *app.Runtime, paths []string, strict bool, reason, label string) error {
+	if strict && len(paths) > 0 {
+		return errs.NewDetailed(
+			errs.ExitValidation,
+			reason,
+			"Link the listed notes or rerun without --strict.",
+			fmt.Sprintf("%d %s: %s", len(paths), label, strings.Join(paths, ", ")),
+		)
+	}
+	if rt.Printer.JSON {
+		return rt.Printer.PrintJSON(linkPathsReport{Count: len(paths), Paths: paths})
+	}
+	for _, path := range paths {
+		rt.Printer.Println(path)
+	}
+	return nil
+}
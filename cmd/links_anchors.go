@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+type brokenAnchorsReport struct {
+	Count   int                  `json:"count"`
+	Anchors []store.BrokenAnchor `json:"anchors"`
+}
+
+func newLinksAnchorsCmd() *cobra.Command {
+	var prefix string
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "anchors",
+		Short: "List links whose #heading or #^block subpath is broken",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			snap, resolver, err := linkReportSnapshot(rt)
+			if err != nil {
+				return err
+			}
+			anchors := snap.BrokenAnchors(resolver, prefix)
+			if strict && len(anchors) > 0 {
+				first := anchors[0]
+				return errs.NewDetailed(
+					errs.ExitValidation,
+					"broken_anchors_found",
+					"Update the link subpaths to match current headings and block IDs.",
+					fmt.Sprintf("%d broken anchors, first %s:%d %s", len(anchors), first.Source, first.Line, first.Raw),
+				)
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(brokenAnchorsReport{Count: len(anchors), Anchors: anchors})
+			}
+			for _, anchor := range anchors {
+				rt.Printer.Println(fmt.Sprintf("%s:%d\t%s\t%s%s", anchor.Source, anchor.Line, anchor.Raw, anchor.Path, anchor.Subpath))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&prefix, "path", "", "Only report links from notes under this folder")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail when any broken anchor is found")
+	return cmd
+}
@@ -3,17 +3,22 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/nightisyang/obsidian-cli/internal/errs"
 	"github.com/spf13/cobra"
 )
 
 func newLinksBacklinksCmd() *cobra.Command {
 	var rebuild bool
+	var maxChars int
 
 	cmd := &cobra.Command{
 		Use:   "backlinks <path>",
-		Short: "List backlinks for a note",
+		Short: "List backlinks for a note with each linking line",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("max-chars") && maxChars <= 0 {
+				return errs.New(errs.ExitValidation, "--max-chars must be > 0")
+			}
 			rt, err := getRuntime(cmd)
 			if err != nil {
 				return err
@@ -22,15 +27,22 @@ func newLinksBacklinksCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			for i := range backlinks {
+				backlinks[i].Links = applyLinkSnippetMaxChars(backlinks[i].Links, maxChars)
+			}
 			if rt.Printer.JSON {
 				return rt.Printer.PrintJSON(backlinks)
 			}
-			for _, path := range backlinks {
-				rt.Printer.Println(fmt.Sprintf("%s", path))
+			for _, backlink := range backlinks {
+				rt.Printer.Println(backlink.Source)
+				for _, link := range backlink.Links {
+					rt.Printer.Println(fmt.Sprintf("  %d:%d\t%s", link.Line, link.Column, link.Snippet))
+				}
 			}
 			return nil
 		},
 	}
 	cmd.Flags().BoolVar(&rebuild, "index", false, "Force index rebuild")
+	cmd.Flags().IntVar(&maxChars, "max-chars", 0, "Maximum snippet chars per link")
 	return cmd
 }
@@ -0,0 +1,28 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+func newLinksDeadEndsCmd() *cobra.Command {
+	var prefix string
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "deadends",
+		Short: "List notes with no outgoing links to existing files",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			snap, resolver, err := linkReportSnapshot(rt)
+			if err != nil {
+				return err
+			}
+			return printLinkPaths(rt, snap.DeadEnds(resolver, prefix), strict, "dead_end_notes_found", "dead-end notes")
+		},
+	}
+	cmd.Flags().StringVar(&prefix, "path", "", "Only report notes under this folder")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail when any dead-end note is found")
+	return cmd
+}
@@ -2,16 +2,23 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/nightisyang/obsidian-cli/internal/errs"
 	"github.com/spf13/cobra"
 )
 
 func newLinksListCmd() *cobra.Command {
+	var maxChars int
+
 	cmd := &cobra.Command{
 		Use:   "list <path>",
-		Short: "List outgoing wikilinks in a note",
+		Short: "List outgoing wikilinks, markdown links and embeds in a note",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("max-chars") && maxChars <= 0 {
+				return errs.New(errs.ExitValidation, "--max-chars must be > 0")
+			}
 			rt, err := getRuntime(cmd)
 			if err != nil {
 				return err
@@ -20,14 +27,30 @@ func newLinksListCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			links = applyLinkSnippetMaxChars(links, maxChars)
 			if rt.Printer.JSON {
 				return rt.Printer.PrintJSON(links)
 			}
+			warnings := []string{}
 			for _, link := range links {
-				rt.Printer.Println(fmt.Sprintf("%s", link))
+				line := fmt.Sprintf("%d:%d\t%s\t%s%s", link.Line, link.Column, link.Kind, link.Target, link.Subpath())
+				if link.Path != "" {
+					line += "\t" + link.Path
+				}
+				rt.Printer.Println(line)
+				if link.BrokenAnchor {
+					warnings = append(warnings, fmt.Sprintf("broken anchor %s on line %d: %s has no %s", link.Raw, link.Line, link.Path, link.Subpath()))
+				}
+				if len(link.Candidates) > 1 {
+					warnings = append(warnings, fmt.Sprintf("ambiguous link %s on line %d matches %s", link.Raw, link.Line, strings.Join(link.Candidates, ", ")))
+				}
+			}
+			for _, warning := range warnings {
+				rt.Printer.Println("warning: " + warning)
 			}
 			return nil
 		},
 	}
+	cmd.Flags().IntVar(&maxChars, "max-chars", 0, "Maximum snippet chars per link")
 	return cmd
 }
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newLinksMentionsCmd() *cobra.Command {
+	var link bool
+	var dryRun bool
+	var selectors []string
+
+	cmd := &cobra.Command{
+		Use:   "mentions <note>",
+		Short: "List unlinked mentions of a note, optionally converting them to wikilinks",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			target, err := note.Get(rt.VaultRoot, args[0])
+			if err != nil {
+				return err
+			}
+			mentions, err := note.Mentions(rt.VaultRoot, target.Path, note.MentionTerms(target))
+			if err != nil {
+				return errs.Wrap(errs.ExitGeneric, "failed to scan vault for mentions", err)
+			}
+			if len(selectors) > 0 {
+				if mentions, err = selectMentions(mentions, selectors); err != nil {
+					return err
+				}
+			}
+			if !link {
+				if rt.Printer.JSON {
+					return rt.Printer.PrintJSON(mentions)
+				}
+				for _, m := range mentions {
+					rt.Printer.Println(fmt.Sprintf("%s:%d:%d\t%s", m.Path, m.Line, m.Column, m.Snippet))
+				}
+				return nil
+			}
+
+			linkTarget, err := mentionLinkTarget(rt.VaultRoot, rt.Config.IndexDir, target.Path)
+			if err != nil {
+				return err
+			}
+			changed, err := note.LinkMentions(rt.VaultRoot, rt.History, mentions, linkTarget, dryRun)
+			if err != nil {
+				return errs.Wrap(errs.ExitGeneric, "failed to link mentions", err)
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(map[string]any{"target": target.Path, "mentions": mentions, "changed": changed, "dry_run": dryRun})
+			}
+			prefix := "linked"
+			if dryRun {
+				prefix = "dry-run: would link"
+			}
+			rt.Printer.Println(fmt.Sprintf("%s %d mentions of %s in %d notes", prefix, len(mentions), target.Path, len(changed)))
+			for _, rel := range changed {
+				rt.Printer.Println(rel)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&link, "link", false, "Rewrite the mentions into [[wikilinks]]")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview operation without writing files")
+	cmd.Flags().StringSliceVar(&selectors, "select", nil, "Only use mentions matching path, path:line or path:line:column (repeatable)")
+	return cmd
+}
+
+// selectMentions keeps the mentions matched by any path[:line[:column]]
+// selector.
+func selectMentions(mentions []note.Mention, selectors []string) ([]note.Mention, error) {
+	type selector struct {
+		path         string
+		line, column int
+	}
+	parsed := make([]selector, 0, len(selectors))
+	for _, raw := range selectors {
+		parts := strings.Split(strings.TrimSpace(raw), ":")
+		if len(parts) > 3 || parts[0] == "" {
+			return nil, errs.New(errs.ExitValidation, "invalid --select "+raw+", use path, path:line or path:line:column")
+		}
+		sel := selector{path: normalizeGraphPath(parts[0])}
+		for i, dst := range []*int{&sel.line, &sel.column} {
+			if len(parts) <= i+1 {
+				break
+			}
+			n, err := strconv.Atoi(parts[i+1])
+			if err != nil || n <= 0 {
+				return nil, errs.New(errs.ExitValidation, "invalid --select "+raw+", use path, path:line or path:line:column")
+			}
+			*dst = n
+		}
+		parsed = append(parsed, sel)
+	}
+	out := []note.Mention{}
+	for _, m := range mentions {
+		for _, sel := range parsed {
+			if m.Path == sel.path && (sel.line == 0 || m.Line == sel.line) && (sel.column == 0 || m.Column == sel.column) {
+				out = append(out, m)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// mentionLinkTarget returns the shortest link text that resolves to rel.
+func mentionLinkTarget(vaultRoot, indexDir, rel string) (string, error) {
+	snap, _, err := store.Open(vaultRoot, indexDir).Refresh()
+	if err != nil {
+		return "", err
+	}
+	stem := strings.TrimSuffix(rel[strings.LastIndex(rel, "/")+1:], ".md")
+	if snap.Resolver().Resolve("", strings.ToLower(stem)).Path == rel {
+		return stem, nil
+	}
+	return strings.TrimSuffix(rel, ".md"), nil
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+func newLinksOrphansCmd() *cobra.Command {
+	var prefix string
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "orphans",
+		Short: "List notes that no other note or canvas links to",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			snap, resolver, err := linkReportSnapshot(rt)
+			if err != nil {
+				return err
+			}
+			return printLinkPaths(rt, snap.Orphans(resolver, prefix), strict, "orphan_notes_found", "orphan notes")
+		},
+	}
+	cmd.Flags().StringVar(&prefix, "path", "", "Only report notes under this folder")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail when any orphan note is found")
+	return cmd
+}
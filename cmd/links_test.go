@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nightisyang/obsidian-cli/internal/index"
+	"github.com/nightisyang/obsidian-cli/internal/note"
+)
+
+func TestLinksUnderstandMarkdownLinksAndEmbeds(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	files := map[string]string{
+		"a.md":             "Read [the spec](docs/Spec%20One.md#Scope) and ![[b]].",
+		"b.md":             "beta",
+		"docs/Spec One.md": "spec",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(name)), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "links", "list", "a.md")
+	if err != nil {
+		t.Fatalf("links list: %v (stderr=%q)", err, stderr)
+	}
+	var links []note.Link
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &links); err != nil {
+		t.Fatalf("decode links: %v", err)
+	}
+	if len(links) != 2 || links[0].Kind != note.LinkMarkdown || links[0].Target != "docs/Spec One.md" || links[0].Heading != "Scope" || links[1].Kind != note.LinkEmbed {
+		t.Fatalf("unexpected links: %+v", links)
+	}
+
+	for _, target := range []string{"docs/Spec One.md", "b.md"} {
+		stdout, stderr, err = runCLI(t, "--vault", root, "--json", "links", "backlinks", target)
+		if err != nil {
+			t.Fatalf("backlinks %s: %v (stderr=%q)", target, err, stderr)
+		}
+		var backlinks []index.Backlink
+		if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &backlinks); err != nil || len(backlinks) != 1 || backlinks[0].Source != "a.md" {
+			t.Fatalf("expected a.md to backlink %s, got %s (%v)", target, stdout, err)
+		}
+	}
+
+	if _, stderr, err := runCLI(t, "--vault", root, "note", "move", "docs/Spec One.md", "specs/Spec One.md"); err != nil {
+		t.Fatalf("note move: %v (stderr=%q)", err, stderr)
+	}
+	raw, err := os.ReadFile(filepath.Join(root, "a.md"))
+	if err != nil || string(raw) != "Read [the spec](specs/Spec%20One.md#Scope) and ![[b]]." {
+		t.Fatalf("expected markdown link rewrite, got %q (%v)", raw, err)
+	}
+}
+
+func TestLinksListResolvesPathsAndFlagsAmbiguity(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"work/Meeting.md":  "work",
+		"home/Meeting.md":  "home",
+		"projects/Plan.md": "plan",
+		"daily.md":         "[[Meeting]] and [[Plan]]",
+	}
+	for name, content := range files {
+		abs := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(abs, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "links", "list", "daily.md")
+	if err != nil {
+		t.Fatalf("links list: %v (stderr=%q)", err, stderr)
+	}
+	var links []note.Link
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &links); err != nil {
+		t.Fatalf("decode links: %v", err)
+	}
+	if len(links) != 2 || links[0].Path != "" || len(links[0].Candidates) != 2 || links[1].Path != "projects/Plan.md" {
+		t.Fatalf("unexpected links: %+v", links)
+	}
+
+	stdout, _, err = runCLI(t, "--vault", root, "links", "list", "daily.md")
+	if err != nil || !strings.Contains(stdout, "warning: ambiguous link [[Meeting]] on line 1 matches home/Meeting.md, work/Meeting.md") {
+		t.Fatalf("expected ambiguity warning, got %q (%v)", stdout, err)
+	}
+
+	stdout, _, err = runCLI(t, "--vault", root, "--json", "links", "backlinks", "work/Meeting.md")
+	if err != nil {
+		t.Fatalf("backlinks: %v", err)
+	}
+	var backlinks []index.Backlink
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &backlinks); err != nil || len(backlinks) != 0 {
+		t.Fatalf("expected no guessed backlinks, got %s (%v)", stdout, err)
+	}
+}
+
+func TestLinksUnresolvedOrphansAndDeadEnds(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"hub.md":         "[[a]] [[ghost]]",
+		"a.md":           "back to [[hub]]",
+		"docs/lonely.md": "x\n[[ghost|Ghost]]",
+	}
+	for name, content := range files {
+		abs := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(abs, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "links", "unresolved", "--path", "docs")
+	if err != nil {
+		t.Fatalf("links unresolved: %v (stderr=%q)", err, stderr)
+	}
+	var report unresolvedReport
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if report.Count != 1 || report.Targets[0].Target != "ghost" || report.Targets[0].Occurrences[0].Line != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if _, _, err := runCLI(t, "--vault", root, "links", "unresolved", "--strict"); err == nil || !strings.Contains(err.Error(), "2 unresolved links to 1 targets") {
+		t.Fatalf("expected strict failure, got %v", err)
+	}
+
+	stdout, _, err = runCLI(t, "--vault", root, "--json", "links", "orphans")
+	if err != nil {
+		t.Fatalf("links orphans: %v", err)
+	}
+	var orphans linkPathsReport
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &orphans); err != nil || orphans.Count != 1 || orphans.Paths[0] != "docs/lonely.md" {
+		t.Fatalf("unexpected orphans: %s (%v)", stdout, err)
+	}
+
+	stdout, _, err = runCLI(t, "--vault", root, "links", "deadends")
+	if err != nil || strings.TrimSpace(stdout) != "docs/lonely.md" {
+		t.Fatalf("unexpected dead ends: %q (%v)", stdout, err)
+	}
+}
+
+func TestLinksReportBrokenAnchors(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "plan.md"), []byte("# Plan\n## Next Steps\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a.md"), []byte("[[plan#Next Steps]] [[plan#Old Heading]]"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "links", "list", "a.md")
+	if err != nil {
+		t.Fatalf("links list: %v (stderr=%q)", err, stderr)
+	}
+	var links []note.Link
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &links); err != nil {
+		t.Fatalf("decode links: %v", err)
+	}
+	if len(links) != 2 || links[0].BrokenAnchor || !links[1].BrokenAnchor {
+		t.Fatalf("unexpected anchor flags: %+v", links)
+	}
+
+	stdout, _, err = runCLI(t, "--vault", root, "--json", "links", "anchors")
+	if err != nil {
+		t.Fatalf("links anchors: %v", err)
+	}
+	var report brokenAnchorsReport
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &report); err != nil || report.Count != 1 || report.Anchors[0].Raw != "[[plan#Old Heading]]" {
+		t.Fatalf("unexpected report: %s (%v)", stdout, err)
+	}
+	if _, _, err := runCLI(t, "--vault", root, "links", "anchors", "--strict"); err == nil {
+		t.Fatalf("expected --strict to fail on broken anchors")
+	}
+}
+
+func TestLinksMentionsListAndLink(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"projects/Roadmap.md": "---\naliases: [Q3 Plan]\n---\nroadmap body",
+		"a.md":                "Review the roadmap.\nThe Q3 plan slipped.",
+		"b.md":                "Already [[Roadmap]]; roadmap again.",
+	}
+	for name, content := range files {
+		abs := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(abs, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "links", "mentions", "projects/Roadmap.md")
+	if err != nil {
+		t.Fatalf("links mentions: %v (stderr=%q)", err, stderr)
+	}
+	var mentions []note.Mention
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &mentions); err != nil {
+		t.Fatalf("decode mentions: %v", err)
+	}
+	if len(mentions) != 3 || mentions[1].Text != "Q3 plan" || mentions[2].Path != "b.md" || mentions[2].Column != 22 {
+		t.Fatalf("unexpected mentions: %+v", mentions)
+	}
+
+	if _, stderr, err := runCLI(t, "--vault", root, "links", "mentions", "projects/Roadmap.md", "--link", "--dry-run"); err != nil {
+		t.Fatalf("dry run: %v (stderr=%q)", err, stderr)
+	}
+	if raw, _ := os.ReadFile(filepath.Join(root, "a.md")); string(raw) != files["a.md"] {
+		t.Fatalf("dry run wrote a.md: %q", raw)
+	}
+
+	if _, stderr, err := runCLI(t, "--vault", root, "links", "mentions", "projects/Roadmap.md", "--link", "--select", "a.md:2"); err != nil {
+		t.Fatalf("link: %v (stderr=%q)", err, stderr)
+	}
+	raw, _ := os.ReadFile(filepath.Join(root, "a.md"))
+	if string(raw) != "Review the roadmap.\nThe [[Roadmap|Q3 plan]] slipped." {
+		t.Fatalf("unexpected rewrite: %q", raw)
+	}
+	if raw, _ := os.ReadFile(filepath.Join(root, "b.md")); string(raw) != files["b.md"] {
+		t.Fatalf("unselected note changed: %q", raw)
+	}
+}
+
+func TestLinksBacklinksIncludeOccurrencesAndRespectMaxChars(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"plan.md": "# Plan\n## Next\n",
+		"a.md":    "intro\nA long line that mentions the plan before we finally link [[plan#Next|next steps]] and then keeps going on.\n[[plan]]",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "links", "backlinks", "plan.md", "--max-chars", "40")
+	if err != nil {
+		t.Fatalf("backlinks: %v (stderr=%q)", err, stderr)
+	}
+	var backlinks []index.Backlink
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &backlinks); err != nil {
+		t.Fatalf("decode backlinks: %v", err)
+	}
+	if len(backlinks) != 1 || len(backlinks[0].Links) != 2 {
+		t.Fatalf("unexpected backlinks: %+v", backlinks)
+	}
+	first := backlinks[0].Links[0]
+	if first.Line != 2 || first.Column != 59 || first.Text != "next steps" || first.Heading != "Next" || first.Raw != "[[plan#Next|next steps]]" {
+		t.Fatalf("unexpected occurrence: %+v", first)
+	}
+	if !strings.Contains(first.Snippet, first.Raw) || len([]rune(first.Snippet)) > 42 {
+		t.Fatalf("expected windowed snippet around the link, got %q", first.Snippet)
+	}
+
+	stdout, _, err = runCLI(t, "--vault", root, "links", "list", "a.md")
+	if err != nil || !strings.HasPrefix(stdout, "2:59\twikilink\tplan#Next\tplan.md") {
+		t.Fatalf("unexpected links list output: %q (%v)", stdout, err)
+	}
+}
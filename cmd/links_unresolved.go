@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/app"
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+type unresolvedReport struct {
+	Count   int                      `json:"count"`
+	Targets []store.UnresolvedTarget `json:"targets"`
+}
+
+func newLinksUnresolvedCmd() *cobra.Command {
+	var prefix string
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "unresolved",
+		Short: "List links whose target does not exist, grouped by target",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			snap, resolver, err := linkReportSnapshot(rt)
+			if err != nil {
+				return err
+			}
+			return printUnresolved(rt, snap.Unresolved(resolver, prefix), strict, "unresolved_links_found", "Create the missing notes or fix the link targets.")
+		},
+	}
+	cmd.Flags().StringVar(&prefix, "path", "", "Only report links from notes under this folder")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail when any unresolved link is found")
+	return cmd
+}
+
+// printUnresolved prints unresolved targets with their occurrences, failing
+// under --strict when there are any.
+func printUnresolved(rt *app.Runtime, targets []store.UnresolvedTarget, strict bool, reason, hint string) error {
+	report := unresolvedReport{Targets: targets}
+	for _, target := range report.Targets {
+		report.Count += target.Count
+	}
+	if strict && report.Count > 0 {
+		names := make([]string, 0, len(report.Targets))
+		for _, target := range report.Targets {
+			names = append(names, target.Target)
+		}
+		return errs.NewDetailed(
+			errs.ExitValidation,
+			reason,
+			hint,
+			fmt.Sprintf("%d unresolved links to %d targets: %s", report.Count, len(report.Targets), strings.Join(names, ", ")),
+		)
+	}
+	if rt.Printer.JSON {
+		return rt.Printer.PrintJSON(report)
+	}
+	for _, target := range report.Targets {
+		rt.Printer.Println(fmt.Sprintf("%s\t%d", target.Target, target.Count))
+		for _, occ := range target.Occurrences {
+			location := occ.Source
+			if occ.Line > 0 {
+				location = fmt.Sprintf("%s:%d", occ.Source, occ.Line)
+			}
+			rt.Printer.Println(fmt.Sprintf("  %s\t%s", location, occ.Raw))
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/nightisyang/obsidian-cli/internal/frontmatter"
 	"github.com/nightisyang/obsidian-cli/internal/note"
@@ -48,3 +49,25 @@ func applySearchSnippetMaxChars(results []search.SearchResult, max int) []search
 	}
 	return out
 }
+
+// applyLinkSnippetMaxChars narrows link snippets to max chars, keeping the
+// link itself in view.
+func applyLinkSnippetMaxChars(links []note.Link, max int) []note.Link {
+	if max <= 0 {
+		return links
+	}
+	out := make([]note.Link, len(links))
+	copy(out, links)
+	for i := range out {
+		start := strings.Index(out[i].Snippet, out[i].Raw)
+		if start < 0 {
+			start = 0
+		}
+		end := start + len(out[i].Raw)
+		if end > len(out[i].Snippet) {
+			end = start
+		}
+		out[i].Snippet = note.WindowSnippet(out[i].Snippet, start, end, max)
+	}
+	return out
+}
@@ -12,6 +12,9 @@ func newNoteCmd() *cobra.Command {
 	cmd.AddCommand(newNoteAppendCmd())
 	cmd.AddCommand(newNotePrependCmd())
 	cmd.AddCommand(newNoteMoveCmd())
+	cmd.AddCommand(newNoteHeadingCmd())
+	cmd.AddCommand(newNoteSplitCmd())
+	cmd.AddCommand(newNoteMergeCmd())
 	cmd.AddCommand(newNoteDailyCmd())
 	return cmd
 }
@@ -1,6 +1,12 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"os"
+
+	"github.com/nightisyang/obsidian-cli/internal/app"
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/spf13/cobra"
+)
 
 func newNoteDeleteCmd() *cobra.Command {
 	var force bool
@@ -34,11 +40,24 @@ func newNoteDeleteCmd() *cobra.Command {
 				rt.Printer.Println("dry-run: would delete " + n.Path)
 				return nil
 			}
-			if err := rt.Backend.DeleteNote(rt.Context, args[0]); err != nil {
+			// The Local REST API deletes permanently, so a vault on this
+			// machine is deleted through the trash setting in every mode.
+			local := vaultIsLocal(rt)
+			if local {
+				err = note.Delete(rt.VaultRoot, rt.History, args[0])
+			} else {
+				err = rt.Backend.DeleteNote(rt.Context, args[0])
+			}
+			if err != nil {
 				return err
 			}
+			trashed := local && note.TrashOption(rt.VaultRoot) != note.TrashNone
 			if rt.Printer.JSON {
-				return rt.Printer.PrintJSON(map[string]any{"deleted": args[0]})
+				return rt.Printer.PrintJSON(map[string]any{"deleted": args[0], "trashed": trashed})
+			}
+			if trashed {
+				rt.Printer.Println("deleted: " + args[0] + " (moved to " + note.TrashDir + ", see trash list)")
+				return nil
 			}
 			rt.Printer.Println("deleted: " + args[0])
 			return nil
@@ -49,3 +68,10 @@ func newNoteDeleteCmd() *cobra.Command {
 	cmd.Flags().StringVar(&ifHash, "if-hash", "", "Require current note SHA256 hash before writing")
 	return cmd
 }
+
+// vaultIsLocal reports whether the vault root is a folder on this machine,
+// as opposed to a vault only reachable through the Local REST API.
+func vaultIsLocal(rt *app.Runtime) bool {
+	info, err := os.Stat(rt.VaultRoot)
+	return err == nil && info.IsDir()
+}
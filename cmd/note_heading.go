@@ -0,0 +1,9 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+func newNoteHeadingCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "heading", Short: "Heading operations"}
+	cmd.AddCommand(newNoteHeadingRenameCmd())
+	return cmd
+}
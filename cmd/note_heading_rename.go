@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newNoteHeadingRenameCmd() *cobra.Command {
+	var dryRun bool
+	var ifHash string
+
+	cmd := &cobra.Command{
+		Use:   "rename <path> <old> <new>",
+		Short: "Rename a heading and rewrite the links that target it",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			if err := verifyHashPrecondition(rt, args[0], ifHash); err != nil {
+				return err
+			}
+			target, err := note.Get(rt.VaultRoot, args[0])
+			if err != nil {
+				return err
+			}
+			s := store.Open(rt.VaultRoot, rt.Config.IndexDir)
+			snap, _, err := s.Refresh()
+			if err != nil {
+				return err
+			}
+			resolver, err := s.Resolver(snap)
+			if err != nil {
+				return err
+			}
+			res, err := note.RenameHeading(rt.VaultRoot, rt.History, target.Path, args[1], args[2], func(sourceRel string, link note.Link) bool {
+				return resolver.Resolve(sourceRel, link.Key(sourceRel)).Path == target.Path
+			}, dryRun)
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(res)
+			}
+			prefix := "renamed"
+			if dryRun {
+				prefix = "dry-run: would rename"
+			}
+			rt.Printer.Println(fmt.Sprintf("%s %q to %q in %s (%d notes)", prefix, res.Old, res.New, res.Path, len(res.Changed)))
+			for _, rel := range res.Changed {
+				rt.Printer.Println(rel)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview operation without writing files")
+	cmd.Flags().StringVar(&ifHash, "if-hash", "", "Require note SHA256 hash before writing")
+	return cmd
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nightisyang/obsidian-cli/internal/note"
+)
+
+func TestNoteHeadingRenameRewritesAnchors(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"projects/Plan.md": "---\naliases: [Q3 Plan]\n---\n# Plan\n\n## Old Goals\nship it\n\nSee [[#Old Goals]].\n",
+		"daily.md":         "[[Plan#Old Goals]] [[Q3 Plan#Old Goals|goals]] [g](projects/Plan.md#Old%20Goals)\n",
+		"other.md":         "[[Missing#Old Goals]]\n",
+	}
+	for name, content := range files {
+		abs := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(abs, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "note", "heading", "rename", "projects/Plan.md", "Old Goals", "Goals", "--dry-run")
+	if err != nil {
+		t.Fatalf("dry run: %v (stderr=%q)", err, stderr)
+	}
+	var res note.HeadingRename
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &res); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(res.Changed) != 2 || res.Changed[0] != "daily.md" || res.Changed[1] != "projects/Plan.md" || !res.DryRun {
+		t.Fatalf("unexpected dry run result %+v", res)
+	}
+	if raw, _ := os.ReadFile(filepath.Join(root, "daily.md")); string(raw) != files["daily.md"] {
+		t.Fatalf("dry run wrote daily.md: %s", raw)
+	}
+
+	if _, stderr, err := runCLI(t, "--vault", root, "note", "heading", "rename", "projects/Plan.md", "Old Goals", "Goals"); err != nil {
+		t.Fatalf("rename: %v (stderr=%q)", err, stderr)
+	}
+	want := map[string]string{
+		"projects/Plan.md": "---\naliases: [Q3 Plan]\n---\n# Plan\n\n## Goals\nship it\n\nSee [[#Goals]].\n",
+		"daily.md":         "[[Plan#Goals]] [[Q3 Plan#Goals|goals]] [g](projects/Plan.md#Goals)\n",
+		"other.md":         files["other.md"],
+	}
+	for name, content := range want {
+		raw, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil || string(raw) != content {
+			t.Fatalf("%s = %q (%v), want %q", name, raw, err, content)
+		}
+	}
+}
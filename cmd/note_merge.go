@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/spf13/cobra"
+)
+
+func newNoteMergeCmd() *cobra.Command {
+	var opts note.MergeOptions
+	var ifHash string
+	var dstIfHash string
+
+	cmd := &cobra.Command{
+		Use:   "merge <src> <dst>",
+		Short: "Merge a note into another and redirect its links",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			if err := verifyHashPrecondition(rt, args[0], ifHash); err != nil {
+				return err
+			}
+			if err := verifyHashPrecondition(rt, args[1], dstIfHash); err != nil {
+				return err
+			}
+			opts.Guard = func(n note.Note) error {
+				return enforceNoteSizeGuard(rt, n)
+			}
+			_, resolver, err := linkReportSnapshot(rt)
+			if err != nil {
+				return err
+			}
+			res, err := note.Merge(rt.VaultRoot, rt.History, args[0], args[1], func(sourceRel string, link note.Link) string {
+				return resolver.Resolve(sourceRel, link.Key(sourceRel)).Path
+			}, opts)
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(res)
+			}
+			prefix := "merged"
+			if opts.DryRun {
+				prefix = "dry-run: would merge"
+			}
+			rt.Printer.Println(fmt.Sprintf("%s %s into %s (%d notes relinked)", prefix, res.Source, res.Destination, len(res.Rewritten)))
+			for _, c := range res.Conflicts {
+				rt.Printer.Println(fmt.Sprintf("conflict: %s kept %s value (src=%v dst=%v)", c.Key, c.Kept, c.Source, c.Destination))
+			}
+			for _, rel := range res.Rewritten {
+				rt.Printer.Println("rewrite: " + rel)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.Heading, "heading", "", "Wrap the source content in a level 2 heading with this text")
+	cmd.Flags().StringVar(&opts.OnConflict, "on-conflict", "dst", "Scalar frontmatter conflicts: dst, src or error")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Preview operation without writing files")
+	cmd.Flags().StringVar(&ifHash, "if-hash", "", "Require source note SHA256 hash before writing")
+	cmd.Flags().StringVar(&dstIfHash, "dst-if-hash", "", "Require destination note SHA256 hash before writing")
+	return cmd
+}
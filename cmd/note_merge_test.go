@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nightisyang/obsidian-cli/internal/note"
+)
+
+func TestNoteMergeCombinesNotesAndRedirectsLinks(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"Project X.md":       "---\ntags: [alpha]\nstatus: draft\nowner: sam\n---\nOld notes, see [[Home]].\n",
+		"notes/project-x.md": "---\ntags: [beta, alpha]\nstatus: active\n---\n# Project X\n\nCurrent notes.\n",
+		"Home.md":            "[[Project X]] and [[Project X#Old|old]] and [x](Project%20X.md)\n",
+	}
+	for name, content := range files {
+		abs := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(abs, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	if _, _, err := runCLI(t, "--vault", root, "note", "merge", "Project X.md", "notes/project-x.md", "--on-conflict", "error"); err == nil || !strings.Contains(err.Error(), "status") {
+		t.Fatalf("expected status conflict, got %v", err)
+	}
+	if _, _, err := runCLI(t, "--vault", root, "note", "merge", "Project X.md", "notes/project-x.md", "--dst-if-hash", "deadbeef"); err == nil || !strings.Contains(err.Error(), "hash precondition failed") {
+		t.Fatalf("expected destination hash precondition failure, got %v", err)
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "note", "merge", "Project X.md", "notes/project-x.md", "--heading", "Archive", "--dry-run")
+	if err != nil {
+		t.Fatalf("dry run: %v (stderr=%q)", err, stderr)
+	}
+	var res note.MergeResult
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &res); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(res.Conflicts) != 1 || res.Conflicts[0].Key != "status" || res.Conflicts[0].Kept != "dst" || strings.Join(res.Rewritten, ",") != "Home.md" {
+		t.Fatalf("unexpected dry run %+v", res)
+	}
+	if _, err := os.Stat(filepath.Join(root, "Project X.md")); err != nil {
+		t.Fatalf("dry run removed the source: %v", err)
+	}
+
+	if _, _, err := runCLI(t, "--vault", root, "--note-size-max-bytes", "64", "note", "merge", "Project X.md", "notes/project-x.md"); err == nil || !strings.Contains(err.Error(), "exceeding 64 bytes") {
+		t.Fatalf("expected size limit failure, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "Project X.md")); err != nil {
+		t.Fatalf("size limit failure removed the source: %v", err)
+	}
+
+	if _, stderr, err := runCLI(t, "--vault", root, "note", "merge", "Project X.md", "notes/project-x.md", "--heading", "Archive"); err != nil {
+		t.Fatalf("merge: %v (stderr=%q)", err, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(root, "Project X.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected source to be deleted, got %v", err)
+	}
+	merged, err := note.Get(root, "notes/project-x.md")
+	if err != nil {
+		t.Fatalf("read merged: %v", err)
+	}
+	if want := "# Project X\n\nCurrent notes.\n\n## Archive\n\nOld notes, see [[Home]].\n"; merged.Body != want {
+		t.Fatalf("merged body = %q, want %q", merged.Body, want)
+	}
+	if strings.Join(merged.Frontmatter.Tags, ",") != "beta,alpha" || merged.Frontmatter.Status != "active" || merged.Frontmatter.Extra["owner"] != "sam" {
+		t.Fatalf("unexpected merged frontmatter %+v", merged.Frontmatter)
+	}
+	raw, err := os.ReadFile(filepath.Join(root, "Home.md"))
+	if want := "[[notes/project-x]] and [[notes/project-x#Old|old]] and [x](notes/project-x.md)\n"; err != nil || string(raw) != want {
+		t.Fatalf("Home.md = %q (%v), want %q", raw, err, want)
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/spf13/cobra"
+)
+
+func newNoteSplitCmd() *cobra.Command {
+	var opts note.SplitOptions
+	var ifHash string
+
+	cmd := &cobra.Command{
+		Use:   "split <path>",
+		Short: "Move heading sections into new linked notes",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			if err := verifyHashPrecondition(rt, args[0], ifHash); err != nil {
+				return err
+			}
+			_, resolver, err := linkReportSnapshot(rt)
+			if err != nil {
+				return err
+			}
+			res, err := note.Split(rt.VaultRoot, rt.History, args[0], func(sourceRel string, link note.Link) string {
+				return resolver.Resolve(sourceRel, link.Key(sourceRel)).Path
+			}, opts)
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(res)
+			}
+			prefix := "split"
+			if opts.DryRun {
+				prefix = "dry-run: would split"
+			}
+			rt.Printer.Println(fmt.Sprintf("%s %s into %d notes (%d bytes left)", prefix, res.Path, len(res.Parts), res.Bytes))
+			for _, part := range res.Parts {
+				rt.Printer.Println(fmt.Sprintf("%s\t%s\t%d", part.Path, part.Heading, part.Bytes))
+			}
+			for _, rel := range res.Rewritten {
+				rt.Printer.Println("rewrite: " + rel)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.Heading, "heading", "", "Split out the section under this heading")
+	cmd.Flags().IntVar(&opts.Level, "level", 0, "Split out every section at this heading level")
+	cmd.Flags().BoolVar(&opts.Embed, "embed", false, "Replace each section with an embed instead of a link")
+	cmd.Flags().StringSliceVar(&opts.Inherit, "inherit", nil, "Frontmatter keys copied to the new notes, or all (repeatable)")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Preview operation without writing files")
+	cmd.Flags().StringVar(&ifHash, "if-hash", "", "Require note SHA256 hash before writing")
+	return cmd
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nightisyang/obsidian-cli/internal/note"
+)
+
+func TestNoteSplitMovesSectionsIntoLinkedNotes(t *testing.T) {
+	root := t.TempDir()
+	original := "---\ntags: [project]\ntopic: planning\n---\n# Plan\n\nIntro.\n\n## Goals\nShip it.\n\n### Detail\nMore.\n\n## Empty\n\n## Risks\nDelays.\n"
+	if err := os.WriteFile(filepath.Join(root, "plan.md"), []byte(original), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "plan-part-2.md"), []byte("taken\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "note", "split", "plan.md", "--level", "2", "--dry-run")
+	if err != nil {
+		t.Fatalf("dry run: %v (stderr=%q)", err, stderr)
+	}
+	var res note.SplitResult
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &res); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(res.Parts) != 2 || res.Parts[0].Path != "plan-part-3.md" || res.Parts[0].Heading != "Goals" || res.Parts[1].Path != "plan-part-4.md" {
+		t.Fatalf("unexpected dry run %+v", res)
+	}
+	if raw, _ := os.ReadFile(filepath.Join(root, "plan.md")); string(raw) != original {
+		t.Fatalf("dry run rewrote plan.md: %s", raw)
+	}
+
+	if _, stderr, err := runCLI(t, "--vault", root, "note", "split", "plan.md", "--level", "2", "--embed", "--inherit", "tags"); err != nil {
+		t.Fatalf("split: %v (stderr=%q)", err, stderr)
+	}
+	parent, err := note.Get(root, "plan.md")
+	if err != nil {
+		t.Fatalf("read parent: %v", err)
+	}
+	if want := "# Plan\n\nIntro.\n\n## Goals\n![[plan-part-3]]\n\n## Empty\n\n## Risks\n![[plan-part-4]]\n"; parent.Body != want {
+		t.Fatalf("parent body = %q, want %q", parent.Body, want)
+	}
+	child, err := note.Get(root, "plan-part-3.md")
+	if err != nil {
+		t.Fatalf("read child: %v", err)
+	}
+	if want := "Ship it.\n\n### Detail\nMore.\n\nContext from [[plan#Goals]]\n"; child.Body != want {
+		t.Fatalf("child body = %q, want %q", child.Body, want)
+	}
+	if strings.Join(child.Frontmatter.Tags, ",") != "project" || child.Frontmatter.Topic != "" {
+		t.Fatalf("unexpected inherited frontmatter %+v", child.Frontmatter)
+	}
+
+	if _, _, err := runCLI(t, "--vault", root, "note", "split", "plan.md", "--heading", "Empty"); err == nil {
+		t.Fatal("expected empty section to be refused")
+	}
+	if _, _, err := runCLI(t, "--vault", root, "note", "split", "plan.md"); err == nil || !strings.Contains(err.Error(), "exactly one of --heading or --level") {
+		t.Fatalf("expected selector validation error, got %v", err)
+	}
+}
+
+func TestNoteSplitSkipsFencesAndRedirectsLinks(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"plan.md":  "# Plan\n\nSee [[#Two]] and [[#Detail]].\n\n## One\n```sh\n## not a heading\n```\nText [[#Two]] ^step1\n\n### Detail\nMore.\n\n## Two\nBack to [[#One]] and [[#Plan]].\n",
+		"other.md": "[[plan#One]] [[plan#^step1|block]] [[plan#Two]] ![[plan#One#Detail]]\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "note", "split", "plan.md", "--heading", "One")
+	if err != nil {
+		t.Fatalf("split: %v (stderr=%q)", err, stderr)
+	}
+	var res note.SplitResult
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &res); err != nil || len(res.Rewritten) != 1 || res.Rewritten[0] != "other.md" {
+		t.Fatalf("unexpected result %s (%v)", stdout, err)
+	}
+	parent, err := note.Get(root, "plan.md")
+	if err != nil {
+		t.Fatalf("read parent: %v", err)
+	}
+	if want := "# Plan\n\nSee [[#Two]] and [[plan-part-2#Detail]].\n\n## One\nContinued in [[plan-part-2]]\n\n## Two\nBack to [[plan-part-2]] and [[#Plan]].\n"; parent.Body != want {
+		t.Fatalf("parent body = %q, want %q", parent.Body, want)
+	}
+	child, err := note.Get(root, "plan-part-2.md")
+	if err != nil {
+		t.Fatalf("read child: %v", err)
+	}
+	if want := "```sh\n## not a heading\n```\nText [[plan#Two]] ^step1\n\n### Detail\nMore.\n\nContext from [[plan#One]]\n"; child.Body != want {
+		t.Fatalf("child body = %q, want %q", child.Body, want)
+	}
+	raw, err := os.ReadFile(filepath.Join(root, "other.md"))
+	if want := "[[plan-part-2]] [[plan-part-2#^step1|block]] [[plan#Two]] ![[plan-part-2#Detail]]\n"; err != nil || string(raw) != want {
+		t.Fatalf("other.md = %q (%v), want %q", raw, err, want)
+	}
+}
@@ -6,9 +6,15 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/nightisyang/obsidian-cli/internal/app"
 	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/history"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/nightisyang/obsidian-cli/internal/vault"
 	"github.com/spf13/cobra"
 )
 
@@ -30,6 +36,9 @@ type opsResult struct {
 	Error    string   `json:"error,omitempty"`
 	Stdout   any      `json:"stdout,omitempty"`
 	Stderr   string   `json:"stderr,omitempty"`
+
+	// touched lists the vault paths the operation reported changing.
+	touched []string
 }
 
 func newOpsCmd() *cobra.Command {
@@ -44,11 +53,12 @@ func newOpsCmd() *cobra.Command {
 func newOpsApplyCmd() *cobra.Command {
 	var continueOnError bool
 	var dryRun bool
+	var atomic bool
 
 	cmd := &cobra.Command{
 		Use:   "apply <spec.json>",
 		Short: "Apply a batch of CLI operations from JSON",
-		Long:  "JSON format:\n{\n  \"ops\": [\n    {\"id\": \"1\", \"args\": [\"note\", \"append\", \"daily.md\", \"- [ ] follow up\"], \"expect\": {\"ok\": \"true\", \"data.path\": \"daily.md\"}}\n  ]\n}\n\nOperations run in order. On failure, succeeded and failed operations are reported; no automatic rollback is performed.",
+		Long:  "JSON format:\n{\n  \"ops\": [\n    {\"id\": \"1\", \"args\": [\"note\", \"append\", \"daily.md\", \"- [ ] follow up\"], \"expect\": {\"ok\": \"true\", \"data.path\": \"daily.md\"}}\n  ]\n}\n\nOperations run in order. On failure, succeeded and failed operations are reported; no automatic rollback is performed unless --atomic is set.\n\nWith --atomic each file is copied before an operation first changes it. If an operation fails, or a file changes outside the batch while it runs, every file the batch touched (link rewrites included) is restored and the rollback is reported. In a git-backed vault the batch becomes a single commit.",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			rt, err := getRuntime(cmd)
@@ -63,6 +73,9 @@ func newOpsApplyCmd() *cobra.Command {
 			if len(spec.Ops) == 0 {
 				return errs.New(errs.ExitValidation, "ops list is empty")
 			}
+			if atomic && continueOnError {
+				return errs.New(errs.ExitValidation, "--atomic cannot be combined with --continue-on-error")
+			}
 
 			if dryRun {
 				if rt.Printer.JSON {
@@ -75,10 +88,13 @@ func newOpsApplyCmd() *cobra.Command {
 				return nil
 			}
 
+			if atomic {
+				return applyOpsAtomic(rt, spec, args)
+			}
 			results := []opsResult{}
 			failed := false
 			for _, op := range spec.Ops {
-				result := executeOpsItem(rt, op)
+				result := executeOpsItem(rt, op, nil)
 				results = append(results, result)
 				if !result.OK {
 					failed = true
@@ -88,48 +104,196 @@ func newOpsApplyCmd() *cobra.Command {
 				}
 			}
 
-			succeededOps := make([]opsResult, 0, len(results))
-			failedOps := make([]opsResult, 0)
-			for _, result := range results {
-				if result.OK {
-					succeededOps = append(succeededOps, result)
-				} else {
-					failedOps = append(failedOps, result)
-				}
-			}
-
-			if rt.Printer.JSON {
-				return rt.Printer.PrintJSON(map[string]any{
-					"results":       results,
-					"failed":        failed,
-					"succeeded_ops": succeededOps,
-					"failed_ops":    failedOps,
-				})
-			}
-			if len(succeededOps) > 0 {
-				rt.Printer.Println("succeeded operations:")
-				for _, result := range succeededOps {
-					rt.Printer.Println(fmt.Sprintf("- %v", result.Args))
-				}
-			}
-			if len(failedOps) > 0 {
-				rt.Printer.Println("failed operations:")
-				for _, result := range failedOps {
-					rt.Printer.Println(fmt.Sprintf("- %v (%s)", result.Args, result.Error))
-				}
-			}
-			if failed {
-				return errs.New(errs.ExitGeneric, "batch apply failed")
-			}
-			return nil
+			return commitOpsBatch(rt, args, printOpsResults(rt, results, failed, nil))
 		},
 	}
 
 	cmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "Continue applying operations after a failure")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview operation list without executing")
+	cmd.Flags().BoolVar(&atomic, "atomic", false, "Restore every touched file if any operation fails")
 	return cmd
 }
 
+// opsRollback reports an atomic batch that was rolled back. External lists
+// files changed outside the batch while it ran; those are left as found,
+// as are touched files changed again after the batch wrote them.
+type opsRollback struct {
+	Reason   string   `json:"reason"`
+	External []string `json:"external_changes"`
+	vault.RollbackReport
+}
+
+// applyOpsAtomic runs the batch against a checkpoint. Operations save each
+// path to the checkpoint before first changing it, and after each one the
+// vault is compared with the state the previous one left: a change to any
+// path the operation did not report touching, or to a file the batch wrote
+// earlier whose content hash no longer matches, was made by someone else
+// and aborts the batch. A rolled back batch exits non-zero even in JSON mode.
+func applyOpsAtomic(rt *app.Runtime, spec opsSpec, args []string) error {
+	checkpoint, err := vault.NewCheckpoint(rt.VaultRoot, checkpointExcludes(rt)...)
+	if err != nil {
+		return errs.Wrap(errs.ExitGeneric, "failed to snapshot vault", err)
+	}
+	defer checkpoint.Close()
+
+	env := []string{vault.CheckpointEnv + "=" + checkpoint.Dir}
+	last := checkpoint.Files
+	results := []opsResult{}
+	var rollback *opsRollback
+	for _, op := range spec.Ops {
+		result := executeOpsItem(rt, op, env)
+		results = append(results, result)
+		if err := checkpoint.Expect(result.touched); err != nil {
+			return err
+		}
+		now, err := checkpoint.Scan()
+		if err != nil {
+			return err
+		}
+		// Stat changes catch edits anywhere in the vault; hashes catch edits
+		// to files the batch already wrote that keep size and mtime.
+		external := outsidePaths(vault.Changed(last, now), result.touched)
+		rewritten, err := checkpoint.Unexpected(result.touched)
+		if err != nil {
+			return err
+		}
+		if external = mergeSorted(external, rewritten); len(external) > 0 {
+			rollback = &opsRollback{Reason: "concurrent_modification", External: external}
+			break
+		}
+		last = now
+		if !result.OK {
+			rollback = &opsRollback{Reason: "operation_failed", External: []string{}}
+			break
+		}
+	}
+	if rollback == nil {
+		return commitOpsBatch(rt, args, printOpsResults(rt, results, false, nil))
+	}
+	report, err := checkpoint.Restore()
+	rollback.RollbackReport = report
+	if err != nil {
+		return errs.Wrap(errs.ExitGeneric, "batch failed and rollback did not complete", err)
+	}
+	return printOpsResults(rt, results, true, rollback)
+}
+
+// outsidePaths returns the changed paths that are neither one of touched
+// nor inside a touched folder.
+func outsidePaths(changed, touched []string) []string {
+	out := []string{}
+	for _, rel := range changed {
+		inside := false
+		for _, t := range touched {
+			if rel == t || strings.HasPrefix(rel, t+"/") {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			out = append(out, rel)
+		}
+	}
+	return out
+}
+
+// commitOpsBatch commits every path the batch's operations changed as one
+// commit once the results are printed. A commit failure is reported only
+// when printing succeeded.
+func commitOpsBatch(rt *app.Runtime, args []string, printErr error) error {
+	if err := commitTouched(rt, commitMessage("ops apply", args)); err != nil && printErr == nil {
+		return err
+	}
+	return printErr
+}
+
+// mergeSorted returns the distinct paths of a and b in order.
+func mergeSorted(a, b []string) []string {
+	seen := map[string]struct{}{}
+	out := []string{}
+	for _, rel := range append(append([]string{}, a...), b...) {
+		if _, ok := seen[rel]; !ok {
+			seen[rel] = struct{}{}
+			out = append(out, rel)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// checkpointExcludes leaves the index, git data and Obsidian's constantly
+// rewritten workspace layout out of atomic batches.
+func checkpointExcludes(rt *app.Runtime) []string {
+	out := []string{".git", ".obsidian/workspace.json", ".obsidian/workspace-mobile.json"}
+	indexDir := store.Open(rt.VaultRoot, rt.Config.IndexDir).Dir()
+	if rel, err := filepath.Rel(rt.VaultRoot, indexDir); err == nil && !strings.HasPrefix(rel, "..") {
+		out = append(out, filepath.ToSlash(rel))
+	}
+	return out
+}
+
+func printOpsResults(rt *app.Runtime, results []opsResult, failed bool, rollback *opsRollback) error {
+	succeededOps := make([]opsResult, 0, len(results))
+	failedOps := make([]opsResult, 0)
+	for _, result := range results {
+		if result.OK {
+			succeededOps = append(succeededOps, result)
+		} else {
+			failedOps = append(failedOps, result)
+		}
+	}
+
+	if rt.Printer.JSON {
+		payload := map[string]any{
+			"results":       results,
+			"failed":        failed,
+			"succeeded_ops": succeededOps,
+			"failed_ops":    failedOps,
+		}
+		if rollback != nil {
+			payload["rolled_back"] = true
+			payload["rollback"] = rollback
+		}
+		if err := rt.Printer.PrintJSON(payload); err != nil {
+			return err
+		}
+	} else {
+		if len(succeededOps) > 0 {
+			rt.Printer.Println("succeeded operations:")
+			for _, result := range succeededOps {
+				rt.Printer.Println(fmt.Sprintf("- %v", result.Args))
+			}
+		}
+		if len(failedOps) > 0 {
+			rt.Printer.Println("failed operations:")
+			for _, result := range failedOps {
+				rt.Printer.Println(fmt.Sprintf("- %v (%s)", result.Args, result.Error))
+			}
+		}
+		if rollback != nil {
+			rt.Printer.Println(fmt.Sprintf("rolled back: %d restored, %d removed", len(rollback.Restored), len(rollback.Removed)))
+			for _, rel := range rollback.External {
+				rt.Printer.Println("changed outside the batch: " + rel)
+			}
+		}
+	}
+	if rollback != nil && rollback.Reason == "concurrent_modification" {
+		return errs.NewDetailed(
+			errs.ExitGeneric,
+			"concurrent_modification",
+			"Rerun the batch once nothing else is editing the vault.",
+			"batch rolled back: files changed outside the batch while it ran",
+		)
+	}
+	if rollback != nil {
+		return errs.New(errs.ExitGeneric, "batch apply failed and was rolled back")
+	}
+	if failed && !rt.Printer.JSON {
+		return errs.New(errs.ExitGeneric, "batch apply failed")
+	}
+	return nil
+}
+
 func readOpsSpec(path string) (opsSpec, error) {
 	payload, err := os.ReadFile(path)
 	if err != nil {
@@ -147,26 +311,46 @@ func readOpsSpec(path string) (opsSpec, error) {
 	return spec, nil
 }
 
-func executeOpsItem(rt *app.Runtime, op opsItem) opsResult {
+// executeOpsItem runs op as a child process with env added to its
+// environment. The child records the paths it changes in a journal file,
+// which are added to rt.History so the batch commits them once.
+func executeOpsItem(rt *app.Runtime, op opsItem, env []string) (result opsResult) {
 	baseArgs := []string{"--vault", rt.VaultRoot, "--json"}
 	if rt.ConfigPath != "" {
 		baseArgs = append(baseArgs, "--config", rt.ConfigPath)
 	}
-	if rt.EffectiveMode != "" {
-		baseArgs = append(baseArgs, "--mode", rt.EffectiveMode)
+	if rt.RequestedMode != "" {
+		baseArgs = append(baseArgs, "--mode", rt.RequestedMode)
 	}
 	baseArgs = append(baseArgs, op.Args...)
 
+	result = opsResult{
+		ID:   op.ID,
+		Args: append([]string(nil), op.Args...),
+	}
+	journal, err := os.CreateTemp("", "obsidian-cli-ops-*.paths")
+	if err != nil {
+		result.ExitCode = errs.ExitGeneric
+		result.Error = err.Error()
+		return result
+	}
+	journal.Close()
+	defer os.Remove(journal.Name())
+	defer func() {
+		touched, _ := history.ReadJournal(journal.Name())
+		for _, rel := range touched {
+			result.touched = append(result.touched, rel)
+			_ = rt.History.Touch(filepath.Join(rt.VaultRoot, filepath.FromSlash(rel)))
+		}
+	}()
+
 	cmd := exec.Command(os.Args[0], baseArgs...)
+	cmd.Env = append(append(os.Environ(), history.JournalEnv+"="+journal.Name()), env...)
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	result := opsResult{
-		ID:   op.ID,
-		Args: append([]string(nil), op.Args...),
-	}
 	if err := cmd.Run(); err != nil {
 		exitCode := errs.ExitGeneric
 		if ee, ok := err.(*exec.ExitError); ok {
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// opsExecEnv makes the test binary act as the CLI, so ops apply can run
+// its operations as child processes.
+const opsExecEnv = "OBSIDIAN_CLI_TEST_EXEC"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(opsExecEnv) == "1" {
+		os.Exit(Execute())
+	}
+	os.Exit(m.Run())
+}
+
+func TestOpsApplyAtomicRollsBackLinkRewrites(t *testing.T) {
+	t.Setenv(opsExecEnv, "1")
+	root := t.TempDir()
+	files := map[string]string{
+		"plan.md":  "the plan\n",
+		"index.md": "see [[plan]]\n",
+	}
+	for rel, content := range files {
+		if err := os.WriteFile(filepath.Join(root, rel), []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	spec := filepath.Join(t.TempDir(), "ops.json")
+	payload := `{"ops": [
+		{"id": "move", "args": ["note", "move", "plan.md", "archive/plan.md"]},
+		{"id": "missing", "args": ["note", "get", "missing.md"]}
+	]}`
+	if err := os.WriteFile(spec, []byte(payload), 0o644); err != nil {
+		t.Fatalf("write spec: %v", err)
+	}
+
+	stdout, _, err := runCLI(t, "--vault", root, "--mode", "native", "--json", "ops", "apply", spec, "--atomic")
+	if err == nil {
+		t.Fatal("expected a rolled back batch to fail")
+	}
+	var result struct {
+		Failed     bool `json:"failed"`
+		RolledBack bool `json:"rolled_back"`
+		Rollback   struct {
+			Reason   string   `json:"reason"`
+			Restored []string `json:"restored"`
+			Removed  []string `json:"removed"`
+		} `json:"rollback"`
+	}
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &result); err != nil {
+		t.Fatalf("decode %s: %v", stdout, err)
+	}
+	if !result.Failed || !result.RolledBack || result.Rollback.Reason != "operation_failed" ||
+		len(result.Rollback.Restored) != 2 || len(result.Rollback.Removed) != 1 {
+		t.Fatalf("unexpected result %s", stdout)
+	}
+	for rel, content := range files {
+		if raw, _ := os.ReadFile(filepath.Join(root, rel)); string(raw) != content {
+			t.Fatalf("%s = %q, want %q", rel, raw, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "archive")); !os.IsNotExist(err) {
+		t.Fatalf("expected archive folder to be removed, got %v", err)
+	}
+
+	if _, _, err := runCLI(t, "--vault", root, "ops", "apply", spec, "--atomic", "--continue-on-error"); err == nil {
+		t.Fatal("expected --atomic with --continue-on-error to be rejected")
+	}
+}
+
+func TestOutsidePaths(t *testing.T) {
+	changed := []string{".trash/Projects/a.md", "notes/b.md", "plan.md", "planning.md"}
+	got := outsidePaths(changed, []string{"plan.md", ".trash/Projects"})
+	if len(got) != 2 || got[0] != "notes/b.md" || got[1] != "planning.md" {
+		t.Fatalf("outside paths = %v", got)
+	}
+}
@@ -23,6 +23,8 @@ func newPrintDefaultCmd() *cobra.Command {
 					"config_source":  rt.ConfigSource,
 					"requested_mode": rt.RequestedMode,
 					"effective_mode": rt.EffectiveMode,
+					"api_reachable":  rt.APIReachable,
+					"mode_reason":    rt.ModeReason,
 				})
 			}
 			rt.Printer.Println("vault path: " + rt.VaultRoot)
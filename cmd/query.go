@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/dql"
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newQueryCmd() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "query [dql]",
+		Short: "Run a Dataview (DQL) LIST/TABLE/TASK query",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src := ""
+			if len(args) == 1 {
+				src = args[0]
+			}
+			if strings.TrimSpace(file) != "" {
+				if src != "" {
+					return errs.New(errs.ExitValidation, "provide the query as an argument or --file, not both")
+				}
+				payload, err := os.ReadFile(file)
+				if err != nil {
+					return errs.Wrap(errs.ExitNotFound, "failed to read query file", err)
+				}
+				src = string(payload)
+			}
+			if strings.TrimSpace(src) == "" {
+				return errs.New(errs.ExitValidation, "query is required")
+			}
+
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			snap, _, err := store.Open(rt.VaultRoot, rt.Config.IndexDir).Refresh()
+			if err != nil {
+				return err
+			}
+			result, err := dql.Run(src, snap)
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(result)
+			}
+			rt.Printer.Println(strings.Join(result.Columns, "\t"))
+			for _, row := range result.Rows {
+				cells := make([]string, 0, len(result.Columns))
+				for _, col := range result.Columns {
+					cells = append(cells, dql.Display(row[col]))
+				}
+				rt.Printer.Println(strings.Join(cells, "\t"))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "Read the DQL query from a file")
+	return cmd
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQueryCommand(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"a.md": "---\nstatus: active\npriority: 2\n---\n- [ ] ship it\n",
+		"b.md": "---\nstatus: active\npriority: 7\n---\nbody\n",
+		"c.md": "---\nstatus: done\n---\nbody\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := runCLI(t, "--vault", root, "--json", "query", `TABLE priority FROM "" WHERE status = "active" SORT priority DESC`)
+	if err != nil {
+		t.Fatalf("query error: %v (stderr=%q)", err, stderr)
+	}
+	var result struct {
+		Type    string           `json:"type"`
+		Columns []string         `json:"columns"`
+		Rows    []map[string]any `json:"rows"`
+	}
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &result); err != nil {
+		t.Fatalf("decode query result: %v", err)
+	}
+	if result.Type != "table" || len(result.Rows) != 2 || result.Rows[0]["path"] != "b.md" || result.Rows[0]["priority"] != 7.0 {
+		t.Fatalf("unexpected query result: %+v", result)
+	}
+
+	stdout, stderr, err = runCLI(t, "--vault", root, "query", "TASK WHERE !completed")
+	if err != nil {
+		t.Fatalf("task query error: %v (stderr=%q)", err, stderr)
+	}
+	if !strings.Contains(stdout, "a.md\t5\t \tship it\tfalse") {
+		t.Fatalf("unexpected task output: %q", stdout)
+	}
+
+	if _, _, err := runCLI(t, "--vault", root, "query", "LIST WHERE"); err == nil {
+		t.Fatalf("expected error for incomplete query")
+	}
+}
@@ -83,6 +83,12 @@ func newRootCmd() *cobra.Command {
 			cmd.SetContext(ctx)
 			return nil
 		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			if shouldBypassRuntime(cmd) {
+				return nil
+			}
+			return autoCommit(cmd, args)
+		},
 	}
 
 	root.PersistentFlags().StringVar(&rootOpts.vault, "vault", "", "Vault root path")
@@ -95,6 +101,7 @@ func newRootCmd() *cobra.Command {
 
 	root.AddCommand(newVaultCmd())
 	root.AddCommand(newNoteCmd())
+	root.AddCommand(newFolderCmd())
 	root.AddCommand(newPropCmd())
 	root.AddCommand(newTagCmd())
 	root.AddCommand(newSearchCmd())
@@ -117,6 +124,15 @@ func newRootCmd() *cobra.Command {
 	root.AddCommand(newSchemaCmd(root))
 	root.AddCommand(newOpsCmd())
 	root.AddCommand(newSearchContentCmd())
+	root.AddCommand(newIndexCmd())
+	root.AddCommand(newQueryCmd())
+	root.AddCommand(newBaseCmd())
+	root.AddCommand(newCanvasCmd())
+	root.AddCommand(newAliasesCmd())
+	root.AddCommand(newAttachmentsCmd())
+	root.AddCommand(newTrashCmd())
+	root.AddCommand(newHistoryCmd())
+	root.AddCommand(newDiffCmd())
 	root.SetHelpCommand(newHelpCmd(root))
 
 	return root
@@ -11,7 +11,8 @@ import (
 
 func newSearchCmd() *cobra.Command {
 	var tag string
-	var prop string
+	var props []string
+	var queryExpr string
 	var limit int
 	var contextChars int
 	var pathPrefix string
@@ -19,6 +20,7 @@ func newSearchCmd() *cobra.Command {
 	var maxChars int
 	var withMeta bool
 	var strict bool
+	var ranked bool
 
 	cmd := &cobra.Command{
 		Use:   "search [query]",
@@ -29,12 +31,13 @@ func newSearchCmd() *cobra.Command {
 			if len(args) == 1 {
 				text = args[0]
 			}
-			return runSearch(cmd, text, tag, prop, limit, contextChars, pathPrefix, caseSensitive, maxChars, withMeta, strict)
+			return runSearch(cmd, text, tag, props, queryExpr, limit, contextChars, pathPrefix, caseSensitive, maxChars, withMeta, strict, ranked)
 		},
 	}
 
 	cmd.Flags().StringVar(&tag, "tag", "", "Search by tag")
-	cmd.Flags().StringVar(&prop, "prop", "", "Search by property key=value")
+	cmd.Flags().StringArrayVar(&props, "prop", nil, "Filter by property, repeatable and ANDed (key=v, key!=v, key>n, key<=date, key~text, 'key contains v', has:key, missing:key)")
+	cmd.Flags().StringVar(&queryExpr, "query", "", "Search with Obsidian search syntax (OR, -term, \"phrase\", file:, tag:, line:, [prop:value], ...)")
 	cmd.Flags().IntVar(&limit, "limit", 20, "Result limit")
 	cmd.Flags().IntVar(&contextChars, "context", 80, "Snippet context chars")
 	cmd.Flags().IntVar(&maxChars, "max-chars", 0, "Maximum snippet chars per result")
@@ -42,6 +45,7 @@ func newSearchCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&caseSensitive, "case-sensitive", false, "Case sensitive text search")
 	cmd.Flags().BoolVar(&withMeta, "with-meta", false, "Include metadata and warnings in output")
 	cmd.Flags().BoolVar(&strict, "strict", false, "Fail when warnings are present (for agent guardrails)")
+	cmd.Flags().BoolVar(&ranked, "ranked", false, "Rank notes by BM25 relevance (one result per note)")
 	return cmd
 }
 
@@ -53,6 +57,7 @@ func newSearchContentCmd() *cobra.Command {
 	var maxChars int
 	var withMeta bool
 	var strict bool
+	var ranked bool
 
 	cmd := &cobra.Command{
 		Use:    "search-content <query>",
@@ -60,7 +65,7 @@ func newSearchContentCmd() *cobra.Command {
 		Hidden: true,
 		Args:   cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runSearch(cmd, args[0], "", "", limit, contextChars, pathPrefix, caseSensitive, maxChars, withMeta, strict)
+			return runSearch(cmd, args[0], "", nil, "", limit, contextChars, pathPrefix, caseSensitive, maxChars, withMeta, strict, ranked)
 		},
 	}
 
@@ -71,17 +76,28 @@ func newSearchContentCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&caseSensitive, "case-sensitive", false, "Case sensitive text search")
 	cmd.Flags().BoolVar(&withMeta, "with-meta", false, "Include metadata and warnings in output")
 	cmd.Flags().BoolVar(&strict, "strict", false, "Fail when warnings are present (for agent guardrails)")
+	cmd.Flags().BoolVar(&ranked, "ranked", false, "Rank notes by BM25 relevance (one result per note)")
 	return cmd
 }
 
-func runSearch(cmd *cobra.Command, text, tag, prop string, limit, contextChars int, pathPrefix string, caseSensitive bool, maxChars int, withMeta bool, strict bool) error {
+func runSearch(cmd *cobra.Command, text, tag string, props []string, queryExpr string, limit, contextChars int, pathPrefix string, caseSensitive bool, maxChars int, withMeta bool, strict bool, ranked bool) error {
 	if cmd.Flags().Changed("max-chars") && maxChars <= 0 {
 		return errs.New(errs.ExitValidation, "--max-chars must be > 0")
 	}
-	q, err := search.BuildQuery(text, tag, prop, limit, contextChars, pathPrefix, caseSensitive)
+	var q search.Query
+	var err error
+	if strings.TrimSpace(queryExpr) != "" {
+		if strings.TrimSpace(text+tag) != "" || len(props) > 0 {
+			return errs.New(errs.ExitValidation, "--query cannot be combined with query text, --tag, or --prop")
+		}
+		q, err = search.BuildExprQuery(queryExpr, limit, contextChars, pathPrefix, caseSensitive)
+	} else {
+		q, err = search.BuildQuery(text, tag, props, limit, contextChars, pathPrefix, caseSensitive)
+	}
 	if err != nil {
 		return err
 	}
+	q.Ranked = ranked && q.Type == search.QueryText
 	rt, err := getRuntime(cmd)
 	if err != nil {
 		return err
@@ -93,7 +109,7 @@ func runSearch(cmd *cobra.Command, text, tag, prop string, limit, contextChars i
 	if maxChars > 0 {
 		results = applySearchSnippetMaxChars(results, maxChars)
 	}
-	metadata := newOperationMetadata(strict)
+	metadata := newOperationMetadata(strict).withBackendRoute(rt)
 	metadata.CacheStatus = "on_demand"
 	if max, err := sourceFileMTimeMax(rt.VaultRoot); err == nil {
 		metadata.SourceFileMTimeMax = max
@@ -0,0 +1,11 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+func newTrashCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "trash", Short: "Vault trash operations"}
+	cmd.AddCommand(newTrashListCmd())
+	cmd.AddCommand(newTrashRestoreCmd())
+	cmd.AddCommand(newTrashEmptyCmd())
+	return cmd
+}
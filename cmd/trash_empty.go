@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/spf13/cobra"
+)
+
+func newTrashEmptyCmd() *cobra.Command {
+	var olderThan string
+	var all bool
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "empty",
+		Short: "Permanently delete trash items",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			// Emptying cannot be undone, so deleting everything must be asked
+			// for explicitly.
+			if all && olderThan != "" {
+				return errs.New(errs.ExitValidation, "--all cannot be combined with --older-than")
+			}
+			if !all && olderThan == "" && !dryRun {
+				return errs.NewDetailed(
+					errs.ExitValidation,
+					"validation_error",
+					"Pass --older-than to keep recent items, --all to delete everything, or --dry-run to preview.",
+					"trash empty needs --older-than or --all",
+				)
+			}
+			var cutoff time.Time
+			if olderThan != "" {
+				age, err := parseAgeFlag(olderThan)
+				if err != nil {
+					return err
+				}
+				cutoff = time.Now().Add(-age)
+			}
+			items, err := note.EmptyTrash(rt.VaultRoot, rt.History, cutoff, dryRun)
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(map[string]any{"deleted": items, "count": len(items), "dry_run": dryRun})
+			}
+			prefix := "deleted"
+			if dryRun {
+				prefix = "dry-run: would delete"
+			}
+			rt.Printer.Println(fmt.Sprintf("%s %d trash items", prefix, len(items)))
+			for _, item := range items {
+				rt.Printer.Println(item.ID)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Only delete items trashed longer ago than this, e.g. 30d, 2w or 12h")
+	cmd.Flags().BoolVar(&all, "all", false, "Delete every trash item")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview operation without deleting files")
+	return cmd
+}
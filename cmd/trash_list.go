@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/spf13/cobra"
+)
+
+func newTrashListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List files in the vault trash with their original paths",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			items, err := note.ListTrash(rt.VaultRoot)
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(items)
+			}
+			for _, item := range items {
+				original := item.OriginalPath
+				if original == "" {
+					original = "-"
+				}
+				rt.Printer.Println(fmt.Sprintf("%s\t%s\t%s", item.ID, original, item.DeletedAt.Format(time.RFC3339)))
+			}
+			return nil
+		},
+	}
+}
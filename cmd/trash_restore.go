@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/spf13/cobra"
+)
+
+func newTrashRestoreCmd() *cobra.Command {
+	var to string
+	var rename bool
+
+	cmd := &cobra.Command{
+		Use:   "restore <item>",
+		Short: "Restore a trash item, by id or original path, to where it was deleted from",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			item, restored, err := note.RestoreTrash(rt.VaultRoot, rt.History, args[0], to, rename)
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(map[string]any{"item": item, "restored": restored})
+			}
+			rt.Printer.Println("restored: " + restored)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&to, "to", "", "Restore to this vault path instead of the original one")
+	cmd.Flags().BoolVar(&rename, "rename", false, "Pick a free name when the target already exists")
+	return cmd
+}
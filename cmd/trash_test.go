@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/note"
+)
+
+func TestNoteDeleteIsRecoverableFromTrash(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "projects"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "projects", "plan.md"), []byte("keep me\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, stderr, err := runCLI(t, "--vault", root, "note", "delete", "projects/plan.md"); err != nil {
+		t.Fatalf("delete: %v (stderr=%q)", err, stderr)
+	}
+
+	stdout, _, err := runCLI(t, "--vault", root, "--json", "trash", "list")
+	if err != nil {
+		t.Fatalf("trash list: %v", err)
+	}
+	var items []note.TrashItem
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &items); err != nil || len(items) != 1 || items[0].OriginalPath != "projects/plan.md" {
+		t.Fatalf("unexpected trash list %s (%v)", stdout, err)
+	}
+	if _, stderr, err := runCLI(t, "--vault", root, "trash", "restore", items[0].ID); err != nil {
+		t.Fatalf("restore: %v (stderr=%q)", err, stderr)
+	}
+	if raw, err := os.ReadFile(filepath.Join(root, "projects", "plan.md")); err != nil || string(raw) != "keep me\n" {
+		t.Fatalf("restored note = %q (%v)", raw, err)
+	}
+
+	// The Local REST API cannot trash notes, so api mode deletes the local
+	// vault file itself.
+	stdout, _, err = runCLI(t, "--vault", root, "--mode", "api", "--json", "note", "delete", "projects/plan.md")
+	if err != nil {
+		t.Fatalf("delete again: %v", err)
+	}
+	var deleted struct {
+		Trashed bool `json:"trashed"`
+	}
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &deleted); err != nil || !deleted.Trashed {
+		t.Fatalf("expected api mode delete to use the trash, got %s (%v)", stdout, err)
+	}
+	stdout, _, err = runCLI(t, "--vault", root, "--json", "trash", "empty", "--older-than", "30d")
+	if err != nil {
+		t.Fatalf("trash empty: %v", err)
+	}
+	var emptied struct {
+		Count int `json:"count"`
+	}
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &emptied); err != nil || emptied.Count != 0 {
+		t.Fatalf("expected fresh item to survive --older-than, got %s (%v)", stdout, err)
+	}
+	if _, _, err := runCLI(t, "--vault", root, "trash", "empty", "--older-than", "soon"); err == nil {
+		t.Fatal("expected invalid age to be rejected")
+	}
+	if _, _, err := runCLI(t, "--vault", root, "trash", "empty"); errs.ExitCode(err) != errs.ExitValidation {
+		t.Fatalf("expected a full empty without --all to be refused, got %v", err)
+	}
+	stdout, _, err = runCLI(t, "--vault", root, "--json", "trash", "empty", "--dry-run")
+	if err != nil || json.Unmarshal(parseEnvelope(t, stdout).Data, &emptied) != nil || emptied.Count != 1 {
+		t.Fatalf("expected dry-run to preview every item, got %s (%v)", stdout, err)
+	}
+	stdout, _, err = runCLI(t, "--vault", root, "--json", "trash", "empty", "--all")
+	if err != nil || json.Unmarshal(parseEnvelope(t, stdout).Data, &emptied) != nil || emptied.Count != 1 {
+		t.Fatalf("expected --all to empty the trash, got %s (%v)", stdout, err)
+	}
+}
@@ -10,5 +10,7 @@ func newVaultCmd() *cobra.Command {
 	cmd.AddCommand(newVaultInitCmd())
 	cmd.AddCommand(newVaultMigrateCmd())
 	cmd.AddCommand(newVaultStatusCmd())
+	cmd.AddCommand(newVaultCommitCmd())
+	cmd.AddCommand(newVaultLogCmd())
 	return cmd
 }
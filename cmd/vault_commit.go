@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newVaultCommitCmd() *cobra.Command {
+	var message string
+
+	cmd := &cobra.Command{
+		Use:   "commit",
+		Short: "Commit all vault changes to the vault's git repository",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			repo, err := openVaultRepo(rt)
+			if err != nil {
+				return err
+			}
+			commit, committed, err := repo.CommitAll(message)
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(map[string]any{"committed": committed, "commit": commit})
+			}
+			if !committed {
+				rt.Printer.Println("nothing to commit")
+				return nil
+			}
+			rt.Printer.Println(fmt.Sprintf("%s %s", commit.Short, commit.Subject))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&message, "message", "m", "obsidian-cli: vault commit", "Commit message")
+	return cmd
+}
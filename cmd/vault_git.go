@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nightisyang/obsidian-cli/internal/app"
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/gitvault"
+	"github.com/nightisyang/obsidian-cli/internal/history"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// commitSubjectMax keeps auto-commit subjects within git's usual width,
+// counted in characters.
+const commitSubjectMax = 72
+
+// openVaultRepo opens the git repository holding the vault, leaving the
+// index dir out of commits.
+func openVaultRepo(rt *app.Runtime) (*gitvault.Repo, error) {
+	return gitvault.Open(rt.VaultRoot, store.Open(rt.VaultRoot, rt.Config.IndexDir).Dir())
+}
+
+// autoCommit commits the changes of a successful mutating command when
+// git_auto_commit is set. Dry runs and read-only commands commit nothing,
+// and ops apply commits its batch itself.
+func autoCommit(cmd *cobra.Command, args []string) error {
+	rt, err := getRuntime(cmd)
+	if err != nil {
+		return nil
+	}
+	path := relativeCommandPath(cmd)
+	if !traitForCommand(cmd).Mutating || path == "vault commit" || path == "ops apply" {
+		return nil
+	}
+	if flag := cmd.Flags().Lookup("dry-run"); flag != nil && flag.Value.String() == "true" {
+		return nil
+	}
+	return commitTouched(rt, commitMessage(path, args))
+}
+
+// commitTouched commits the vault paths the command changed, as recorded
+// by rt.History, when git_auto_commit is set. Other changes in the vault
+// are left for the user. Commands run by ops apply record their paths in
+// the batch's journal instead, so the batch is one commit.
+func commitTouched(rt *app.Runtime, message string) error {
+	if !rt.Config.GitAutoCommit || os.Getenv(history.JournalEnv) != "" {
+		return nil
+	}
+	paths := rt.History.Touched()
+	if len(paths) == 0 {
+		return nil
+	}
+	repo, err := openVaultRepo(rt)
+	if err != nil {
+		return err
+	}
+	if _, _, err := repo.Commit(message, paths); err != nil {
+		return errs.Wrap(errs.ExitGeneric, "changes were written but the git auto-commit failed", err)
+	}
+	return nil
+}
+
+// commitMessage derives a commit subject from the command path and its
+// arguments, such as `obsidian-cli: note move "Old plan.md" plan.md`.
+func commitMessage(path string, args []string) string {
+	parts := []string{"obsidian-cli:", path}
+	for _, arg := range args {
+		arg = strings.Join(strings.Fields(arg), " ")
+		if strings.Contains(arg, " ") || arg == "" {
+			arg = `"` + arg + `"`
+		}
+		parts = append(parts, arg)
+	}
+	subject := strings.Join(parts, " ")
+	if utf8.RuneCountInString(subject) > commitSubjectMax {
+		subject = strings.TrimSpace(string([]rune(subject)[:commitSubjectMax-3])) + "..."
+	}
+	return subject
+}
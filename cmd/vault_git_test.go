@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/nightisyang/obsidian-cli/internal/gitvault"
+)
+
+func TestGitAutoCommitAndHistory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, ".obsidian-cli.yaml"), []byte("git_auto_commit: true\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "draft.md"), []byte("unrelated edit\n"), 0o644); err != nil {
+		t.Fatalf("write draft: %v", err)
+	}
+
+	if _, stderr, err := runCLI(t, "--vault", root, "--mode", "native", "note", "create", "Plan", "--content", "first draft"); err != nil {
+		t.Fatalf("create: %v (stderr=%q)", err, stderr)
+	}
+	if _, stderr, err := runCLI(t, "--vault", root, "--mode", "native", "note", "append", "plan.md", "second line"); err != nil {
+		t.Fatalf("append: %v (stderr=%q)", err, stderr)
+	}
+	if _, _, err := runCLI(t, "--vault", root, "--mode", "native", "note", "append", "plan.md", "ignored", "--dry-run"); err != nil {
+		t.Fatalf("dry-run append: %v", err)
+	}
+
+	stdout, _, err := runCLI(t, "--vault", root, "--json", "vault", "log", "plan.md")
+	if err != nil {
+		t.Fatalf("vault log: %v", err)
+	}
+	var commits []gitvault.Commit
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &commits); err != nil || len(commits) != 2 {
+		t.Fatalf("unexpected log %s (%v)", stdout, err)
+	}
+	if commits[0].Subject != `obsidian-cli: note append plan.md "second line"` || !strings.HasPrefix(commits[1].Subject, "obsidian-cli: note create Plan") {
+		t.Fatalf("unexpected subjects %+v", commits)
+	}
+	if out, _ := exec.Command("git", "-C", root, "status", "--porcelain", "--", ".", ":(exclude).obsidian-cli-index").Output(); string(out) != "?? .obsidian-cli.yaml\n?? draft.md\n" {
+		t.Fatalf("expected only unrelated files left uncommitted, got %q", out)
+	}
+
+	stdout, _, err = runCLI(t, "--vault", root, "--json", "history", "list", "plan")
+	if err != nil || !strings.Contains(stdout, `"source": "git"`) {
+		t.Fatalf("history list = %s (%v)", stdout, err)
+	}
+	stdout, _, err = runCLI(t, "--vault", root, "diff", "plan.md")
+	if err != nil || !strings.Contains(stdout, "--- plan.md@"+commits[1].Short) || !strings.Contains(stdout, "+second line") {
+		t.Fatalf("diff = %q (%v)", stdout, err)
+	}
+
+	stdout, _, err = runCLI(t, "--vault", root, "--json", "vault", "commit", "-m", "manual")
+	if err != nil || !strings.Contains(stdout, `"committed": true`) {
+		t.Fatalf("vault commit = %s (%v)", stdout, err)
+	}
+
+	t.Setenv(opsExecEnv, "1")
+	spec := filepath.Join(t.TempDir(), "ops.json")
+	payload := `{"ops":[{"args":["note","append","plan.md","third"]},{"args":["note","create","Next"]}]}`
+	if err := os.WriteFile(spec, []byte(payload), 0o644); err != nil {
+		t.Fatalf("write spec: %v", err)
+	}
+	if _, stderr, err := runCLI(t, "--vault", root, "--mode", "native", "ops", "apply", spec); err != nil {
+		t.Fatalf("ops apply: %v (stderr=%q)", err, stderr)
+	}
+	stdout, _, err = runCLI(t, "--vault", root, "--json", "vault", "log")
+	if err != nil {
+		t.Fatalf("vault log: %v", err)
+	}
+	if err := json.Unmarshal(parseEnvelope(t, stdout).Data, &commits); err != nil || len(commits) != 4 || !strings.HasPrefix(commits[0].Subject, "obsidian-cli: ops apply") {
+		t.Fatalf("expected one commit for the batch, got %s (%v)", stdout, err)
+	}
+	if out, _ := exec.Command("git", "-C", root, "status", "--porcelain", "--", ".", ":(exclude).obsidian-cli-index").Output(); len(out) != 0 {
+		t.Fatalf("expected the batch to be committed, got %q", out)
+	}
+}
+
+func TestCommitMessage(t *testing.T) {
+	if got := commitMessage("note move", []string{"Old plan.md", "plan.md"}); got != `obsidian-cli: note move "Old plan.md" plan.md` {
+		t.Fatalf("unexpected message %q", got)
+	}
+	if got := commitMessage("note append", []string{"daily.md", strings.Repeat("x", 100)}); len(got) != commitSubjectMax || !strings.HasSuffix(got, "...") {
+		t.Fatalf("expected a truncated subject, got %q", got)
+	}
+	got := commitMessage("note create", []string{strings.Repeat("会議メモ", 20)})
+	if !utf8.ValidString(got) || utf8.RuneCountInString(got) != commitSubjectMax || !strings.HasSuffix(got, "...") {
+		t.Fatalf("expected a valid truncated subject, got %q", got)
+	}
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/vault"
+	"github.com/spf13/cobra"
+)
+
+func newVaultLogCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "log [path]",
+		Short: "List git commits touching the vault or one note, newest first",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := getRuntime(cmd)
+			if err != nil {
+				return err
+			}
+			repo, err := openVaultRepo(rt)
+			if err != nil {
+				return err
+			}
+			rel := ""
+			if len(args) == 1 {
+				_, normalized, err := vault.ResolveNoteAbs(rt.VaultRoot, args[0])
+				if err != nil {
+					return err
+				}
+				rel = filepath.ToSlash(normalized)
+			}
+			commits, err := repo.Log(rel, limit)
+			if err != nil {
+				return err
+			}
+			if rt.Printer.JSON {
+				return rt.Printer.PrintJSON(commits)
+			}
+			for _, c := range commits {
+				rt.Printer.Println(fmt.Sprintf("%s\t%s\t%s", c.Short, c.Date.Format(time.RFC3339), c.Subject))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum commits to list (0 for all)")
+	return cmd
+}
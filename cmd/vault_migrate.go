@@ -20,7 +20,7 @@ func newVaultMigrateCmd() *cobra.Command {
 				return err
 			}
 
-			result, err := vault.MigrateFrontmatter(rt.VaultRoot, vault.MigrateOptions{
+			result, err := vault.MigrateFrontmatter(rt.VaultRoot, rt.History, vault.MigrateOptions{
 				DryRun: dryRun,
 				Kind:   defaultKind,
 			})
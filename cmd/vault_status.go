@@ -3,7 +3,7 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/nightisyang/obsidian-cli/internal/vault"
+	"github.com/nightisyang/obsidian-cli/internal/store"
 	"github.com/spf13/cobra"
 )
 
@@ -14,15 +14,26 @@ func newVaultStatusCmd() *cobra.Command {
 		Use:   "status",
 		Short: "Show vault status",
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			_ = reindex
 			runtime, err := getRuntime(cmd)
 			if err != nil {
 				return err
 			}
-			status, err := vault.ComputeStatus(runtime.VaultRoot, runtime.ConfigPath, runtime.ConfigSource, runtime.EffectiveMode)
+			if reindex {
+				if _, _, err := store.Open(runtime.VaultRoot, runtime.Config.IndexDir).Rebuild(); err != nil {
+					return err
+				}
+			}
+			status, err := runtime.Backend.VaultStatus(runtime.Context)
 			if err != nil {
 				return err
 			}
+			status.Root = runtime.VaultRoot
+			status.ConfigPath = runtime.ConfigPath
+			status.ConfigSource = runtime.ConfigSource
+			status.EffectiveMode = runtime.EffectiveMode
+			status.RequestedMode = runtime.RequestedMode
+			status.APIReachable = runtime.APIReachable
+			status.ModeReason = runtime.ModeReason
 			if runtime.Printer.JSON {
 				return runtime.Printer.PrintJSON(status)
 			}
@@ -32,7 +43,10 @@ func newVaultStatusCmd() *cobra.Command {
 			runtime.Printer.Printf("root: %s\n", status.Root)
 			runtime.Printer.Printf("notes: %d\n", status.NoteCount)
 			runtime.Printer.Printf("tags: %d\n", status.TagCount)
-			runtime.Printer.Printf("mode: %s\n", status.EffectiveMode)
+			runtime.Printer.Printf("mode: %s (requested %s)\n", status.EffectiveMode, status.RequestedMode)
+			if status.ModeReason != "" {
+				runtime.Printer.Printf("mode reason: %s\n", status.ModeReason)
+			}
 			if status.ConfigPath != "" {
 				runtime.Printer.Printf("config: %s (%s)\n", status.ConfigPath, status.ConfigSource)
 			} else {
@@ -5,7 +5,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"path"
-	"path/filepath"
 	"sort"
 	"strings"
 
@@ -13,6 +12,7 @@ import (
 	"github.com/nightisyang/obsidian-cli/internal/errs"
 	"github.com/nightisyang/obsidian-cli/internal/index"
 	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/nightisyang/obsidian-cli/internal/store"
 )
 
 func verifyHashPrecondition(rt *app.Runtime, path, expectedHash string) error {
@@ -63,11 +63,12 @@ func enforceNoteSizeGuard(rt *app.Runtime, n note.Note) error {
 	baseRef := strings.TrimSuffix(path.Base(n.Path), ".md")
 	splitRef := strings.TrimSuffix(path.Base(splitPath), ".md")
 	message := fmt.Sprintf(
-		"note %s is %d bytes, exceeding %d bytes. split content into %s and add reciprocal backlinks.\nexample:\n1) obsidian-cli --vault <vault> note create %q --content \"<moved section>\"\n2) obsidian-cli --vault <vault> note append %s \"Continued in [[%s]]\"\n3) obsidian-cli --vault <vault> note append %s \"Context from [[%s]]\"",
+		"note %s is %d bytes, exceeding %d bytes. split content into %s and add reciprocal backlinks.\nsplit every level 2 section in one call:\nobsidian-cli --vault <vault> note split %s --level 2\nor by hand:\n1) obsidian-cli --vault <vault> note create %q --content \"<moved section>\"\n2) obsidian-cli --vault <vault> note append %s \"Continued in [[%s]]\"\n3) obsidian-cli --vault <vault> note append %s \"Context from [[%s]]\"",
 		n.Path,
 		sizeBytes,
 		threshold,
 		splitPath,
+		n.Path,
 		splitTitle,
 		n.Path,
 		splitRef,
@@ -77,20 +78,13 @@ func enforceNoteSizeGuard(rt *app.Runtime, n note.Note) error {
 	return errs.NewDetailed(
 		errs.ExitValidation,
 		"note_size_limit_exceeded",
-		"Split into a new note with reciprocal backlinks, or raise --note-size-max-bytes.",
+		"Run note split, or split into a new note with reciprocal backlinks by hand, or raise --note-size-max-bytes.",
 		message,
 	)
 }
 
 func suggestedSplitPath(currentPath string) string {
-	clean := strings.TrimSpace(currentPath)
-	if clean == "" {
-		return "split-note-part-2.md"
-	}
-	if strings.HasSuffix(clean, ".md") {
-		return strings.TrimSuffix(clean, ".md") + "-part-2.md"
-	}
-	return clean + "-part-2.md"
+	return note.SplitPartPath(currentPath, 2)
 }
 
 func suggestedSplitTitle(relPath string) string {
@@ -115,37 +109,25 @@ func enforceBacklinkIntegrity(rt *app.Runtime, n note.Note) error {
 	if len(targets) == 0 {
 		return nil
 	}
-	files, err := index.ListMarkdownFiles(rt.VaultRoot)
+	s := store.Open(rt.VaultRoot, rt.Config.IndexDir)
+	snap, _, err := s.Refresh()
 	if err != nil {
 		return err
 	}
-	paths := map[string]struct{}{}
-	stems := map[string]struct{}{}
-	for _, abs := range files {
-		rel, relErr := filepath.Rel(rt.VaultRoot, abs)
-		if relErr != nil {
-			return relErr
-		}
-		rel = filepath.ToSlash(rel)
-		pathKey := index.NormalizeLinkTarget(strings.TrimSuffix(rel, ".md"))
-		stemKey := index.NormalizeLinkTarget(strings.TrimSuffix(filepath.Base(rel), ".md"))
-		if pathKey != "" {
-			paths[pathKey] = struct{}{}
-		}
-		if stemKey != "" {
-			stems[stemKey] = struct{}{}
-		}
+	resolver, err := s.Resolver(snap)
+	if err != nil {
+		return err
+	}
+	resolver.Add(n.Path)
+	for _, alias := range n.Frontmatter.Aliases {
+		resolver.AddAlias(alias, n.Path)
 	}
 
 	unresolved := make([]string, 0)
 	for _, target := range targets {
-		if _, ok := paths[target]; ok {
-			continue
-		}
-		if _, ok := stems[target]; ok {
-			continue
+		if res := resolver.Resolve(n.Path, target); res.Path == "" && !res.Ambiguous() {
+			unresolved = append(unresolved, target)
 		}
-		unresolved = append(unresolved, target)
 	}
 	if len(unresolved) == 0 {
 		return nil
@@ -177,44 +159,23 @@ func enforceNoOrphanNotes(rt *app.Runtime, n note.Note) error {
 		return nil
 	}
 
-	idx, err := index.BuildIndex(rt.VaultRoot)
+	snap, _, err := store.Open(rt.VaultRoot, rt.Config.IndexDir).Refresh()
 	if err != nil {
 		return err
 	}
 
-	selfPath := index.NormalizeLinkTarget(strings.TrimSuffix(n.Path, ".md"))
-	selfStem := index.NormalizeLinkTarget(strings.TrimSuffix(path.Base(n.Path), ".md"))
-
-	existingPaths := map[string]struct{}{}
-	existingStems := map[string]struct{}{}
-	for rel := range idx.SourceToTarget {
-		pathKey := index.NormalizeLinkTarget(strings.TrimSuffix(rel, ".md"))
-		stemKey := index.NormalizeLinkTarget(strings.TrimSuffix(filepath.Base(rel), ".md"))
-		if pathKey != "" {
-			existingPaths[pathKey] = struct{}{}
-		}
-		if stemKey != "" {
-			existingStems[stemKey] = struct{}{}
-		}
-	}
-
+	resolver := snap.Resolver()
+	resolver.Add(n.Path)
 	hasOutgoing := false
-	for _, target := range index.ParseWikiLinks(n.Body) {
-		if target == selfPath || target == selfStem {
-			continue
-		}
-		if _, ok := existingPaths[target]; ok {
-			hasOutgoing = true
-			break
-		}
-		if _, ok := existingStems[target]; ok {
+	for _, link := range resolver.ResolveLinks(n.Path, note.ParseLinks(n.Body)) {
+		if (link.Path != "" && link.Path != n.Path) || len(link.Candidates) > 1 {
 			hasOutgoing = true
 			break
 		}
 	}
 
 	hasIncoming := false
-	for _, source := range index.BacklinksForPath(rt.VaultRoot, idx, n.Path) {
+	for _, source := range snap.Backlinks(n.Path) {
 		if source != n.Path {
 			hasIncoming = true
 			break
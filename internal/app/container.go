@@ -2,12 +2,15 @@ package app
 
 import (
 	"context"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/nightisyang/obsidian-cli/internal/backend"
 	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/history"
 	"github.com/nightisyang/obsidian-cli/internal/output"
+	"github.com/nightisyang/obsidian-cli/internal/store"
 	"github.com/nightisyang/obsidian-cli/internal/vault"
 )
 
@@ -39,14 +42,38 @@ func Build(ctx context.Context, opts Options) (*Runtime, error) {
 		return nil, errs.New(errs.ExitValidation, "mode must be one of auto, native, api")
 	}
 
-	effectiveMode := requestedMode
-	if requestedMode == "auto" {
-		effectiveMode = "native"
-	}
-
 	printer := output.NewPrinter(opts.JSON, opts.Quiet)
 
-	nativeBackend := backend.NewNativeBackend(resolved.VaultRoot, resolved.Config, effectiveMode)
+	hist := history.Open(resolved.VaultRoot, store.Open(resolved.VaultRoot, resolved.Config.IndexDir).Dir(), resolved.Config.HistoryKeep)
+	if dir := os.Getenv(vault.CheckpointEnv); dir != "" {
+		hist.BeforeTouch = func(rel string) error {
+			return vault.SaveToCheckpoint(dir, resolved.VaultRoot, rel)
+		}
+	}
+	effectiveMode := requestedMode
+	apiReachable := false
+	modeReason := ""
+	var selected backend.Backend
+	switch requestedMode {
+	case "api":
+		selected = backend.NewAPIBackend(resolved.VaultRoot, hist, resolved.Config, effectiveMode)
+		modeReason = "api mode requested"
+	case "auto":
+		api := backend.NewAPIBackend(resolved.VaultRoot, hist, resolved.Config, "api")
+		if err := probeAPI(ctx, api, resolved.Config.APITimeout); err != nil {
+			effectiveMode = "native"
+			modeReason = "Local REST API unavailable: " + err.Error()
+			selected = backend.NewNativeBackend(resolved.VaultRoot, hist, resolved.Config, effectiveMode)
+		} else {
+			effectiveMode = "api"
+			apiReachable = true
+			modeReason = "Local REST API reachable at " + resolved.Config.APIBaseURL
+			selected = backend.NewAutoBackend(api, backend.NewNativeBackend(resolved.VaultRoot, hist, resolved.Config, effectiveMode))
+		}
+	default:
+		selected = backend.NewNativeBackend(resolved.VaultRoot, hist, resolved.Config, effectiveMode)
+		modeReason = "native mode requested"
+	}
 
 	return &Runtime{
 		Context:       ctx,
@@ -56,7 +83,23 @@ func Build(ctx context.Context, opts Options) (*Runtime, error) {
 		ConfigSource:  resolved.Source,
 		RequestedMode: requestedMode,
 		EffectiveMode: effectiveMode,
+		APIReachable:  apiReachable,
+		ModeReason:    modeReason,
 		Printer:       printer,
-		Backend:       nativeBackend,
+		Backend:       selected,
+		History:       hist,
 	}, nil
 }
+
+// probeAPI checks whether the Local REST API answers within timeout.
+func probeAPI(ctx context.Context, api *backend.APIBackend, timeout time.Duration) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if timeout <= 0 {
+		timeout = vault.DefaultConfig().APITimeout
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return api.Ping(probeCtx)
+}
@@ -2,8 +2,13 @@ package app
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/nightisyang/obsidian-cli/internal/backend"
 	"github.com/nightisyang/obsidian-cli/internal/errs"
 )
 
@@ -20,3 +25,36 @@ func TestBuildRejectsInvalidMode(t *testing.T) {
 		t.Fatalf("expected validation app error, got %T (%v)", err, err)
 	}
 }
+
+func TestBuildAutoModeUsesReachableAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"OK","authenticated":true}`))
+	}))
+	defer server.Close()
+
+	vaultRoot := t.TempDir()
+	configPath := filepath.Join(vaultRoot, ".obsidian-cli.yaml")
+	if err := os.WriteFile(configPath, []byte("api_base_url: \""+server.URL+"\"\napi_timeout: \"1s\"\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	rt, err := Build(context.Background(), Options{Vault: vaultRoot, Mode: "auto"})
+	if err != nil {
+		t.Fatalf("Build error: %v", err)
+	}
+	if rt.EffectiveMode != "api" || !rt.APIReachable {
+		t.Fatalf("expected api effective mode, got %q reachable=%v", rt.EffectiveMode, rt.APIReachable)
+	}
+	if _, ok := rt.Backend.(*backend.AutoBackend); !ok {
+		t.Fatalf("expected auto backend, got %T", rt.Backend)
+	}
+
+	server.Close()
+	rt, err = Build(context.Background(), Options{Vault: vaultRoot, Mode: "auto"})
+	if err != nil {
+		t.Fatalf("Build error: %v", err)
+	}
+	if rt.EffectiveMode != "native" || rt.APIReachable || rt.ModeReason == "" {
+		t.Fatalf("expected native fallback with reason, got %q reachable=%v reason=%q", rt.EffectiveMode, rt.APIReachable, rt.ModeReason)
+	}
+}
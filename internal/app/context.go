@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/nightisyang/obsidian-cli/internal/backend"
+	"github.com/nightisyang/obsidian-cli/internal/history"
 	"github.com/nightisyang/obsidian-cli/internal/output"
 	"github.com/nightisyang/obsidian-cli/internal/vault"
 )
@@ -16,6 +17,11 @@ type Runtime struct {
 	ConfigSource  string
 	RequestedMode string
 	EffectiveMode string
+	APIReachable  bool
+	ModeReason    string
 	Printer       *output.Printer
 	Backend       backend.Backend
+	// History receives the prior content of every file a command
+	// changes outside the backend.
+	History *history.Store
 }
@@ -0,0 +1,1203 @@
+package backend
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/frontmatter"
+	"github.com/nightisyang/obsidian-cli/internal/history"
+	"github.com/nightisyang/obsidian-cli/internal/index"
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/nightisyang/obsidian-cli/internal/search"
+	"github.com/nightisyang/obsidian-cli/internal/tasks"
+	"github.com/nightisyang/obsidian-cli/internal/templates"
+	"github.com/nightisyang/obsidian-cli/internal/vault"
+)
+
+// APIBackend serves every Backend call through the Obsidian Local REST API
+// plugin. Note manipulation reuses the pure helpers in the note, tasks and
+// index packages so both backends produce identical files.
+type APIBackend struct {
+	vaultRoot string
+	history   *history.Store
+	cfg       vault.Config
+	mode      string
+	baseURL   string
+	token     string
+	client    *http.Client
+}
+
+type apiFileList struct {
+	Files []string `json:"files"`
+}
+
+type apiSearchHit struct {
+	Filename string  `json:"filename"`
+	Score    float64 `json:"score"`
+	Matches  []struct {
+		Match struct {
+			Start int `json:"start"`
+			End   int `json:"end"`
+		} `json:"match"`
+		Context string `json:"context"`
+	} `json:"matches"`
+}
+
+type apiCommandList struct {
+	Commands []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"commands"`
+}
+
+type apiErrorBody struct {
+	ErrorCode int    `json:"errorCode"`
+	Message   string `json:"message"`
+}
+
+func NewAPIBackend(vaultRoot string, hist *history.Store, cfg vault.Config, mode string) *APIBackend {
+	timeout := cfg.APITimeout
+	if timeout <= 0 {
+		timeout = vault.DefaultConfig().APITimeout
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(cfg.APIBaseURL), "/")
+	if baseURL == "" {
+		baseURL = vault.DefaultConfig().APIBaseURL
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if isLoopbackURL(baseURL) {
+		// The Local REST API plugin serves a self-signed certificate on localhost.
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &APIBackend{
+		vaultRoot: vaultRoot,
+		history:   hist,
+		cfg:       cfg,
+		mode:      mode,
+		baseURL:   baseURL,
+		token:     strings.TrimSpace(cfg.APIToken),
+		client:    &http.Client{Timeout: timeout, Transport: transport},
+	}
+}
+
+func (b *APIBackend) VaultStatus(ctx context.Context) (vault.Status, error) {
+	notes, err := b.readAll(ctx, "")
+	if err != nil {
+		return vault.Status{}, err
+	}
+	tags := map[string]struct{}{}
+	for _, n := range notes {
+		for _, tag := range index.NoteTags(n) {
+			tags[tag] = struct{}{}
+		}
+	}
+	return vault.Status{
+		Root:          b.vaultRoot,
+		EffectiveMode: b.mode,
+		NoteCount:     len(notes),
+		TagCount:      len(tags),
+	}, nil
+}
+
+func (b *APIBackend) CreateNote(ctx context.Context, in note.CreateInput) (note.Note, error) {
+	if strings.TrimSpace(in.Template) != "" {
+		tpl, err := b.ReadTemplate(ctx, in.Template, in.Title, true)
+		if err != nil {
+			return note.Note{}, err
+		}
+		in.Content = tpl.Content
+	}
+	n, err := note.Prepare(in)
+	if err != nil {
+		return note.Note{}, err
+	}
+	exists, err := b.exists(ctx, n.Path)
+	if err != nil {
+		return note.Note{}, err
+	}
+	if exists {
+		return note.Note{}, errs.New(errs.ExitValidation, "note already exists")
+	}
+	return b.writeNote(ctx, n, true)
+}
+
+// GetNote reads a note by path, falling back to a unique file name,
+// frontmatter title or alias match.
+func (b *APIBackend) GetNote(ctx context.Context, path string) (note.Note, error) {
+	n, err := b.readNote(ctx, path)
+	if errs.ExitCode(err) != errs.ExitNotFound {
+		return n, err
+	}
+	notes, readErr := b.readAll(ctx, "")
+	if readErr != nil {
+		return note.Note{}, err
+	}
+	ref := noteNameRef(path)
+	matches := []string{}
+	byPath := map[string]note.Note{}
+	for _, candidate := range notes {
+		for _, name := range candidate.Names() {
+			if strings.EqualFold(strings.TrimSpace(name), ref) {
+				matches = append(matches, candidate.Path)
+				byPath[candidate.Path] = candidate
+				break
+			}
+		}
+	}
+	match, pickErr := pickNamedNote(path, matches)
+	if pickErr != nil {
+		return note.Note{}, pickErr
+	}
+	if match == "" {
+		return note.Note{}, err
+	}
+	return byPath[match], nil
+}
+
+func (b *APIBackend) GetHeading(ctx context.Context, path, heading string) (note.HeadingSection, error) {
+	n, err := b.readNote(ctx, path)
+	if err != nil {
+		return note.HeadingSection{}, err
+	}
+	return note.SectionFromNote(n, heading)
+}
+
+func (b *APIBackend) GetBlock(ctx context.Context, path, blockID string) (note.Block, error) {
+	n, err := b.readNote(ctx, path)
+	if err != nil {
+		return note.Block{}, err
+	}
+	return note.BlockFromNote(n, blockID)
+}
+
+func (b *APIBackend) SetBlock(ctx context.Context, path, blockID, content string) (note.Block, error) {
+	n, err := b.readNote(ctx, path)
+	if err != nil {
+		return note.Block{}, err
+	}
+	n, err = note.ReplaceBlock(n, blockID, content)
+	if err != nil {
+		return note.Block{}, err
+	}
+	updated, err := b.writeNote(ctx, n, false)
+	if err != nil {
+		return note.Block{}, err
+	}
+	return note.BlockFromNote(updated, blockID)
+}
+
+func (b *APIBackend) AppendNote(ctx context.Context, path, content string) (note.Note, error) {
+	n, err := b.readNote(ctx, path)
+	if err != nil {
+		return note.Note{}, err
+	}
+	n.Body = note.AppendText(n.Body, content, false)
+	return b.writeNote(ctx, n, false)
+}
+
+func (b *APIBackend) PrependNote(ctx context.Context, path, content string) (note.Note, error) {
+	n, err := b.readNote(ctx, path)
+	if err != nil {
+		return note.Note{}, err
+	}
+	n.Body = note.PrependText(n.Body, content, false)
+	return b.writeNote(ctx, n, false)
+}
+
+func (b *APIBackend) DeleteNote(ctx context.Context, path string) error {
+	abs, rel, err := vault.ResolveNoteAbs(b.vaultRoot, path)
+	if err != nil {
+		return err
+	}
+	if err := b.history.Capture(abs); err != nil {
+		return err
+	}
+	_, err = b.do(ctx, http.MethodDelete, vaultFilePath(rel), nil, "", nil)
+	if err != nil && errs.ExitCode(err) == errs.ExitNotFound {
+		return errs.New(errs.ExitNotFound, "note not found")
+	}
+	return err
+}
+
+func (b *APIBackend) ListNotes(ctx context.Context, dir string, opts note.ListOptions) ([]note.Note, error) {
+	paths, err := b.listMarkdown(ctx, dir, opts.Recursive)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]note.Note, 0, len(paths))
+	for _, p := range paths {
+		n, err := b.readNote(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, n)
+	}
+	return note.SortNotes(entries, opts), nil
+}
+
+func (b *APIBackend) MoveNote(ctx context.Context, src, dst string, opts note.MoveOptions) (note.Note, error) {
+	srcAbs, srcRel, err := vault.ResolveNoteAbs(b.vaultRoot, src)
+	if err != nil {
+		return note.Note{}, err
+	}
+	_, dstRel, err := vault.ResolveNoteAbs(b.vaultRoot, dst)
+	if err != nil {
+		return note.Note{}, err
+	}
+	n, err := b.readNote(ctx, srcRel)
+	if err != nil {
+		if errs.ExitCode(err) == errs.ExitNotFound {
+			return note.Note{}, errs.New(errs.ExitNotFound, "source note not found")
+		}
+		return note.Note{}, err
+	}
+	exists, err := b.exists(ctx, dstRel)
+	if err != nil {
+		return note.Note{}, err
+	}
+	if exists {
+		return note.Note{}, errs.New(errs.ExitValidation, "destination already exists")
+	}
+	n.Path = dstRel
+	if opts.DryRun {
+		n.Title = path.Base(strings.TrimSuffix(dstRel, ".md"))
+		return n, nil
+	}
+
+	// Once the destination is written the move is under way: later failures
+	// report what was already applied instead of looking like a clean error.
+	moved, err := b.writeNote(ctx, n, false)
+	if err != nil {
+		return note.Note{}, err
+	}
+	done := []string{"wrote " + dstRel}
+	if err := b.history.Capture(srcAbs); err != nil {
+		return note.Note{}, partialApply(done, err)
+	}
+	if _, err := b.do(ctx, http.MethodDelete, vaultFilePath(srcRel), nil, "", nil); err != nil {
+		return note.Note{}, partialApply(done, err)
+	}
+	done = append(done, "deleted "+srcRel)
+	if !opts.UpdateLinks {
+		return moved, nil
+	}
+
+	paths, err := b.listMarkdown(ctx, "", true)
+	if err != nil {
+		return note.Note{}, partialApply(done, err)
+	}
+	for _, p := range paths {
+		raw, err := b.readRaw(ctx, p)
+		if err != nil {
+			return note.Note{}, partialApply(done, err)
+		}
+		updated := note.RewriteLinkText(raw, p, srcRel, dstRel)
+		if updated == raw {
+			continue
+		}
+		if err := b.writeRaw(ctx, p, updated); err != nil {
+			return note.Note{}, partialApply(done, err)
+		}
+		done = append(done, "rewrote links in "+p)
+		if p == dstRel {
+			moved, err = note.Parse(dstRel, updated)
+			if err != nil {
+				return note.Note{}, partialApply(done, err)
+			}
+		}
+	}
+	return moved, nil
+}
+
+func (b *APIBackend) DailyPath(ctx context.Context, at time.Time) (string, error) {
+	payload, err := b.readRaw(ctx, ".obsidian/daily-notes.json")
+	if err != nil {
+		// A missing or unreadable daily notes config falls back to the defaults.
+		payload = ""
+	}
+	return note.DailyPathFromConfig([]byte(payload), at), nil
+}
+
+func (b *APIBackend) DailyRead(ctx context.Context, at time.Time, create bool) (note.Note, error) {
+	p, err := b.DailyPath(ctx, at)
+	if err != nil {
+		return note.Note{}, err
+	}
+	if create {
+		return b.ensureNote(ctx, p)
+	}
+	return b.readNote(ctx, p)
+}
+
+func (b *APIBackend) DailyAppend(ctx context.Context, at time.Time, content string, inline bool) (note.Note, error) {
+	n, creating, err := b.dailyNote(ctx, at)
+	if err != nil {
+		return note.Note{}, err
+	}
+	n.Body = note.AppendText(n.Body, content, inline)
+	return b.writeNote(ctx, n, creating)
+}
+
+func (b *APIBackend) DailyPrepend(ctx context.Context, at time.Time, content string, inline bool) (note.Note, error) {
+	n, creating, err := b.dailyNote(ctx, at)
+	if err != nil {
+		return note.Note{}, err
+	}
+	n.Body = note.PrependText(n.Body, content, inline)
+	return b.writeNote(ctx, n, creating)
+}
+
+// dailyNote reads the daily note for at, or returns an empty one to be
+// created, so that adding to it is a single write.
+func (b *APIBackend) dailyNote(ctx context.Context, at time.Time) (note.Note, bool, error) {
+	p, err := b.DailyPath(ctx, at)
+	if err != nil {
+		return note.Note{}, false, err
+	}
+	n, err := b.readNote(ctx, p)
+	if err == nil {
+		return n, false, nil
+	}
+	if errs.ExitCode(err) != errs.ExitNotFound {
+		return note.Note{}, false, err
+	}
+	return note.Note{Path: p, Frontmatter: note.Frontmatter{Extra: map[string]any{}}}, true, nil
+}
+
+func (b *APIBackend) ListTemplates(ctx context.Context) ([]templates.TemplateInfo, error) {
+	dir := b.templatesDir()
+	paths, err := b.listMarkdown(ctx, dir, false)
+	if err != nil {
+		if errs.ExitCode(err) == errs.ExitNotFound {
+			return []templates.TemplateInfo{}, nil
+		}
+		return nil, err
+	}
+	out := make([]templates.TemplateInfo, 0, len(paths))
+	for _, p := range paths {
+		out = append(out, templates.TemplateInfo{
+			Name: strings.TrimSuffix(path.Base(p), path.Ext(p)),
+			Path: p,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (b *APIBackend) ReadTemplate(ctx context.Context, name, title string, resolve bool) (templates.Template, error) {
+	cleanName := strings.Trim(strings.ReplaceAll(strings.TrimSpace(name), "\\", "/"), "/")
+	if cleanName == "" {
+		return templates.Template{}, errs.New(errs.ExitValidation, "template name is required")
+	}
+	if !strings.HasSuffix(strings.ToLower(cleanName), ".md") {
+		cleanName += ".md"
+	}
+	rel := path.Join(b.templatesDir(), cleanName)
+	if rel != b.templatesDir() && !strings.HasPrefix(rel, b.templatesDir()+"/") {
+		return templates.Template{}, errs.New(errs.ExitValidation, "template path escapes templates directory")
+	}
+
+	content, err := b.readRaw(ctx, rel)
+	if err != nil && errs.ExitCode(err) == errs.ExitNotFound {
+		infos, listErr := b.ListTemplates(ctx)
+		if listErr != nil {
+			return templates.Template{}, listErr
+		}
+		lower := strings.ToLower(strings.TrimSuffix(cleanName, ".md"))
+		for _, info := range infos {
+			if strings.ToLower(info.Name) == lower {
+				rel = info.Path
+				content, err = b.readRaw(ctx, rel)
+				break
+			}
+		}
+	}
+	if err != nil {
+		if errs.ExitCode(err) == errs.ExitNotFound {
+			return templates.Template{}, errs.New(errs.ExitNotFound, "template not found")
+		}
+		return templates.Template{}, err
+	}
+	if resolve {
+		content = templates.ResolveVariables(content, title, now())
+	}
+	return templates.Template{
+		Name:    strings.TrimSuffix(path.Base(rel), path.Ext(rel)),
+		Path:    rel,
+		Content: content,
+	}, nil
+}
+
+func (b *APIBackend) InsertTemplate(ctx context.Context, path, name, title string, resolve bool) (note.Note, error) {
+	n, err := b.readNote(ctx, path)
+	if err != nil {
+		return note.Note{}, err
+	}
+	tpl, err := b.ReadTemplate(ctx, name, title, resolve)
+	if err != nil {
+		return note.Note{}, err
+	}
+	n.Body += tpl.Content
+	return b.writeNote(ctx, n, false)
+}
+
+func (b *APIBackend) Search(ctx context.Context, q search.Query) ([]search.SearchResult, error) {
+	switch q.Type {
+	case search.QueryText:
+		return b.searchText(ctx, q)
+	case search.QueryTag:
+		results, err := b.SearchTag(ctx, q.Tag, 0)
+		if err != nil {
+			return nil, err
+		}
+		results = filterByPath(results, q.Path)
+		if q.Limit > 0 && len(results) > q.Limit {
+			results = results[:q.Limit]
+		}
+		return results, nil
+	case search.QueryProp:
+		notes, err := b.readAll(ctx, q.Path)
+		if err != nil {
+			return nil, err
+		}
+		return matchProps(notes, q), nil
+	case search.QueryExpr:
+		notes, err := b.readAll(ctx, q.Path)
+		if err != nil {
+			return nil, err
+		}
+		return matchExprs(notes, q), nil
+	default:
+		return nil, errs.New(errs.ExitValidation, "unknown search query type")
+	}
+}
+
+func (b *APIBackend) ListTags(ctx context.Context, opts index.TagListOptions) ([]index.TagCount, error) {
+	notes, err := b.readAll(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, n := range notes {
+		for _, tag := range index.NoteTags(n) {
+			counts[tag]++
+		}
+	}
+	return index.RankTags(counts, opts), nil
+}
+
+func (b *APIBackend) SearchTag(ctx context.Context, tag string, limit int) ([]search.SearchResult, error) {
+	norm := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(tag, "#")))
+	if norm == "" {
+		return []search.SearchResult{}, nil
+	}
+	notes, err := b.readAll(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	results := []search.SearchResult{}
+	for _, n := range notes {
+		if !index.HasTag(n, norm) {
+			continue
+		}
+		results = append(results, search.SearchResult{
+			Path:      n.Path,
+			Match:     "#" + norm,
+			Snippet:   "tag match",
+			MatchType: "tag",
+		})
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+func (b *APIBackend) OutgoingLinks(ctx context.Context, path string) ([]note.Link, error) {
+	n, err := b.readNote(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := b.readAll(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	links := noteResolver(notes).ResolveLinks(n.Path, note.ParseLinks(n.Body))
+	targets := map[string]note.Note{n.Path: n}
+	for i, link := range links {
+		if link.Path == "" || link.Subpath() == "" {
+			continue
+		}
+		target, ok := targets[link.Path]
+		if !ok {
+			if target, err = b.readNote(ctx, link.Path); err != nil {
+				return nil, err
+			}
+			targets[link.Path] = target
+		}
+		links[i].BrokenAnchor = !link.AnchorExists(note.Headings(target.Body), note.BlockIDs(target.Body))
+	}
+	return links, nil
+}
+
+func (b *APIBackend) Backlinks(ctx context.Context, path string, _ bool) ([]index.Backlink, error) {
+	_, rel, err := vault.ResolveNoteAbs(b.vaultRoot, path)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := b.readAll(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	resolver := noteResolver(notes)
+	resolver.Add(rel)
+	out := []index.Backlink{}
+	for _, n := range notes {
+		if n.Path == rel {
+			continue
+		}
+		links := []note.Link{}
+		for _, link := range resolver.ResolveLinks(n.Path, note.ParseLinks(n.Body)) {
+			if link.Path == rel {
+				links = append(links, link)
+			}
+		}
+		if len(links) > 0 {
+			out = append(out, index.Backlink{Source: n.Path, Links: links})
+		}
+	}
+	return out, nil
+}
+
+// noteResolver resolves links against notes and their aliases.
+func noteResolver(notes []note.Note) *index.Resolver {
+	r := index.NewResolver(nil)
+	for _, n := range notes {
+		r.Add(n.Path)
+	}
+	for _, n := range notes {
+		for _, alias := range n.Frontmatter.Aliases {
+			r.AddAlias(alias, n.Path)
+		}
+	}
+	return r
+}
+
+func (b *APIBackend) PropGet(ctx context.Context, path, key string) (any, error) {
+	values, err := b.PropList(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := values[key]
+	if !ok {
+		return nil, errs.New(errs.ExitNotFound, "property not found")
+	}
+	return value, nil
+}
+
+func (b *APIBackend) PropSet(ctx context.Context, path, key string, value any) (note.Note, error) {
+	n, err := b.readNote(ctx, path)
+	if err != nil {
+		return note.Note{}, err
+	}
+	values := frontmatter.FrontmatterToMap(n.Frontmatter)
+	values[key] = value
+	n.Frontmatter = frontmatter.MapToFrontmatter(values)
+	return b.writeNote(ctx, n, false)
+}
+
+func (b *APIBackend) PropDelete(ctx context.Context, path, key string) (note.Note, error) {
+	n, err := b.readNote(ctx, path)
+	if err != nil {
+		return note.Note{}, err
+	}
+	values := frontmatter.FrontmatterToMap(n.Frontmatter)
+	if _, ok := values[key]; !ok {
+		return note.Note{}, errs.New(errs.ExitNotFound, "property not found")
+	}
+	delete(values, key)
+	n.Frontmatter = frontmatter.MapToFrontmatter(values)
+	return b.writeNote(ctx, n, false)
+}
+
+func (b *APIBackend) PropList(ctx context.Context, path string) (map[string]any, error) {
+	n, err := b.readNote(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return frontmatter.FrontmatterToMap(n.Frontmatter), nil
+}
+
+func (b *APIBackend) OpenInObsidian(ctx context.Context, path string, launch bool) (OpenResult, error) {
+	n, err := b.readNote(ctx, path)
+	if err != nil {
+		return OpenResult{}, err
+	}
+	vaultName := filepath.Base(b.vaultRoot)
+	uri := fmt.Sprintf("obsidian://open?vault=%s&file=%s", urlQueryEscape(vaultName), urlQueryEscape(strings.TrimSuffix(n.Path, ".md")))
+	result := OpenResult{URI: uri}
+	if !launch {
+		return result, nil
+	}
+	if _, err := b.do(ctx, http.MethodPost, "/open/"+escapePath(n.Path), nil, "", nil); err != nil {
+		return OpenResult{}, err
+	}
+	result.Launched = true
+	return result, nil
+}
+
+func (b *APIBackend) SyncStatus(ctx context.Context) (SyncStatus, error) {
+	status := SyncStatus{EffectiveMode: b.mode}
+	if _, err := b.readRaw(ctx, ".obsidian/sync.json"); err == nil {
+		status.Configured = true
+		status.ConfigPath = ".obsidian/sync.json"
+	}
+	ids, _ := b.readPluginIDs(ctx, ".obsidian/core-plugins.json")
+	status.Enabled = contains(ids, "sync")
+	return status, nil
+}
+
+func (b *APIBackend) ListTasks(ctx context.Context, opts tasks.ListOptions) ([]tasks.Task, error) {
+	paths := []string{}
+	if strings.TrimSpace(opts.Path) != "" {
+		_, rel, err := vault.ResolveNoteAbs(b.vaultRoot, opts.Path)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, rel)
+	} else {
+		listed, err := b.listMarkdown(ctx, "", true)
+		if err != nil {
+			return nil, err
+		}
+		paths = listed
+	}
+
+	out := []tasks.Task{}
+	for _, p := range paths {
+		raw, err := b.readRaw(ctx, p)
+		if err != nil {
+			if errs.ExitCode(err) == errs.ExitNotFound && strings.TrimSpace(opts.Path) != "" {
+				return []tasks.Task{}, nil
+			}
+			return nil, err
+		}
+		for _, t := range tasks.FromContent(p, raw) {
+			if !tasks.Matches(t, opts) {
+				continue
+			}
+			out = append(out, t)
+		}
+	}
+	// The API lists files in its own order, so sort before applying the
+	// limit to return the same tasks as the native backend.
+	tasks.Sort(out)
+	if opts.Limit > 0 && len(out) > opts.Limit {
+		out = out[:opts.Limit]
+	}
+	return out, nil
+}
+
+func (b *APIBackend) GetTask(ctx context.Context, ref tasks.Ref) (tasks.Task, error) {
+	items, err := b.ListTasks(ctx, tasks.ListOptions{Path: ref.Path})
+	if err != nil {
+		return tasks.Task{}, err
+	}
+	for _, item := range items {
+		if item.Line == ref.Line {
+			return item, nil
+		}
+	}
+	return tasks.Task{}, errs.New(errs.ExitNotFound, "task not found")
+}
+
+func (b *APIBackend) UpdateTask(ctx context.Context, ref tasks.Ref, input tasks.UpdateInput) (tasks.Task, error) {
+	_, rel, err := vault.ResolveNoteAbs(b.vaultRoot, ref.Path)
+	if err != nil {
+		return tasks.Task{}, err
+	}
+	raw, err := b.readRaw(ctx, rel)
+	if err != nil {
+		if errs.ExitCode(err) == errs.ExitNotFound {
+			return tasks.Task{}, errs.New(errs.ExitNotFound, "note not found")
+		}
+		return tasks.Task{}, err
+	}
+	updated, task, err := tasks.ApplyUpdate(rel, raw, ref.Line, input)
+	if err != nil {
+		return tasks.Task{}, err
+	}
+	if err := b.writeRaw(ctx, rel, updated); err != nil {
+		return tasks.Task{}, err
+	}
+	return task, nil
+}
+
+func (b *APIBackend) ListPlugins(ctx context.Context, filter string, enabledOnly bool) ([]PluginInfo, error) {
+	filter = strings.TrimSpace(strings.ToLower(filter))
+	if filter != "" && filter != "core" && filter != "community" {
+		return nil, errs.New(errs.ExitValidation, "filter must be core or community")
+	}
+
+	items := []PluginInfo{}
+	if filter == "" || filter == "core" {
+		coreEnabled, _ := b.readPluginIDs(ctx, ".obsidian/core-plugins.json")
+		for _, id := range coreEnabled {
+			items = append(items, PluginInfo{ID: id, Name: id, Type: "core", Enabled: true})
+		}
+	}
+	if filter == "" || filter == "community" {
+		communityEnabled, _ := b.readPluginIDs(ctx, ".obsidian/community-plugins.json")
+		listing, err := b.listDir(ctx, ".obsidian/plugins")
+		if err == nil {
+			for _, entry := range listing {
+				if !strings.HasSuffix(entry, "/") {
+					continue
+				}
+				id := path.Base(strings.TrimSuffix(entry, "/"))
+				info := PluginInfo{ID: id, Type: "community", Enabled: contains(communityEnabled, id)}
+				if payload, readErr := b.readRaw(ctx, ".obsidian/plugins/"+id+"/manifest.json"); readErr == nil {
+					var manifest struct {
+						Name    string `json:"name"`
+						Version string `json:"version"`
+					}
+					if jsonErr := json.Unmarshal([]byte(payload), &manifest); jsonErr == nil {
+						info.Name = manifest.Name
+						info.Version = manifest.Version
+					}
+				}
+				if info.Name == "" {
+					info.Name = id
+				}
+				items = append(items, info)
+			}
+		}
+	}
+
+	if enabledOnly {
+		filtered := make([]PluginInfo, 0, len(items))
+		for _, item := range items {
+			if item.Enabled {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Type == items[j].Type {
+			return items[i].ID < items[j].ID
+		}
+		return items[i].Type < items[j].Type
+	})
+	return items, nil
+}
+
+func (b *APIBackend) ListCommandIDs(ctx context.Context, filter string) ([]string, error) {
+	payload, err := b.do(ctx, http.MethodGet, "/commands/", nil, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	var listing apiCommandList
+	if err := json.Unmarshal(payload, &listing); err != nil {
+		return nil, errs.Wrap(errs.ExitGeneric, "invalid command list from API", err)
+	}
+	prefix := strings.TrimSpace(filter)
+	out := make([]string, 0, len(listing.Commands))
+	for _, command := range listing.Commands {
+		if prefix != "" && !strings.HasPrefix(command.ID, prefix) {
+			continue
+		}
+		out = append(out, command.ID)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (b *APIBackend) ExecuteCommand(ctx context.Context, id string) error {
+	clean := strings.TrimSpace(id)
+	if clean == "" {
+		return errs.New(errs.ExitValidation, "command id is required")
+	}
+	_, err := b.do(ctx, http.MethodPost, "/commands/"+url.PathEscape(clean)+"/", nil, "", nil)
+	if err != nil && errs.ExitCode(err) == errs.ExitNotFound {
+		return errs.New(errs.ExitNotFound, "command not found")
+	}
+	return err
+}
+
+// Ping checks that the API is reachable and the configured token is accepted.
+func (b *APIBackend) Ping(ctx context.Context) error {
+	payload, err := b.do(ctx, http.MethodGet, "/", nil, "", nil)
+	if err != nil {
+		return err
+	}
+	var status struct {
+		Authenticated *bool `json:"authenticated"`
+	}
+	if err := json.Unmarshal(payload, &status); err == nil && status.Authenticated != nil && !*status.Authenticated {
+		return errs.NewDetailed(errs.ExitConfig, "api_unauthorized", "Set api_token in .obsidian-cli.yaml to the Local REST API key.", "Local REST API rejected the configured token")
+	}
+	return nil
+}
+
+func (b *APIBackend) searchText(ctx context.Context, q search.Query) ([]search.SearchResult, error) {
+	contextChars := q.Context
+	if contextChars <= 0 {
+		contextChars = 80
+	}
+	query := url.Values{}
+	query.Set("query", q.Text)
+	query.Set("contextLength", strconv.Itoa(contextChars))
+	payload, err := b.do(ctx, http.MethodPost, "/search/simple/", query, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	var hits []apiSearchHit
+	if err := json.Unmarshal(payload, &hits); err != nil {
+		return nil, errs.Wrap(errs.ExitGeneric, "invalid search response from API", err)
+	}
+
+	results := []search.SearchResult{}
+	for _, hit := range hits {
+		if !strings.HasSuffix(strings.ToLower(hit.Filename), ".md") {
+			continue
+		}
+		for _, m := range hit.Matches {
+			if q.CaseSensitive && !strings.Contains(m.Context, q.Text) {
+				continue
+			}
+			results = append(results, search.SearchResult{
+				Path:      hit.Filename,
+				Match:     q.Text,
+				Snippet:   m.Context,
+				MatchType: "text",
+				Score:     hit.Score,
+			})
+			if q.Ranked {
+				// One result per note when ranking, mirroring the BM25 engine.
+				break
+			}
+		}
+	}
+	results = filterByPath(results, q.Path)
+	if q.Ranked {
+		sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	}
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results, nil
+}
+
+func (b *APIBackend) templatesDir() string {
+	dir := strings.Trim(strings.ReplaceAll(strings.TrimSpace(b.cfg.TemplatesDir), "\\", "/"), "/")
+	if dir == "" {
+		return ".obsidian/templates"
+	}
+	return dir
+}
+
+func (b *APIBackend) readPluginIDs(ctx context.Context, relPath string) ([]string, error) {
+	payload, err := b.readRaw(ctx, relPath)
+	if err != nil {
+		return []string{}, err
+	}
+	var ids []string
+	if err := json.Unmarshal([]byte(payload), &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (b *APIBackend) ensureNote(ctx context.Context, relPath string) (note.Note, error) {
+	n, err := b.readNote(ctx, relPath)
+	if err == nil {
+		return n, nil
+	}
+	if errs.ExitCode(err) != errs.ExitNotFound {
+		return note.Note{}, err
+	}
+	return b.writeNote(ctx, note.Note{
+		Path:        relPath,
+		Frontmatter: note.Frontmatter{Extra: map[string]any{}},
+	}, true)
+}
+
+func (b *APIBackend) readAll(ctx context.Context, dir string) ([]note.Note, error) {
+	paths, err := b.listMarkdown(ctx, dir, true)
+	if err != nil {
+		return nil, err
+	}
+	notes := make([]note.Note, 0, len(paths))
+	for _, p := range paths {
+		n, err := b.readNote(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, nil
+}
+
+func (b *APIBackend) readNote(ctx context.Context, relPath string) (note.Note, error) {
+	_, rel, err := vault.ResolveNoteAbs(b.vaultRoot, relPath)
+	if err != nil {
+		return note.Note{}, err
+	}
+	raw, err := b.readRaw(ctx, rel)
+	if err != nil {
+		if errs.ExitCode(err) == errs.ExitNotFound {
+			return note.Note{}, errs.New(errs.ExitNotFound, "note not found")
+		}
+		return note.Note{}, err
+	}
+	return note.Parse(rel, raw)
+}
+
+func (b *APIBackend) writeNote(ctx context.Context, n note.Note, creating bool) (note.Note, error) {
+	rendered, err := note.Render(n, creating, now())
+	if err != nil {
+		return note.Note{}, err
+	}
+	if err := b.writeRaw(ctx, rendered.Path, rendered.Raw); err != nil {
+		return note.Note{}, err
+	}
+	return rendered, nil
+}
+
+func (b *APIBackend) exists(ctx context.Context, relPath string) (bool, error) {
+	_, err := b.readRaw(ctx, relPath)
+	if err == nil {
+		return true, nil
+	}
+	if errs.ExitCode(err) == errs.ExitNotFound {
+		return false, nil
+	}
+	return false, err
+}
+
+func (b *APIBackend) readRaw(ctx context.Context, relPath string) (string, error) {
+	payload, err := b.do(ctx, http.MethodGet, vaultFilePath(relPath), nil, "", nil)
+	if err != nil {
+		return "", err
+	}
+	return string(payload), nil
+}
+
+func (b *APIBackend) writeRaw(ctx context.Context, relPath, content string) error {
+	if err := b.history.Capture(filepath.Join(b.vaultRoot, filepath.FromSlash(relPath))); err != nil {
+		return err
+	}
+	_, err := b.do(ctx, http.MethodPut, vaultFilePath(relPath), nil, "text/markdown", strings.NewReader(content))
+	return err
+}
+
+// listDir returns the raw API listing for a vault directory; folders end in "/".
+func (b *APIBackend) listDir(ctx context.Context, dir string) ([]string, error) {
+	endpoint := "/vault/"
+	if clean := strings.Trim(dir, "/"); clean != "" {
+		endpoint += escapePath(clean) + "/"
+	}
+	payload, err := b.do(ctx, http.MethodGet, endpoint, nil, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	var listing apiFileList
+	if err := json.Unmarshal(payload, &listing); err != nil {
+		return nil, errs.Wrap(errs.ExitGeneric, "invalid directory listing from API", err)
+	}
+	return listing.Files, nil
+}
+
+// listMarkdown walks dir through the API and returns vault-relative markdown
+// paths, skipping hidden folders the same way index.ListMarkdownFiles does.
+func (b *APIBackend) listMarkdown(ctx context.Context, dir string, recursive bool) ([]string, error) {
+	root := strings.Trim(strings.ReplaceAll(strings.TrimSpace(dir), "\\", "/"), "/")
+	out := []string{}
+	queue := []string{root}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		entries, err := b.listDir(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := strings.TrimSuffix(entry, "/")
+			// The API lists entries relative to the requested folder.
+			rel := name
+			if current != "" && !strings.HasPrefix(name, current+"/") {
+				rel = current + "/" + name
+			}
+			if strings.HasSuffix(entry, "/") {
+				if recursive && !strings.HasPrefix(path.Base(rel), ".") {
+					queue = append(queue, rel)
+				}
+				continue
+			}
+			if strings.HasSuffix(strings.ToLower(rel), ".md") {
+				out = append(out, rel)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (b *APIBackend) do(ctx context.Context, method, endpoint string, query url.Values, contentType string, body io.Reader) ([]byte, error) {
+	target := b.baseURL + endpoint
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, errs.Wrap(errs.ExitConfig, "invalid api_base_url", err)
+	}
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		if !requestNotSent(err) {
+			return nil, errs.WrapDetailed(
+				errs.ExitGeneric,
+				"api_no_response",
+				"The request may have been applied; check the note before retrying, or use --mode native.",
+				"Local REST API did not answer",
+				err,
+			)
+		}
+		return nil, errs.WrapDetailed(
+			errs.ExitGeneric,
+			"api_unreachable",
+			"Start Obsidian with the Local REST API plugin enabled, or use --mode native.",
+			"Local REST API request failed",
+			err,
+		)
+	}
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errs.Wrap(errs.ExitGeneric, "failed to read Local REST API response", err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return payload, nil
+	}
+	return nil, apiStatusError(resp.StatusCode, payload)
+}
+
+func apiStatusError(status int, payload []byte) error {
+	message := strings.TrimSpace(string(payload))
+	var body apiErrorBody
+	if err := json.Unmarshal(payload, &body); err == nil && body.Message != "" {
+		message = body.Message
+	}
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	message = fmt.Sprintf("Local REST API returned %d: %s", status, message)
+	switch {
+	case status == http.StatusNotFound:
+		return errs.New(errs.ExitNotFound, message)
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return errs.NewDetailed(errs.ExitConfig, "api_unauthorized", "Set api_token in .obsidian-cli.yaml to the Local REST API key.", message)
+	case status >= 400 && status < 500:
+		return errs.New(errs.ExitValidation, message)
+	default:
+		return errs.NewDetailed(errs.ExitGeneric, "api_error", "Check the Obsidian developer console for Local REST API errors.", message)
+	}
+}
+
+// IsAPIFailure reports whether err came from the Local REST API transport
+// itself (unreachable, rejected token, server error) rather than from the
+// requested operation, so callers can retry against the native backend.
+func IsAPIFailure(err error) bool {
+	var appErr *errs.AppError
+	if !errors.As(err, &appErr) {
+		return false
+	}
+	switch appErr.Reason {
+	case "api_unreachable", "api_unauthorized", "api_error", "api_no_response", "api_partial_apply":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsAPINotApplied reports whether err is an API failure that happened
+// before Obsidian could act on the request (connection refused, rejected
+// token), so even a write can safely be retried against the native backend.
+func IsAPINotApplied(err error) bool {
+	var appErr *errs.AppError
+	if !errors.As(err, &appErr) {
+		return false
+	}
+	return appErr.Reason == "api_unreachable" || appErr.Reason == "api_unauthorized"
+}
+
+// partialApply reports a write made of several requests that failed after
+// some were already sent. done lists what was applied; such a write is
+// never retried against the native backend.
+func partialApply(done []string, err error) error {
+	return errs.WrapDetailed(
+		errs.ExitGeneric,
+		"api_partial_apply",
+		"Check the listed notes and finish or undo the change by hand before retrying.",
+		"Local REST API write was only partly applied (done: "+strings.Join(done, "; ")+")",
+		err,
+	)
+}
+
+// requestNotSent reports transport errors raised while connecting, before
+// any part of the request reached the server.
+func requestNotSent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+func vaultFilePath(relPath string) string {
+	return "/vault/" + escapePath(relPath)
+}
+
+func escapePath(relPath string) string {
+	segments := strings.Split(strings.Trim(strings.ReplaceAll(relPath, "\\", "/"), "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func isLoopbackURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	host := parsed.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,278 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/nightisyang/obsidian-cli/internal/search"
+	"github.com/nightisyang/obsidian-cli/internal/tasks"
+	"github.com/nightisyang/obsidian-cli/internal/vault"
+)
+
+// fakeRESTAPI is an in-memory stand-in for the Local REST API plugin.
+type fakeRESTAPI struct {
+	mu       sync.Mutex
+	token    string
+	files    map[string]string
+	executed []string
+}
+
+func newFakeRESTAPI(t *testing.T, token string, files map[string]string) (*fakeRESTAPI, *httptest.Server) {
+	t.Helper()
+	fake := &fakeRESTAPI{token: token, files: files}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeRESTAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	authorized := r.Header.Get("Authorization") == "Bearer "+f.token
+	if r.URL.Path == "/" {
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "authenticated": authorized})
+		return
+	}
+	if !authorized {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]any{"errorCode": 40101, "message": "Authorization required"})
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/vault/"):
+		f.serveVault(w, r, strings.TrimPrefix(r.URL.Path, "/vault/"))
+	case r.URL.Path == "/commands/" && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(map[string]any{"commands": []map[string]string{
+			{"id": "editor:toggle-bold", "name": "Toggle bold"},
+			{"id": "app:reload", "name": "Reload"},
+		}})
+	case strings.HasPrefix(r.URL.Path, "/commands/") && r.Method == http.MethodPost:
+		id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/commands/"), "/")
+		if id != "app:reload" && id != "editor:toggle-bold" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.executed = append(f.executed, id)
+		w.WriteHeader(http.StatusNoContent)
+	case r.URL.Path == "/search/simple/" && r.Method == http.MethodPost:
+		query := strings.ToLower(r.URL.Query().Get("query"))
+		hits := []map[string]any{}
+		for name, content := range f.files {
+			idx := strings.Index(strings.ToLower(content), query)
+			if idx < 0 {
+				continue
+			}
+			hits = append(hits, map[string]any{
+				"filename": name,
+				"score":    1,
+				"matches": []map[string]any{{
+					"match":   map[string]int{"start": idx, "end": idx + len(query)},
+					"context": content,
+				}},
+			})
+		}
+		_ = json.NewEncoder(w).Encode(hits)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeRESTAPI) serveVault(w http.ResponseWriter, r *http.Request, rel string) {
+	rel, _ = url.PathUnescape(rel)
+	if rel == "" || strings.HasSuffix(rel, "/") {
+		prefix := rel
+		seen := map[string]struct{}{}
+		for name := range f.files {
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			rest := strings.TrimPrefix(name, prefix)
+			if slash := strings.Index(rest, "/"); slash >= 0 {
+				rest = rest[:slash+1]
+			}
+			seen[rest] = struct{}{}
+		}
+		if len(seen) == 0 && prefix != "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		entries := make([]string, 0, len(seen))
+		for entry := range seen {
+			entries = append(entries, entry)
+		}
+		sort.Strings(entries)
+		_ = json.NewEncoder(w).Encode(map[string]any{"files": entries})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		content, ok := f.files[rel]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]any{"errorCode": 40400, "message": "File not found"})
+			return
+		}
+		_, _ = io.WriteString(w, content)
+	case http.MethodPut:
+		payload, _ := io.ReadAll(r.Body)
+		f.files[rel] = string(payload)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		if _, ok := f.files[rel]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.files, rel)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestAPIBackend(t *testing.T, serverURL, token string) *APIBackend {
+	t.Helper()
+	cfg := vault.DefaultConfig()
+	cfg.APIBaseURL = serverURL
+	cfg.APIToken = token
+	cfg.APITimeout = 2 * time.Second
+	return NewAPIBackend(t.TempDir(), nil, cfg, "api")
+}
+
+func TestAPIBackendNoteLifecycle(t *testing.T) {
+	fake, server := newFakeRESTAPI(t, "secret", map[string]string{
+		"projects/alpha.md": "---\nstatus: active\n---\n\n# Plan\nSee [[beta]]\n- [ ] ship it\n",
+		"beta.md":           "beta body #project",
+	})
+	b := newTestAPIBackend(t, server.URL, "secret")
+	ctx := context.Background()
+
+	created, err := b.CreateNote(ctx, note.CreateInput{Title: "Gamma Note", Content: "hello"})
+	if err != nil {
+		t.Fatalf("CreateNote error: %v", err)
+	}
+	if created.Path != "gamma-note.md" || !strings.Contains(fake.files["gamma-note.md"], "hello") {
+		t.Fatalf("unexpected created note: %+v files=%v", created, fake.files)
+	}
+
+	appended, err := b.AppendNote(ctx, "gamma-note", "more")
+	if err != nil {
+		t.Fatalf("AppendNote error: %v", err)
+	}
+	if appended.Body != "hello\nmore" {
+		t.Fatalf("unexpected appended body: %q", appended.Body)
+	}
+
+	if _, err := b.PropSet(ctx, "projects/alpha.md", "status", "done"); err != nil {
+		t.Fatalf("PropSet error: %v", err)
+	}
+	value, err := b.PropGet(ctx, "projects/alpha.md", "status")
+	if err != nil || value != "done" {
+		t.Fatalf("PropGet = %v, %v", value, err)
+	}
+
+	notes, err := b.ListNotes(ctx, "", note.ListOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("ListNotes error: %v", err)
+	}
+	if len(notes) != 3 {
+		t.Fatalf("expected 3 notes, got %d", len(notes))
+	}
+
+	backlinks, err := b.Backlinks(ctx, "beta.md", false)
+	if err != nil {
+		t.Fatalf("Backlinks error: %v", err)
+	}
+	if len(backlinks) != 1 || backlinks[0].Source != "projects/alpha.md" || len(backlinks[0].Links) == 0 {
+		t.Fatalf("unexpected backlinks: %v", backlinks)
+	}
+
+	tagged, err := b.Search(ctx, search.Query{Type: search.QueryTag, Tag: "project"})
+	if err != nil || len(tagged) != 1 || tagged[0].Path != "beta.md" {
+		t.Fatalf("unexpected tag search: %v, %v", tagged, err)
+	}
+
+	moved, err := b.MoveNote(ctx, "beta.md", "archive/beta.md", note.MoveOptions{UpdateLinks: true})
+	if err != nil {
+		t.Fatalf("MoveNote error: %v", err)
+	}
+	if moved.Path != "archive/beta.md" {
+		t.Fatalf("unexpected moved path: %s", moved.Path)
+	}
+	if !strings.Contains(fake.files["projects/alpha.md"], "[[archive/beta]]") {
+		t.Fatalf("expected link rewrite, got %q", fake.files["projects/alpha.md"])
+	}
+
+	if err := b.DeleteNote(ctx, "gamma-note.md"); err != nil {
+		t.Fatalf("DeleteNote error: %v", err)
+	}
+	if _, err := b.GetNote(ctx, "gamma-note.md"); errs.ExitCode(err) != errs.ExitNotFound {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+}
+
+func TestAPIBackendExecuteCommand(t *testing.T) {
+	fake, server := newFakeRESTAPI(t, "secret", map[string]string{})
+	b := newTestAPIBackend(t, server.URL, "secret")
+	ctx := context.Background()
+
+	ids, err := b.ListCommandIDs(ctx, "app:")
+	if err != nil {
+		t.Fatalf("ListCommandIDs error: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "app:reload" {
+		t.Fatalf("unexpected command ids: %v", ids)
+	}
+	if err := b.ExecuteCommand(ctx, "app:reload"); err != nil {
+		t.Fatalf("ExecuteCommand error: %v", err)
+	}
+	if len(fake.executed) != 1 || fake.executed[0] != "app:reload" {
+		t.Fatalf("command was not executed: %v", fake.executed)
+	}
+	if err := b.ExecuteCommand(ctx, "missing:command"); errs.ExitCode(err) != errs.ExitNotFound {
+		t.Fatalf("expected not found for unknown command, got %v", err)
+	}
+}
+
+func TestAPIBackendRejectsBadToken(t *testing.T) {
+	_, server := newFakeRESTAPI(t, "secret", map[string]string{"a.md": "alpha"})
+	b := newTestAPIBackend(t, server.URL, "wrong")
+
+	_, err := b.GetNote(context.Background(), "a.md")
+	if errs.ExitCode(err) != errs.ExitConfig {
+		t.Fatalf("expected config error for bad token, got %v", err)
+	}
+	if err := b.Ping(context.Background()); errs.ExitCode(err) != errs.ExitConfig {
+		t.Fatalf("expected ping to report unauthorized, got %v", err)
+	}
+}
+
+func TestAPIBackendListTasksLimitMatchesNativeOrder(t *testing.T) {
+	_, server := newFakeRESTAPI(t, "secret", map[string]string{
+		"b.md":        "- [ ] from b\n",
+		"zeta/one.md": "- [ ] from zeta\n",
+		"a/deep.md":   "- [ ] second\n- [ ] first\n",
+	})
+	b := newTestAPIBackend(t, server.URL, "secret")
+
+	items, err := b.ListTasks(context.Background(), tasks.ListOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListTasks error: %v", err)
+	}
+	if len(items) != 2 || items[0].Path != "a/deep.md" || items[0].Line != 1 || items[1].Line != 2 {
+		t.Fatalf("unexpected limited tasks: %+v", items)
+	}
+}
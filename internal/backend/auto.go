@@ -0,0 +1,253 @@
+package backend
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/index"
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/nightisyang/obsidian-cli/internal/search"
+	"github.com/nightisyang/obsidian-cli/internal/tasks"
+	"github.com/nightisyang/obsidian-cli/internal/templates"
+	"github.com/nightisyang/obsidian-cli/internal/vault"
+)
+
+const (
+	ServedByAPI    = "api"
+	ServedByNative = "native"
+)
+
+// AutoBackend routes calls to the Local REST API while the app is reachable.
+// Read operations retry on the native backend when the API call fails.
+// Writes retry only when the API never received the request, since a write
+// that timed out may already have been applied; a write of several requests
+// that fails part way reports what was applied instead. App-only
+// operations (command listing and execution, opening a note in Obsidian)
+// never fall back.
+type AutoBackend struct {
+	api    *APIBackend
+	native *NativeBackend
+
+	mu       sync.Mutex
+	apiDown  bool
+	servedBy string
+}
+
+func NewAutoBackend(api *APIBackend, native *NativeBackend) *AutoBackend {
+	return &AutoBackend{api: api, native: native}
+}
+
+// ServedBy reports which backend handled the most recent call.
+func ServedBy(b Backend) string {
+	switch v := b.(type) {
+	case *AutoBackend:
+		return v.LastServedBy()
+	case *APIBackend:
+		return ServedByAPI
+	default:
+		return ServedByNative
+	}
+}
+
+func (b *AutoBackend) LastServedBy() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.servedBy
+}
+
+func (b *AutoBackend) record(servedBy string, apiFailed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.servedBy = servedBy
+	if apiFailed {
+		b.apiDown = true
+	}
+}
+
+func (b *AutoBackend) apiAvailable() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.apiDown
+}
+
+func autoFileOp[T any](b *AutoBackend, run func(Backend) (T, error)) (T, error) {
+	if b.apiAvailable() {
+		out, err := run(b.api)
+		if err == nil || !IsAPIFailure(err) {
+			b.record(ServedByAPI, false)
+			return out, err
+		}
+		b.record(ServedByNative, true)
+	} else {
+		b.record(ServedByNative, false)
+	}
+	return run(b.native)
+}
+
+// autoWriteOp is autoFileOp for operations that change the vault.
+func autoWriteOp[T any](b *AutoBackend, run func(Backend) (T, error)) (T, error) {
+	if b.apiAvailable() {
+		out, err := run(b.api)
+		if err == nil || !IsAPINotApplied(err) {
+			b.record(ServedByAPI, IsAPIFailure(err))
+			return out, err
+		}
+		b.record(ServedByNative, true)
+	} else {
+		b.record(ServedByNative, false)
+	}
+	return run(b.native)
+}
+
+func autoAppOp[T any](b *AutoBackend, run func(Backend) (T, error)) (T, error) {
+	b.record(ServedByAPI, false)
+	return run(b.api)
+}
+
+func (b *AutoBackend) VaultStatus(ctx context.Context) (vault.Status, error) {
+	return autoFileOp(b, func(be Backend) (vault.Status, error) { return be.VaultStatus(ctx) })
+}
+
+func (b *AutoBackend) CreateNote(ctx context.Context, in note.CreateInput) (note.Note, error) {
+	return autoWriteOp(b, func(be Backend) (note.Note, error) { return be.CreateNote(ctx, in) })
+}
+
+func (b *AutoBackend) GetNote(ctx context.Context, path string) (note.Note, error) {
+	return autoFileOp(b, func(be Backend) (note.Note, error) { return be.GetNote(ctx, path) })
+}
+
+func (b *AutoBackend) GetHeading(ctx context.Context, path, heading string) (note.HeadingSection, error) {
+	return autoFileOp(b, func(be Backend) (note.HeadingSection, error) { return be.GetHeading(ctx, path, heading) })
+}
+
+func (b *AutoBackend) GetBlock(ctx context.Context, path, blockID string) (note.Block, error) {
+	return autoFileOp(b, func(be Backend) (note.Block, error) { return be.GetBlock(ctx, path, blockID) })
+}
+
+func (b *AutoBackend) SetBlock(ctx context.Context, path, blockID, content string) (note.Block, error) {
+	return autoWriteOp(b, func(be Backend) (note.Block, error) { return be.SetBlock(ctx, path, blockID, content) })
+}
+
+func (b *AutoBackend) AppendNote(ctx context.Context, path, content string) (note.Note, error) {
+	return autoWriteOp(b, func(be Backend) (note.Note, error) { return be.AppendNote(ctx, path, content) })
+}
+
+func (b *AutoBackend) PrependNote(ctx context.Context, path, content string) (note.Note, error) {
+	return autoWriteOp(b, func(be Backend) (note.Note, error) { return be.PrependNote(ctx, path, content) })
+}
+
+func (b *AutoBackend) DeleteNote(ctx context.Context, path string) error {
+	_, err := autoWriteOp(b, func(be Backend) (struct{}, error) { return struct{}{}, be.DeleteNote(ctx, path) })
+	return err
+}
+
+func (b *AutoBackend) ListNotes(ctx context.Context, dir string, opts note.ListOptions) ([]note.Note, error) {
+	return autoFileOp(b, func(be Backend) ([]note.Note, error) { return be.ListNotes(ctx, dir, opts) })
+}
+
+func (b *AutoBackend) MoveNote(ctx context.Context, src, dst string, opts note.MoveOptions) (note.Note, error) {
+	return autoWriteOp(b, func(be Backend) (note.Note, error) { return be.MoveNote(ctx, src, dst, opts) })
+}
+
+func (b *AutoBackend) DailyPath(ctx context.Context, at time.Time) (string, error) {
+	return autoFileOp(b, func(be Backend) (string, error) { return be.DailyPath(ctx, at) })
+}
+
+func (b *AutoBackend) DailyRead(ctx context.Context, at time.Time, create bool) (note.Note, error) {
+	return autoWriteOp(b, func(be Backend) (note.Note, error) { return be.DailyRead(ctx, at, create) })
+}
+
+func (b *AutoBackend) DailyAppend(ctx context.Context, at time.Time, content string, inline bool) (note.Note, error) {
+	return autoWriteOp(b, func(be Backend) (note.Note, error) { return be.DailyAppend(ctx, at, content, inline) })
+}
+
+func (b *AutoBackend) DailyPrepend(ctx context.Context, at time.Time, content string, inline bool) (note.Note, error) {
+	return autoWriteOp(b, func(be Backend) (note.Note, error) { return be.DailyPrepend(ctx, at, content, inline) })
+}
+
+func (b *AutoBackend) ListTemplates(ctx context.Context) ([]templates.TemplateInfo, error) {
+	return autoFileOp(b, func(be Backend) ([]templates.TemplateInfo, error) { return be.ListTemplates(ctx) })
+}
+
+func (b *AutoBackend) ReadTemplate(ctx context.Context, name, title string, resolve bool) (templates.Template, error) {
+	return autoFileOp(b, func(be Backend) (templates.Template, error) { return be.ReadTemplate(ctx, name, title, resolve) })
+}
+
+func (b *AutoBackend) InsertTemplate(ctx context.Context, path, name, title string, resolve bool) (note.Note, error) {
+	return autoWriteOp(b, func(be Backend) (note.Note, error) { return be.InsertTemplate(ctx, path, name, title, resolve) })
+}
+
+func (b *AutoBackend) Search(ctx context.Context, q search.Query) ([]search.SearchResult, error) {
+	return autoFileOp(b, func(be Backend) ([]search.SearchResult, error) { return be.Search(ctx, q) })
+}
+
+func (b *AutoBackend) ListTags(ctx context.Context, opts index.TagListOptions) ([]index.TagCount, error) {
+	return autoFileOp(b, func(be Backend) ([]index.TagCount, error) { return be.ListTags(ctx, opts) })
+}
+
+func (b *AutoBackend) SearchTag(ctx context.Context, tag string, limit int) ([]search.SearchResult, error) {
+	return autoFileOp(b, func(be Backend) ([]search.SearchResult, error) { return be.SearchTag(ctx, tag, limit) })
+}
+
+func (b *AutoBackend) OutgoingLinks(ctx context.Context, path string) ([]note.Link, error) {
+	return autoFileOp(b, func(be Backend) ([]note.Link, error) { return be.OutgoingLinks(ctx, path) })
+}
+
+func (b *AutoBackend) Backlinks(ctx context.Context, path string, rebuild bool) ([]index.Backlink, error) {
+	return autoFileOp(b, func(be Backend) ([]index.Backlink, error) { return be.Backlinks(ctx, path, rebuild) })
+}
+
+func (b *AutoBackend) PropGet(ctx context.Context, path, key string) (any, error) {
+	return autoFileOp(b, func(be Backend) (any, error) { return be.PropGet(ctx, path, key) })
+}
+
+func (b *AutoBackend) PropSet(ctx context.Context, path, key string, value any) (note.Note, error) {
+	return autoWriteOp(b, func(be Backend) (note.Note, error) { return be.PropSet(ctx, path, key, value) })
+}
+
+func (b *AutoBackend) PropDelete(ctx context.Context, path, key string) (note.Note, error) {
+	return autoWriteOp(b, func(be Backend) (note.Note, error) { return be.PropDelete(ctx, path, key) })
+}
+
+func (b *AutoBackend) PropList(ctx context.Context, path string) (map[string]any, error) {
+	return autoFileOp(b, func(be Backend) (map[string]any, error) { return be.PropList(ctx, path) })
+}
+
+// OpenInObsidian only builds the URI when launch is false, which either
+// backend can do. Launching asks the running app to open the note.
+func (b *AutoBackend) OpenInObsidian(ctx context.Context, path string, launch bool) (OpenResult, error) {
+	if launch {
+		return autoAppOp(b, func(be Backend) (OpenResult, error) { return be.OpenInObsidian(ctx, path, launch) })
+	}
+	return autoFileOp(b, func(be Backend) (OpenResult, error) { return be.OpenInObsidian(ctx, path, launch) })
+}
+
+func (b *AutoBackend) SyncStatus(ctx context.Context) (SyncStatus, error) {
+	return autoFileOp(b, func(be Backend) (SyncStatus, error) { return be.SyncStatus(ctx) })
+}
+
+func (b *AutoBackend) ListTasks(ctx context.Context, opts tasks.ListOptions) ([]tasks.Task, error) {
+	return autoFileOp(b, func(be Backend) ([]tasks.Task, error) { return be.ListTasks(ctx, opts) })
+}
+
+func (b *AutoBackend) GetTask(ctx context.Context, ref tasks.Ref) (tasks.Task, error) {
+	return autoFileOp(b, func(be Backend) (tasks.Task, error) { return be.GetTask(ctx, ref) })
+}
+
+func (b *AutoBackend) UpdateTask(ctx context.Context, ref tasks.Ref, input tasks.UpdateInput) (tasks.Task, error) {
+	return autoWriteOp(b, func(be Backend) (tasks.Task, error) { return be.UpdateTask(ctx, ref, input) })
+}
+
+func (b *AutoBackend) ListPlugins(ctx context.Context, filter string, enabledOnly bool) ([]PluginInfo, error) {
+	return autoFileOp(b, func(be Backend) ([]PluginInfo, error) { return be.ListPlugins(ctx, filter, enabledOnly) })
+}
+
+func (b *AutoBackend) ListCommandIDs(ctx context.Context, filter string) ([]string, error) {
+	return autoAppOp(b, func(be Backend) ([]string, error) { return be.ListCommandIDs(ctx, filter) })
+}
+
+func (b *AutoBackend) ExecuteCommand(ctx context.Context, id string) error {
+	_, err := autoAppOp(b, func(be Backend) (struct{}, error) { return struct{}{}, be.ExecuteCommand(ctx, id) })
+	return err
+}
@@ -0,0 +1,146 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/nightisyang/obsidian-cli/internal/vault"
+)
+
+func TestAutoBackendFallsBackToNativeForFileOps(t *testing.T) {
+	_, server := newFakeRESTAPI(t, "secret", map[string]string{"remote.md": "from api"})
+	api := newTestAPIBackend(t, server.URL, "secret")
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "local.md"), []byte("from disk"), 0o644); err != nil {
+		t.Fatalf("write note: %v", err)
+	}
+	auto := NewAutoBackend(api, NewNativeBackend(root, nil, vault.DefaultConfig(), "api"))
+	ctx := context.Background()
+
+	n, err := auto.GetNote(ctx, "remote.md")
+	if err != nil || n.Body != "from api" {
+		t.Fatalf("expected api read, got %+v, %v", n, err)
+	}
+	if got := ServedBy(auto); got != ServedByAPI {
+		t.Fatalf("expected api route, got %q", got)
+	}
+
+	// Domain errors from the API are returned as-is rather than retried.
+	if _, err := auto.GetNote(ctx, "local.md"); errs.ExitCode(err) != errs.ExitNotFound {
+		t.Fatalf("expected api not found, got %v", err)
+	}
+
+	server.Close()
+	n, err = auto.GetNote(ctx, "local.md")
+	if err != nil || n.Body != "from disk" {
+		t.Fatalf("expected native fallback, got %+v, %v", n, err)
+	}
+	if got := ServedBy(auto); got != ServedByNative {
+		t.Fatalf("expected native route, got %q", got)
+	}
+}
+
+func TestAutoBackendAppOpsDoNotFallBack(t *testing.T) {
+	fake, server := newFakeRESTAPI(t, "secret", map[string]string{})
+	auto := NewAutoBackend(newTestAPIBackend(t, server.URL, "secret"), NewNativeBackend(t.TempDir(), nil, vault.DefaultConfig(), "api"))
+
+	if err := auto.ExecuteCommand(context.Background(), "app:reload"); err != nil {
+		t.Fatalf("ExecuteCommand error: %v", err)
+	}
+	if len(fake.executed) != 1 {
+		t.Fatalf("expected command to run through api, got %v", fake.executed)
+	}
+
+	server.Close()
+	err := auto.ExecuteCommand(context.Background(), "app:reload")
+	if !IsAPIFailure(err) {
+		t.Fatalf("expected api failure without native fallback, got %v", err)
+	}
+	if got := ServedBy(auto); got != ServedByAPI {
+		t.Fatalf("expected api route, got %q", got)
+	}
+	if _, err := auto.OpenInObsidian(context.Background(), "plan.md", true); !IsAPIFailure(err) || ServedBy(auto) != ServedByAPI {
+		t.Fatalf("expected launching to stay on the api, got %v", err)
+	}
+}
+
+func TestAutoBackendDoesNotRetryUnansweredWrites(t *testing.T) {
+	fake, server := newFakeRESTAPI(t, "secret", map[string]string{"plan.md": "remote"})
+	// Reads are answered; writes reach the server but never get a response.
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fake.ServeHTTP(w, r)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}))
+	t.Cleanup(hanging.Close)
+	server.Close()
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "plan.md"), []byte("local\n"), 0o644); err != nil {
+		t.Fatalf("write note: %v", err)
+	}
+	auto := NewAutoBackend(newTestAPIBackend(t, hanging.URL, "secret"), NewNativeBackend(root, nil, vault.DefaultConfig(), "api"))
+
+	if _, err := auto.AppendNote(context.Background(), "plan.md", "more"); !IsAPIFailure(err) || IsAPINotApplied(err) {
+		t.Fatalf("expected an unanswered api write error, got %v", err)
+	}
+	if raw, _ := os.ReadFile(filepath.Join(root, "plan.md")); string(raw) != "local\n" {
+		t.Fatalf("write was retried natively: %q", raw)
+	}
+
+	// A refused connection never reached Obsidian, so the write falls back.
+	refused := NewAutoBackend(newTestAPIBackend(t, server.URL, "secret"), NewNativeBackend(root, nil, vault.DefaultConfig(), "api"))
+	if _, err := refused.AppendNote(context.Background(), "plan.md", "more"); err != nil {
+		t.Fatalf("expected native fallback, got %v", err)
+	}
+	if got := ServedBy(refused); got != ServedByNative {
+		t.Fatalf("expected native route, got %q", got)
+	}
+}
+
+func TestAutoBackendDoesNotFinishPartlyAppliedMovesNatively(t *testing.T) {
+	fake, server := newFakeRESTAPI(t, "secret", map[string]string{"plan.md": "remote"})
+	server.Close()
+	// Obsidian goes away right after writing the destination, so deleting
+	// the source cannot even connect.
+	var stopping *httptest.Server
+	stopping = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			w.Header().Set("Connection", "close")
+			_ = stopping.Listener.Close()
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	t.Cleanup(stopping.Close)
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "plan.md"), []byte("local\n"), 0o644); err != nil {
+		t.Fatalf("write note: %v", err)
+	}
+	auto := NewAutoBackend(newTestAPIBackend(t, stopping.URL, "secret"), NewNativeBackend(root, nil, vault.DefaultConfig(), "api"))
+
+	_, err := auto.MoveNote(context.Background(), "plan.md", "archive/plan.md", note.MoveOptions{})
+	var appErr *errs.AppError
+	if !errors.As(err, &appErr) || appErr.Reason != "api_partial_apply" || !strings.Contains(appErr.Message, "wrote archive/plan.md") || !IsAPINotApplied(appErr.Err) {
+		t.Fatalf("expected a partial apply error, got %v", err)
+	}
+	if raw, _ := os.ReadFile(filepath.Join(root, "plan.md")); string(raw) != "local\n" {
+		t.Fatalf("move was retried natively: %q", raw)
+	}
+	if _, err := os.Stat(filepath.Join(root, "archive", "plan.md")); !os.IsNotExist(err) {
+		t.Fatalf("move was retried natively: %v", err)
+	}
+}
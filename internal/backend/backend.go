@@ -2,8 +2,11 @@ package backend
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/nightisyang/obsidian-cli/internal/errs"
 	"github.com/nightisyang/obsidian-cli/internal/index"
 	"github.com/nightisyang/obsidian-cli/internal/note"
 	"github.com/nightisyang/obsidian-cli/internal/search"
@@ -55,8 +58,8 @@ type Backend interface {
 	Search(ctx context.Context, q search.Query) ([]search.SearchResult, error)
 	ListTags(ctx context.Context, opts index.TagListOptions) ([]index.TagCount, error)
 	SearchTag(ctx context.Context, tag string, limit int) ([]search.SearchResult, error)
-	OutgoingLinks(ctx context.Context, path string) ([]string, error)
-	Backlinks(ctx context.Context, path string, rebuild bool) ([]string, error)
+	OutgoingLinks(ctx context.Context, path string) ([]note.Link, error)
+	Backlinks(ctx context.Context, path string, rebuild bool) ([]index.Backlink, error)
 	PropGet(ctx context.Context, path, key string) (any, error)
 	PropSet(ctx context.Context, path, key string, value any) (note.Note, error)
 	PropDelete(ctx context.Context, path, key string) (note.Note, error)
@@ -70,3 +73,26 @@ type Backend interface {
 	ListCommandIDs(ctx context.Context, filter string) ([]string, error)
 	ExecuteCommand(ctx context.Context, id string) error
 }
+
+// pickNamedNote chooses the note a title or alias lookup refers to. It
+// returns "" when nothing matched so callers can report the original error.
+func pickNamedNote(ref string, matches []string) (string, error) {
+	switch len(matches) {
+	case 0:
+		return "", nil
+	case 1:
+		return matches[0], nil
+	}
+	return "", errs.NewDetailed(
+		errs.ExitValidation,
+		"ambiguous_note_reference",
+		"Pass the note path instead of its title or alias.",
+		fmt.Sprintf("%q matches several notes: %s", ref, strings.Join(matches, ", ")),
+	)
+}
+
+// noteNameRef strips the extension so "Q3 Plan.md" can match the alias
+// "Q3 Plan".
+func noteNameRef(ref string) string {
+	return strings.TrimSuffix(strings.TrimSpace(ref), ".md")
+}
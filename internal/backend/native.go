@@ -14,9 +14,11 @@ import (
 
 	"github.com/nightisyang/obsidian-cli/internal/errs"
 	"github.com/nightisyang/obsidian-cli/internal/frontmatter"
+	"github.com/nightisyang/obsidian-cli/internal/history"
 	"github.com/nightisyang/obsidian-cli/internal/index"
 	"github.com/nightisyang/obsidian-cli/internal/note"
 	"github.com/nightisyang/obsidian-cli/internal/search"
+	"github.com/nightisyang/obsidian-cli/internal/store"
 	"github.com/nightisyang/obsidian-cli/internal/tasks"
 	"github.com/nightisyang/obsidian-cli/internal/templates"
 	"github.com/nightisyang/obsidian-cli/internal/vault"
@@ -27,19 +29,35 @@ type NativeBackend struct {
 	cfg       vault.Config
 	mode      string
 	engine    search.Engine
+	ranker    search.Engine
+	store     *store.Store
+	history   *history.Store
 }
 
-func NewNativeBackend(vaultRoot string, cfg vault.Config, mode string) *NativeBackend {
+func NewNativeBackend(vaultRoot string, hist *history.Store, cfg vault.Config, mode string) *NativeBackend {
+	s := store.Open(vaultRoot, cfg.IndexDir)
 	return &NativeBackend{
 		vaultRoot: vaultRoot,
 		cfg:       cfg,
 		mode:      mode,
 		engine:    &search.RGEngine{VaultRoot: vaultRoot},
+		ranker:    &search.BM25Engine{VaultRoot: vaultRoot, IndexDir: cfg.IndexDir},
+		store:     s,
+		history:   hist,
 	}
 }
 
 func (b *NativeBackend) VaultStatus(_ context.Context) (vault.Status, error) {
-	return vault.ComputeStatus(b.vaultRoot, "", "", b.mode)
+	snap, err := b.snapshot()
+	if err != nil {
+		return vault.Status{}, err
+	}
+	return vault.Status{
+		Root:          b.vaultRoot,
+		EffectiveMode: b.mode,
+		NoteCount:     len(snap.Files),
+		TagCount:      len(snap.TagCounts()),
+	}, nil
 }
 
 func (b *NativeBackend) CreateNote(_ context.Context, in note.CreateInput) (note.Note, error) {
@@ -50,11 +68,28 @@ func (b *NativeBackend) CreateNote(_ context.Context, in note.CreateInput) (note
 		}
 		in.Content = tpl.Content
 	}
-	return note.Create(b.vaultRoot, in)
+	return note.Create(b.vaultRoot, b.history, in)
 }
 
+// GetNote reads a note by path, falling back to a unique file name,
+// frontmatter title or alias match.
 func (b *NativeBackend) GetNote(_ context.Context, path string) (note.Note, error) {
-	return note.Get(b.vaultRoot, path)
+	n, err := note.Get(b.vaultRoot, path)
+	if errs.ExitCode(err) != errs.ExitNotFound {
+		return n, err
+	}
+	snap, snapErr := b.snapshot()
+	if snapErr != nil {
+		return note.Note{}, err
+	}
+	match, pickErr := pickNamedNote(path, snap.Lookup(noteNameRef(path)))
+	if pickErr != nil {
+		return note.Note{}, pickErr
+	}
+	if match == "" {
+		return note.Note{}, err
+	}
+	return note.Get(b.vaultRoot, match)
 }
 
 func (b *NativeBackend) GetHeading(_ context.Context, path, heading string) (note.HeadingSection, error) {
@@ -66,19 +101,19 @@ func (b *NativeBackend) GetBlock(_ context.Context, path, blockID string) (note.
 }
 
 func (b *NativeBackend) SetBlock(_ context.Context, path, blockID, content string) (note.Block, error) {
-	return note.SetBlock(b.vaultRoot, path, blockID, content)
+	return note.SetBlock(b.vaultRoot, b.history, path, blockID, content)
 }
 
 func (b *NativeBackend) AppendNote(_ context.Context, path, content string) (note.Note, error) {
-	return note.Append(b.vaultRoot, path, content)
+	return note.Append(b.vaultRoot, b.history, path, content)
 }
 
 func (b *NativeBackend) PrependNote(_ context.Context, path, content string) (note.Note, error) {
-	return note.Prepend(b.vaultRoot, path, content)
+	return note.Prepend(b.vaultRoot, b.history, path, content)
 }
 
 func (b *NativeBackend) DeleteNote(_ context.Context, path string) error {
-	return note.Delete(b.vaultRoot, path)
+	return note.Delete(b.vaultRoot, b.history, path)
 }
 
 func (b *NativeBackend) ListNotes(_ context.Context, dir string, opts note.ListOptions) ([]note.Note, error) {
@@ -86,7 +121,7 @@ func (b *NativeBackend) ListNotes(_ context.Context, dir string, opts note.ListO
 }
 
 func (b *NativeBackend) MoveNote(_ context.Context, src, dst string, opts note.MoveOptions) (note.Note, error) {
-	n, _, err := note.Move(b.vaultRoot, src, dst, opts)
+	n, _, err := note.Move(b.vaultRoot, b.history, src, dst, opts)
 	return n, err
 }
 
@@ -95,17 +130,17 @@ func (b *NativeBackend) DailyPath(_ context.Context, at time.Time) (string, erro
 }
 
 func (b *NativeBackend) DailyRead(_ context.Context, at time.Time, create bool) (note.Note, error) {
-	n, _, err := note.DailyRead(b.vaultRoot, at, create)
+	n, _, err := note.DailyRead(b.vaultRoot, b.history, at, create)
 	return n, err
 }
 
 func (b *NativeBackend) DailyAppend(_ context.Context, at time.Time, content string, inline bool) (note.Note, error) {
-	n, _, err := note.DailyAppend(b.vaultRoot, at, content, inline)
+	n, _, err := note.DailyAppend(b.vaultRoot, b.history, at, content, inline)
 	return n, err
 }
 
 func (b *NativeBackend) DailyPrepend(_ context.Context, at time.Time, content string, inline bool) (note.Note, error) {
-	n, _, err := note.DailyPrepend(b.vaultRoot, at, content, inline)
+	n, _, err := note.DailyPrepend(b.vaultRoot, b.history, at, content, inline)
 	return n, err
 }
 
@@ -127,70 +162,91 @@ func (b *NativeBackend) InsertTemplate(_ context.Context, path, name, title stri
 		return note.Note{}, err
 	}
 	n.Body += tpl.Content
-	return note.Write(b.vaultRoot, n.Path, n, false, now())
+	return note.Write(b.vaultRoot, b.history, n.Path, n, false, now())
 }
 
 func (b *NativeBackend) Search(ctx context.Context, q search.Query) ([]search.SearchResult, error) {
 	switch q.Type {
 	case search.QueryText:
+		if q.Ranked {
+			return b.ranker.Search(ctx, q)
+		}
 		return b.engine.Search(ctx, q)
 	case search.QueryTag:
-		results, err := index.SearchTag(b.vaultRoot, q.Tag, q.Limit)
+		results, err := b.SearchTag(ctx, q.Tag, q.Limit)
 		if err != nil {
 			return nil, err
 		}
 		return filterByPath(results, q.Path), nil
 	case search.QueryProp:
 		return b.searchByProp(q)
+	case search.QueryExpr:
+		return b.searchByExpr(q)
 	default:
 		return nil, errs.New(errs.ExitValidation, "unknown search query type")
 	}
 }
 
 func (b *NativeBackend) ListTags(_ context.Context, opts index.TagListOptions) ([]index.TagCount, error) {
-	return index.ListTags(b.vaultRoot, opts)
+	snap, err := b.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return index.RankTags(snap.TagCounts(), opts), nil
 }
 
 func (b *NativeBackend) SearchTag(_ context.Context, tag string, limit int) ([]search.SearchResult, error) {
-	return index.SearchTag(b.vaultRoot, tag, limit)
+	snap, err := b.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	results := []search.SearchResult{}
+	for _, rel := range snap.TaggedPaths(tag) {
+		results = append(results, index.TagMatch(rel, tag))
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+	}
+	return results, nil
 }
 
-func (b *NativeBackend) OutgoingLinks(_ context.Context, path string) ([]string, error) {
+func (b *NativeBackend) OutgoingLinks(_ context.Context, path string) ([]note.Link, error) {
 	n, err := note.Get(b.vaultRoot, path)
 	if err != nil {
 		return nil, err
 	}
-	return index.ParseWikiLinks(n.Body), nil
+	snap, err := b.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	resolver, err := b.store.Resolver(snap)
+	if err != nil {
+		return nil, err
+	}
+	return snap.CheckAnchors(resolver.ResolveLinks(n.Path, note.ParseLinks(n.Body))), nil
 }
 
-func (b *NativeBackend) Backlinks(_ context.Context, path string, rebuild bool) ([]string, error) {
-	var idx index.BacklinkIndex
-	var ok bool
-	if !rebuild {
-		idx, ok = index.GetCached(b.vaultRoot)
-		if ok {
-			stale, err := index.IsStale(b.vaultRoot, idx)
-			if err != nil {
-				return nil, err
-			}
-			if stale {
-				ok = false
-			}
-		}
+func (b *NativeBackend) Backlinks(_ context.Context, path string, rebuild bool) ([]index.Backlink, error) {
+	var snap store.Snapshot
+	var err error
+	if rebuild {
+		snap, _, err = b.store.Rebuild()
+	} else {
+		snap, err = b.snapshot()
 	}
-	if !ok || rebuild {
-		built, err := index.BuildIndex(b.vaultRoot)
-		if err != nil {
-			return nil, err
-		}
-		idx = built
-		index.SetCached(b.vaultRoot, idx)
+	if err != nil {
+		return nil, err
 	}
 	_, rel, err := vault.ResolveNoteAbs(b.vaultRoot, path)
 	if err != nil {
 		return nil, err
 	}
-	return index.BacklinksForPath(b.vaultRoot, idx, rel), nil
+	resolver, err := b.store.Resolver(snap)
+	if err != nil {
+		return nil, err
+	}
+	resolver.Add(rel)
+	return snap.BacklinkOccurrences(resolver, rel), nil
 }
 
 func (b *NativeBackend) PropGet(_ context.Context, path, key string) (any, error) {
@@ -214,7 +270,7 @@ func (b *NativeBackend) PropSet(_ context.Context, path, key string, value any)
 	values := frontmatter.FrontmatterToMap(n.Frontmatter)
 	values[key] = value
 	n.Frontmatter = frontmatter.MapToFrontmatter(values)
-	return note.Write(b.vaultRoot, n.Path, n, false, now())
+	return note.Write(b.vaultRoot, b.history, n.Path, n, false, now())
 }
 
 func (b *NativeBackend) PropDelete(_ context.Context, path, key string) (note.Note, error) {
@@ -228,7 +284,7 @@ func (b *NativeBackend) PropDelete(_ context.Context, path, key string) (note.No
 	}
 	delete(values, key)
 	n.Frontmatter = frontmatter.MapToFrontmatter(values)
-	return note.Write(b.vaultRoot, n.Path, n, false, now())
+	return note.Write(b.vaultRoot, b.history, n.Path, n, false, now())
 }
 
 func (b *NativeBackend) PropList(_ context.Context, path string) (map[string]any, error) {
@@ -292,7 +348,21 @@ func (b *NativeBackend) SyncStatus(_ context.Context) (SyncStatus, error) {
 }
 
 func (b *NativeBackend) ListTasks(_ context.Context, opts tasks.ListOptions) ([]tasks.Task, error) {
-	return tasks.List(b.vaultRoot, opts)
+	if strings.TrimSpace(opts.Path) != "" {
+		_, rel, err := vault.ResolveNoteAbs(b.vaultRoot, opts.Path)
+		if err != nil {
+			return nil, err
+		}
+		opts.Path = filepath.ToSlash(rel)
+	}
+	snap, err := b.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := snap.Files[opts.Path]; opts.Path != "" && !ok {
+		return []tasks.Task{}, nil
+	}
+	return snap.Tasks(opts), nil
 }
 
 func (b *NativeBackend) GetTask(_ context.Context, ref tasks.Ref) (tasks.Task, error) {
@@ -300,7 +370,7 @@ func (b *NativeBackend) GetTask(_ context.Context, ref tasks.Ref) (tasks.Task, e
 }
 
 func (b *NativeBackend) UpdateTask(_ context.Context, ref tasks.Ref, input tasks.UpdateInput) (tasks.Task, error) {
-	return tasks.Update(b.vaultRoot, ref, input)
+	return tasks.Update(b.vaultRoot, b.history, ref, input)
 }
 
 func (b *NativeBackend) ListPlugins(_ context.Context, filter string, enabledOnly bool) ([]PluginInfo, error) {
@@ -424,24 +494,45 @@ func (b *NativeBackend) loadPluginIDs(relPath string) ([]string, error) {
 }
 
 func (b *NativeBackend) searchByProp(q search.Query) ([]search.SearchResult, error) {
-	notes, err := note.List(b.vaultRoot, q.Path, note.ListOptions{Recursive: true})
+	snap, err := b.snapshot()
 	if err != nil {
 		return nil, err
 	}
+	dir := strings.Trim(filepath.ToSlash(strings.TrimSpace(q.Path)), "/")
 	matches := []search.SearchResult{}
-	for _, n := range notes {
-		props := frontmatter.FrontmatterToMap(n.Frontmatter)
-		value, ok := props[q.PropKey]
-		if !ok {
+	for _, rel := range snap.Paths() {
+		if dir != "" && dir != "." && !strings.HasPrefix(rel, dir+"/") {
 			continue
 		}
-		if strings.EqualFold(fmt.Sprint(value), q.PropValue) {
-			matches = append(matches, search.SearchResult{
-				Path:      n.Path,
-				Match:     fmt.Sprintf("%s=%v", q.PropKey, value),
-				Snippet:   "frontmatter property match",
-				MatchType: "prop",
-			})
+		if result, ok := matchProp(rel, snap.Files[rel].Properties, q); ok {
+			matches = append(matches, result)
+			if q.Limit > 0 && len(matches) >= q.Limit {
+				break
+			}
+		}
+	}
+	return matches, nil
+}
+
+func (b *NativeBackend) searchByExpr(q search.Query) ([]search.SearchResult, error) {
+	snap, err := b.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	dir := strings.Trim(filepath.ToSlash(strings.TrimSpace(q.Path)), "/")
+	matches := []search.SearchResult{}
+	for _, rel := range snap.Paths() {
+		if dir != "" && dir != "." && !strings.HasPrefix(rel, dir+"/") {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(b.vaultRoot, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, errs.Wrap(errs.ExitGeneric, "failed to read note", err)
+		}
+		entry := snap.Files[rel]
+		doc := search.Document{Path: rel, Content: string(raw), Tags: entry.Tags, Properties: entry.Properties}
+		if result, ok := search.MatchExpr(doc, q); ok {
+			matches = append(matches, result)
 			if q.Limit > 0 && len(matches) >= q.Limit {
 				break
 			}
@@ -450,6 +541,64 @@ func (b *NativeBackend) searchByProp(q search.Query) ([]search.SearchResult, err
 	return matches, nil
 }
 
+func matchExprs(notes []note.Note, q search.Query) []search.SearchResult {
+	matches := []search.SearchResult{}
+	for _, n := range notes {
+		doc := search.Document{
+			Path:       n.Path,
+			Content:    n.Raw,
+			Tags:       index.NoteTags(n),
+			Properties: frontmatter.FrontmatterToMap(n.Frontmatter),
+		}
+		if result, ok := search.MatchExpr(doc, q); ok {
+			matches = append(matches, result)
+			if q.Limit > 0 && len(matches) >= q.Limit {
+				break
+			}
+		}
+	}
+	return matches
+}
+
+func matchProps(notes []note.Note, q search.Query) []search.SearchResult {
+	matches := []search.SearchResult{}
+	for _, n := range notes {
+		if result, ok := matchProp(n.Path, frontmatter.FrontmatterToMap(n.Frontmatter), q); ok {
+			matches = append(matches, result)
+			if q.Limit > 0 && len(matches) >= q.Limit {
+				break
+			}
+		}
+	}
+	return matches
+}
+
+func matchProp(path string, props map[string]any, q search.Query) (search.SearchResult, bool) {
+	if !search.MatchPropFilters(q.Props, props) {
+		return search.SearchResult{}, false
+	}
+	matched := make([]string, 0, len(q.Props))
+	for _, f := range q.Props {
+		if value, ok := props[f.Key]; ok {
+			matched = append(matched, fmt.Sprintf("%s=%v", f.Key, value))
+		} else {
+			matched = append(matched, f.String())
+		}
+	}
+	return search.SearchResult{
+		Path:      path,
+		Match:     strings.Join(matched, " "),
+		Snippet:   "frontmatter property match",
+		MatchType: "prop",
+	}, true
+}
+
+// snapshot returns the persisted vault index after an incremental refresh.
+func (b *NativeBackend) snapshot() (store.Snapshot, error) {
+	snap, _, err := b.store.Refresh()
+	return snap, err
+}
+
 func filterByPath(results []search.SearchResult, prefix string) []search.SearchResult {
 	prefix = strings.TrimSpace(prefix)
 	if prefix == "" {
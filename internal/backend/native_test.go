@@ -26,7 +26,7 @@ func TestCreateNoteWithTemplate(t *testing.T) {
 	now = func() time.Time { return time.Date(2026, 2, 20, 1, 2, 3, 0, time.UTC) }
 	t.Cleanup(func() { now = oldNow })
 
-	b := NewNativeBackend(root, vault.DefaultConfig(), "native")
+	b := NewNativeBackend(root, nil, vault.DefaultConfig(), "native")
 	created, err := b.CreateNote(context.Background(), note.CreateInput{Title: "Trip", Template: "Daily"})
 	if err != nil {
 		t.Fatalf("CreateNote error: %v", err)
@@ -51,11 +51,11 @@ func TestListPluginsAndOpenURI(t *testing.T) {
 		t.Fatalf("write manifest: %v", err)
 	}
 
-	if _, err := note.Create(root, note.CreateInput{Title: "Alpha"}); err != nil {
+	if _, err := note.Create(root, nil, note.CreateInput{Title: "Alpha"}); err != nil {
 		t.Fatalf("create note: %v", err)
 	}
 
-	b := NewNativeBackend(root, vault.DefaultConfig(), "native")
+	b := NewNativeBackend(root, nil, vault.DefaultConfig(), "native")
 	plugins, err := b.ListPlugins(context.Background(), "", false)
 	if err != nil {
 		t.Fatalf("ListPlugins error: %v", err)
@@ -82,12 +82,12 @@ func TestBacklinksRebuildsWhenCacheIsStale(t *testing.T) {
 		t.Fatalf("write b: %v", err)
 	}
 
-	b := NewNativeBackend(root, vault.DefaultConfig(), "native")
+	b := NewNativeBackend(root, nil, vault.DefaultConfig(), "native")
 	before, err := b.Backlinks(context.Background(), "b.md", false)
 	if err != nil {
 		t.Fatalf("Backlinks initial error: %v", err)
 	}
-	if len(before) != 1 || before[0] != "a.md" {
+	if len(before) != 1 || before[0].Source != "a.md" || len(before[0].Links) != 1 || before[0].Links[0].Line != 1 {
 		t.Fatalf("unexpected initial backlinks: %+v", before)
 	}
 
@@ -120,7 +120,7 @@ hello`
 		t.Fatalf("write note: %v", err)
 	}
 
-	b := NewNativeBackend(root, vault.DefaultConfig(), "native")
+	b := NewNativeBackend(root, nil, vault.DefaultConfig(), "native")
 	n, err := b.PropDelete(context.Background(), "alpha.md", "status")
 	if err != nil {
 		t.Fatalf("PropDelete error: %v", err)
@@ -0,0 +1,241 @@
+package bases
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"gopkg.in/yaml.v3"
+)
+
+// Ext is the file extension Obsidian uses for Bases.
+const Ext = ".base"
+
+// Base is a parsed .base file: global filters and formulas shared by every
+// view, display settings for properties and the views themselves.
+type Base struct {
+	Path       string              `json:"path"`
+	Filters    *Filter             `json:"filters,omitempty"`
+	Formulas   map[string]string   `json:"formulas,omitempty"`
+	Properties map[string]Property `json:"properties,omitempty"`
+	Views      []View              `json:"views"`
+
+	formulas map[string]*node
+}
+
+// Property holds per-property display settings.
+type Property struct {
+	DisplayName string `yaml:"displayName" json:"display_name,omitempty"`
+}
+
+// View is one named table/list/cards view of a base.
+type View struct {
+	Type    string     `yaml:"type" json:"type"`
+	Name    string     `yaml:"name" json:"name"`
+	Filters *Filter    `yaml:"filters" json:"filters,omitempty"`
+	Order   []string   `yaml:"order" json:"order,omitempty"`
+	Sort    []SortSpec `yaml:"sort" json:"sort,omitempty"`
+	GroupBy *SortSpec  `yaml:"groupBy" json:"group_by,omitempty"`
+	Limit   int        `yaml:"limit" json:"limit,omitempty"`
+}
+
+// SortSpec orders rows by a property id such as "file.name", "status" or
+// "formula.total".
+type SortSpec struct {
+	Property  string `yaml:"property" json:"property"`
+	Direction string `yaml:"direction" json:"direction,omitempty"`
+}
+
+// Desc reports whether the spec sorts in descending order.
+func (s SortSpec) Desc() bool {
+	return strings.EqualFold(strings.TrimSpace(s.Direction), "desc")
+}
+
+// Filter is either a single expression or an and/or/not group of filters.
+type Filter struct {
+	Expr string   `json:"expr,omitempty"`
+	And  []Filter `json:"and,omitempty"`
+	Or   []Filter `json:"or,omitempty"`
+	Not  []Filter `json:"not,omitempty"`
+
+	compiled *node
+}
+
+// UnmarshalYAML accepts both the string and the mapping form of a filter.
+func (f *Filter) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		f.Expr = value.Value
+		return nil
+	}
+	var group struct {
+		And []Filter `yaml:"and"`
+		Or  []Filter `yaml:"or"`
+		Not []Filter `yaml:"not"`
+	}
+	if err := value.Decode(&group); err != nil {
+		return err
+	}
+	f.And, f.Or, f.Not = group.And, group.Or, group.Not
+	return nil
+}
+
+func (f *Filter) compile() error {
+	if f == nil {
+		return nil
+	}
+	if strings.TrimSpace(f.Expr) != "" {
+		n, err := parseExpr(f.Expr)
+		if err != nil {
+			return err
+		}
+		f.compiled = n
+	}
+	for _, group := range [][]Filter{f.And, f.Or, f.Not} {
+		for i := range group {
+			if err := group[i].compile(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Parse decodes a .base document and compiles its filters and formulas.
+func Parse(rel string, data []byte) (*Base, error) {
+	var doc struct {
+		Filters    *Filter             `yaml:"filters"`
+		Formulas   map[string]string   `yaml:"formulas"`
+		Properties map[string]Property `yaml:"properties"`
+		Views      []View              `yaml:"views"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errs.WrapDetailed(
+			errs.ExitValidation,
+			"invalid_base",
+			"Check the .base file is valid YAML with filters, formulas, properties and views keys.",
+			"failed to parse base "+rel,
+			err,
+		)
+	}
+	b := &Base{
+		Path:       rel,
+		Filters:    doc.Filters,
+		Formulas:   doc.Formulas,
+		Properties: doc.Properties,
+		Views:      doc.Views,
+		formulas:   map[string]*node{},
+	}
+	if b.Views == nil {
+		b.Views = []View{}
+	}
+	if err := b.Filters.compile(); err != nil {
+		return nil, err
+	}
+	for name, src := range b.Formulas {
+		n, err := parseExpr(src)
+		if err != nil {
+			return nil, err
+		}
+		b.formulas[name] = n
+	}
+	for i := range b.Views {
+		if err := b.Views[i].Filters.compile(); err != nil {
+			return nil, err
+		}
+		if b.Views[i].Type == "" {
+			b.Views[i].Type = "table"
+		}
+	}
+	return b, nil
+}
+
+// Load reads and parses a .base file; rel is vault-relative and the
+// extension is optional.
+func Load(vaultRoot, rel string) (*Base, error) {
+	normalized := filepath.ToSlash(filepath.Clean(strings.TrimPrefix(strings.TrimSpace(rel), "/")))
+	if !strings.HasSuffix(strings.ToLower(normalized), Ext) {
+		normalized += Ext
+	}
+	abs := filepath.Join(vaultRoot, filepath.FromSlash(normalized))
+	cleanRoot := filepath.Clean(vaultRoot)
+	if !strings.HasPrefix(abs, cleanRoot+string(filepath.Separator)) {
+		return nil, errs.New(errs.ExitValidation, "path escapes vault root")
+	}
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errs.New(errs.ExitNotFound, "base not found: "+normalized)
+		}
+		return nil, errs.Wrap(errs.ExitGeneric, "failed to read base", err)
+	}
+	return Parse(normalized, data)
+}
+
+// List returns the vault-relative paths of every .base file, skipping
+// hidden directories.
+func List(vaultRoot string) ([]string, error) {
+	out := []string{}
+	err := filepath.WalkDir(vaultRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != vaultRoot && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.EqualFold(filepath.Ext(d.Name()), Ext) {
+			return nil
+		}
+		rel, err := filepath.Rel(vaultRoot, path)
+		if err != nil {
+			return err
+		}
+		out = append(out, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, errs.Wrap(errs.ExitGeneric, "failed to scan vault for bases", err)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// View returns the view called name, or the first view when name is empty.
+func (b *Base) View(name string) (View, error) {
+	if len(b.Views) == 0 {
+		if strings.TrimSpace(name) != "" {
+			return View{}, errs.New(errs.ExitNotFound, "view not found: "+name)
+		}
+		return View{Type: "table", Name: ""}, nil
+	}
+	if strings.TrimSpace(name) == "" {
+		return b.Views[0], nil
+	}
+	for _, v := range b.Views {
+		if strings.EqualFold(v.Name, name) {
+			return v, nil
+		}
+	}
+	return View{}, errs.NewDetailed(
+		errs.ExitNotFound,
+		"view_not_found",
+		"Run `base views` to list the views defined in this base.",
+		"view not found: "+name,
+	)
+}
+
+// DisplayName returns the configured column label for a property id.
+func (b *Base) DisplayName(id string) string {
+	if p, ok := b.Properties[id]; ok && strings.TrimSpace(p.DisplayName) != "" {
+		return p.DisplayName
+	}
+	if p, ok := b.Properties["note."+id]; ok && strings.TrimSpace(p.DisplayName) != "" {
+		return p.DisplayName
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(id, "note."), "formula.")
+}
@@ -0,0 +1,200 @@
+package bases
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/store"
+)
+
+const booksBase = `filters:
+  and:
+    - file.hasTag("book")
+    - 'file.ext == "md"'
+formulas:
+  score: 'rating * 2'
+  overdue: 'due < today()'
+properties:
+  formula.score:
+    displayName: Score
+views:
+  - type: table
+    name: Unread
+    filters:
+      not:
+        - 'status == "read"'
+    order:
+      - file.basename
+      - rating
+      - formula.score
+    sort:
+      - property: rating
+        direction: DESC
+  - type: table
+    name: By status
+    order: [file.basename, formula.overdue]
+    groupBy:
+      property: status
+      direction: ASC
+    sort:
+      - property: file.basename
+    limit: 3
+`
+
+func testVault(t *testing.T) (string, store.Snapshot) {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"books/dune.md":      "---\ntags: [book]\nstatus: reading\nrating: 5\ndue: 2024-04-01\n---\n# Dune\nSee [[hyperion]].\n",
+		"books/hyperion.md":  "---\ntags: [book, scifi]\nstatus: read\nrating: 4\n---\n# Hyperion\n",
+		"books/emma.md":      "---\ntags: [book]\nstatus: queued\nrating: 3\ndue: 2024-06-01\n---\n# Emma\n",
+		"notes/unrelated.md": "---\nstatus: queued\n---\nNothing here.\n",
+		"books.base":         booksBase,
+		"views/broken.base":  "views: [",
+	}
+	for rel, content := range files {
+		abs := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(abs, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+	snap, _, err := store.Open(root, "").Refresh()
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	return root, snap
+}
+
+func runView(t *testing.T, root string, snap store.Snapshot, view string) Result {
+	t.Helper()
+	b, err := Load(root, "books")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	res, err := Query(root, b, snap, QueryOptions{View: view, Now: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	return res
+}
+
+func TestListAndParse(t *testing.T) {
+	root, _ := testVault(t)
+	paths, err := List(root)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if !reflect.DeepEqual(paths, []string{"books.base", "views/broken.base"}) {
+		t.Fatalf("unexpected bases: %v", paths)
+	}
+	b, err := Load(root, "books.base")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(b.Views) != 2 || b.Views[1].GroupBy == nil || b.Views[1].Limit != 3 || len(b.Filters.And) != 2 {
+		t.Fatalf("unexpected base: %+v", b)
+	}
+	if _, err := Load(root, "views/broken"); err == nil {
+		t.Fatalf("expected YAML error")
+	}
+	if _, err := Load(root, "missing"); err == nil {
+		t.Fatalf("expected not found error")
+	}
+	if _, err := b.View("nope"); err == nil {
+		t.Fatalf("expected unknown view error")
+	}
+}
+
+func TestQueryFiltersFormulasAndSort(t *testing.T) {
+	root, snap := testVault(t)
+	res := runView(t, root, snap, "")
+	if res.View != "Unread" || res.Columns[2].Name != "Score" {
+		t.Fatalf("unexpected result header: %+v", res)
+	}
+	var names []any
+	for _, row := range res.Rows {
+		names = append(names, row["file.basename"])
+	}
+	if !reflect.DeepEqual(names, []any{"dune", "emma"}) {
+		t.Fatalf("unexpected rows: %v", names)
+	}
+	if res.Rows[0]["formula.score"] != 10.0 || res.Rows[1]["rating"] != 3.0 {
+		t.Fatalf("unexpected values: %+v", res.Rows)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, res); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	want := "path,file.basename,rating,Score\nbooks/dune.md,dune,5,10\nbooks/emma.md,emma,3,6\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected CSV:\n%s", buf.String())
+	}
+}
+
+func TestQueryGroupByAndLimit(t *testing.T) {
+	root, snap := testVault(t)
+	res := runView(t, root, snap, "by status")
+	if res.GroupBy != "status" || len(res.Rows) != 3 {
+		t.Fatalf("unexpected grouped result: %+v", res)
+	}
+	var got []string
+	for _, row := range res.Rows {
+		got = append(got, row["group"].(string)+":"+row["file.basename"].(string))
+	}
+	if strings.Join(got, " ") != "queued:emma read:hyperion reading:dune" {
+		t.Fatalf("unexpected order: %v", got)
+	}
+	if res.Rows[2]["formula.overdue"] != true || res.Rows[0]["formula.overdue"] != false {
+		t.Fatalf("unexpected overdue formula: %+v", res.Rows)
+	}
+}
+
+func TestExpressions(t *testing.T) {
+	file := &fileObj{
+		rel:   "books/dune.md",
+		entry: store.Entry{Tags: []string{"book/scifi"}, Links: []string{"authors/Frank Herbert"}},
+		props: normalize(map[string]any{"rating": 5, "title": "Dune", "genres": []any{"scifi", "classic"}, "due": time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)}).(map[string]any),
+	}
+	b := &Base{formulas: map[string]*node{}}
+	cases := map[string]any{
+		`file.hasTag("book")`:                      true,
+		`file.hasTag("#scifi", "book/scifi")`:      true,
+		`file.inFolder("books")`:                   true,
+		`file.hasLink("Frank Herbert")`:            true,
+		`file.name + " / " + file.folder`:          "dune.md / books",
+		`genres.contains("classic") && rating > 4`: true,
+		`genres.containsAny("x", "scifi")`:         true,
+		`title.lower().startsWith("du")`:           true,
+		`!title.isEmpty() && missing.isEmpty()`:    true,
+		`if(rating >= 5, "top", "ok")`:             "top",
+		`due + "1M" == date("2024-05-01")`:         true,
+		`due < "2024-04-02"`:                       true,
+		`(rating * 3 - 1) % 4`:                     2.0,
+		`genres[1] === "classic"`:                  true,
+		`due.format("YYYY/MM")`:                    "2024/04",
+		`max(rating, 7, 2)`:                        7.0,
+	}
+	for src, want := range cases {
+		n, err := parseExpr(src)
+		if err != nil {
+			t.Fatalf("parseExpr(%q): %v", src, err)
+		}
+		if got := newEnv(b, file, time.Now()).eval(n); !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: got %#v, want %#v", src, got, want)
+		}
+	}
+	for _, src := range []string{`rating >`, `nosuch(1)`, `"open`, `a @ b`, `(a`} {
+		if _, err := parseExpr(src); err == nil {
+			t.Fatalf("expected parse error for %q", src)
+		}
+	}
+}
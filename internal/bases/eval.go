@@ -0,0 +1,627 @@
+package bases
+
+import (
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/index"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+)
+
+// fileObj is the `file` value of a row: index metadata plus the note's
+// frontmatter properties.
+type fileObj struct {
+	rel   string
+	entry store.Entry
+	props map[string]any
+}
+
+// formulaRef is the `formula` namespace; members evaluate lazily.
+type formulaRef struct{}
+
+var globalFunctions = map[string]bool{
+	"if": true, "today": true, "now": true, "date": true,
+	"number": true, "list": true, "min": true, "max": true,
+}
+
+func knownFunction(name string) bool {
+	return globalFunctions[name]
+}
+
+// env evaluates expressions for one row. Formula results are cached per
+// row and cycles evaluate to null.
+type env struct {
+	base   *Base
+	file   *fileObj
+	now    time.Time
+	cache  map[string]any
+	active map[string]bool
+}
+
+func newEnv(b *Base, file *fileObj, now time.Time) *env {
+	return &env{base: b, file: file, now: now, cache: map[string]any{}, active: map[string]bool{}}
+}
+
+// match reports whether the row passes f.
+func (e *env) match(f *Filter) bool {
+	if f == nil {
+		return true
+	}
+	if f.compiled != nil && !truthy(e.eval(f.compiled)) {
+		return false
+	}
+	for i := range f.And {
+		if !e.match(&f.And[i]) {
+			return false
+		}
+	}
+	if len(f.Or) > 0 {
+		matched := false
+		for i := range f.Or {
+			if e.match(&f.Or[i]) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for i := range f.Not {
+		if e.match(&f.Not[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *env) formula(name string) any {
+	if v, ok := e.cache[name]; ok {
+		return v
+	}
+	n, ok := e.base.formulas[name]
+	if !ok || e.active[name] {
+		return nil
+	}
+	e.active[name] = true
+	v := e.eval(n)
+	delete(e.active, name)
+	e.cache[name] = v
+	return v
+}
+
+func (e *env) eval(n *node) any {
+	switch n.kind {
+	case nodeLiteral:
+		return n.value
+	case nodeList:
+		out := make([]any, 0, len(n.children))
+		for _, child := range n.children {
+			out = append(out, e.eval(child))
+		}
+		return out
+	case nodeIdent:
+		switch n.name {
+		case "file":
+			return e.file
+		case "note":
+			return e.file.props
+		case "formula":
+			return formulaRef{}
+		}
+		return e.file.props[n.name]
+	case nodeMember:
+		return e.member(e.eval(n.children[0]), n.name)
+	case nodeIndex:
+		recv := e.eval(n.children[0])
+		key := e.eval(n.children[1])
+		switch x := recv.(type) {
+		case []any:
+			if f, ok := key.(float64); ok {
+				i := int(f)
+				if i < 0 {
+					i += len(x)
+				}
+				if i >= 0 && i < len(x) {
+					return x[i]
+				}
+			}
+			return nil
+		default:
+			if s, ok := key.(string); ok {
+				return e.member(recv, s)
+			}
+			return nil
+		}
+	case nodeUnary:
+		v := e.eval(n.children[0])
+		if n.op == "!" {
+			return !truthy(v)
+		}
+		if f, ok := toNumber(v); ok {
+			return -f
+		}
+		return nil
+	case nodeBinary:
+		return e.binary(n)
+	case nodeCall:
+		callee := n.children[0]
+		args := n.children[1:]
+		if callee.kind == nodeIdent {
+			return e.call(callee.name, args)
+		}
+		return e.method(e.eval(callee.children[0]), callee.name, e.evalAll(args))
+	}
+	return nil
+}
+
+func (e *env) evalAll(args []*node) []any {
+	out := make([]any, 0, len(args))
+	for _, a := range args {
+		out = append(out, e.eval(a))
+	}
+	return out
+}
+
+func (e *env) binary(n *node) any {
+	switch n.op {
+	case "&&":
+		return truthy(e.eval(n.children[0])) && truthy(e.eval(n.children[1]))
+	case "||":
+		left := e.eval(n.children[0])
+		if truthy(left) {
+			return left
+		}
+		return e.eval(n.children[1])
+	}
+	a, b := e.eval(n.children[0]), e.eval(n.children[1])
+	switch n.op {
+	case "==":
+		return equal(a, b)
+	case "!=":
+		return !equal(a, b)
+	case "<", "<=", ">", ">=":
+		if a == nil || b == nil {
+			return false
+		}
+		c := compare(a, b)
+		switch n.op {
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		default:
+			return c >= 0
+		}
+	case "+", "-":
+		sign := 1
+		if n.op == "-" {
+			sign = -1
+		}
+		if t, ok := a.(time.Time); ok {
+			if s, ok := b.(string); ok {
+				if shifted, ok := addDuration(t, s, sign); ok {
+					return shifted
+				}
+				return nil
+			}
+			if u, ok := b.(time.Time); ok && sign < 0 {
+				return float64(t.Sub(u).Milliseconds())
+			}
+		}
+		if n.op == "+" {
+			if la, ok := a.([]any); ok {
+				if lb, ok := b.([]any); ok {
+					return append(append([]any{}, la...), lb...)
+				}
+			}
+			_, sa := a.(string)
+			_, sb := b.(string)
+			if sa || sb {
+				return display(a) + display(b)
+			}
+		}
+		x, okA := toNumber(a)
+		y, okB := toNumber(b)
+		if !okA || !okB {
+			return nil
+		}
+		return x + float64(sign)*y
+	case "*", "/", "%":
+		x, okA := toNumber(a)
+		y, okB := toNumber(b)
+		if !okA || !okB {
+			return nil
+		}
+		switch n.op {
+		case "*":
+			return x * y
+		case "/":
+			if y == 0 {
+				return nil
+			}
+			return x / y
+		default:
+			if y == 0 {
+				return nil
+			}
+			return math.Mod(x, y)
+		}
+	}
+	return nil
+}
+
+func (e *env) call(name string, argNodes []*node) any {
+	if name == "if" {
+		if len(argNodes) == 0 {
+			return nil
+		}
+		if truthy(e.eval(argNodes[0])) {
+			if len(argNodes) > 1 {
+				return e.eval(argNodes[1])
+			}
+			return nil
+		}
+		if len(argNodes) > 2 {
+			return e.eval(argNodes[2])
+		}
+		return nil
+	}
+	args := e.evalAll(argNodes)
+	switch name {
+	case "today":
+		y, m, d := e.now.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	case "now":
+		return e.now.UTC()
+	case "date":
+		if len(args) > 0 {
+			if t, ok := asDate(args[0]); ok {
+				return t
+			}
+		}
+		return nil
+	case "number":
+		if len(args) > 0 {
+			if t, ok := args[0].(time.Time); ok {
+				return float64(t.UnixMilli())
+			}
+			if f, ok := toNumber(args[0]); ok {
+				return f
+			}
+		}
+		return nil
+	case "list":
+		if len(args) == 0 || args[0] == nil {
+			return []any{}
+		}
+		if l, ok := args[0].([]any); ok {
+			return l
+		}
+		return []any{args[0]}
+	case "min", "max":
+		var best any
+		for _, a := range flatten(args) {
+			if a == nil {
+				continue
+			}
+			if best == nil || (name == "min" && compare(a, best) < 0) || (name == "max" && compare(a, best) > 0) {
+				best = a
+			}
+		}
+		return best
+	}
+	return nil
+}
+
+func (e *env) member(recv any, name string) any {
+	switch x := recv.(type) {
+	case *fileObj:
+		return x.field(name)
+	case formulaRef:
+		return e.formula(name)
+	case map[string]any:
+		return x[name]
+	case string:
+		if name == "length" {
+			return float64(len([]rune(x)))
+		}
+	case []any:
+		if name == "length" {
+			return float64(len(x))
+		}
+	case time.Time:
+		switch name {
+		case "year":
+			return float64(x.Year())
+		case "month":
+			return float64(x.Month())
+		case "day":
+			return float64(x.Day())
+		case "hour":
+			return float64(x.Hour())
+		case "minute":
+			return float64(x.Minute())
+		case "second":
+			return float64(x.Second())
+		}
+	}
+	return nil
+}
+
+func (e *env) method(recv any, name string, args []any) any {
+	switch name {
+	case "isEmpty":
+		return isEmpty(recv)
+	case "isTruthy":
+		return truthy(recv)
+	case "toString":
+		return display(recv)
+	}
+	switch x := recv.(type) {
+	case *fileObj:
+		return x.method(name, args)
+	case string:
+		return stringMethod(x, name, args)
+	case []any:
+		return listMethod(x, name, args)
+	case float64:
+		return numberMethod(x, name, args)
+	case time.Time:
+		switch name {
+		case "date":
+			return time.Date(x.Year(), x.Month(), x.Day(), 0, 0, 0, 0, time.UTC)
+		case "format":
+			if len(args) > 0 {
+				return formatDate(x, display(args[0]))
+			}
+			return export(x)
+		}
+	}
+	return nil
+}
+
+func (f *fileObj) field(name string) any {
+	switch name {
+	case "name":
+		return path.Base(f.rel)
+	case "basename":
+		return strings.TrimSuffix(path.Base(f.rel), path.Ext(f.rel))
+	case "path":
+		return f.rel
+	case "folder":
+		if dir := path.Dir(f.rel); dir != "." {
+			return dir
+		}
+		return ""
+	case "ext":
+		return strings.TrimPrefix(path.Ext(f.rel), ".")
+	case "size":
+		return float64(f.entry.Size)
+	case "mtime", "ctime":
+		return time.Unix(0, f.entry.MTime).UTC()
+	case "tags":
+		out := make([]any, 0, len(f.entry.Tags))
+		for _, tag := range f.entry.Tags {
+			out = append(out, "#"+tag)
+		}
+		return out
+	case "links":
+		out := make([]any, 0, len(f.entry.Links))
+		for _, link := range f.entry.Links {
+			out = append(out, link)
+		}
+		return out
+	case "properties":
+		return f.props
+	}
+	return nil
+}
+
+func (f *fileObj) method(name string, args []any) any {
+	switch name {
+	case "hasTag":
+		for _, arg := range flatten(args) {
+			want := index.NormalizeTag(display(arg))
+			for _, tag := range f.entry.Tags {
+				if tag == want || strings.HasPrefix(tag, want+"/") {
+					return true
+				}
+			}
+		}
+		return false
+	case "inFolder":
+		if len(args) == 0 {
+			return false
+		}
+		folder := strings.Trim(strings.TrimSpace(display(args[0])), "/")
+		return folder == "" || strings.HasPrefix(f.rel, folder+"/")
+	case "hasLink":
+		if len(args) == 0 {
+			return false
+		}
+		want := linkKey(display(args[0]))
+		for _, link := range f.entry.Links {
+			got := linkKey(link)
+			if got == want || (!strings.Contains(want, "/") && path.Base(got) == want) {
+				return true
+			}
+		}
+		return false
+	case "hasProperty":
+		if len(args) == 0 {
+			return false
+		}
+		_, ok := f.props[display(args[0])]
+		return ok
+	}
+	return nil
+}
+
+// linkKey normalizes link text or a path for comparison.
+func linkKey(raw string) string {
+	s := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(raw), "[["), "]]")
+	if i := strings.IndexAny(s, "|#"); i >= 0 {
+		s = s[:i]
+	}
+	return index.NormalizeLinkTarget(s)
+}
+
+func stringMethod(s, name string, args []any) any {
+	arg := func(i int) string {
+		if i < len(args) {
+			return display(args[i])
+		}
+		return ""
+	}
+	switch name {
+	case "contains":
+		return strings.Contains(s, arg(0))
+	case "containsAll":
+		for _, a := range flatten(args) {
+			if !strings.Contains(s, display(a)) {
+				return false
+			}
+		}
+		return true
+	case "containsAny":
+		for _, a := range flatten(args) {
+			if strings.Contains(s, display(a)) {
+				return true
+			}
+		}
+		return false
+	case "startsWith":
+		return strings.HasPrefix(s, arg(0))
+	case "endsWith":
+		return strings.HasSuffix(s, arg(0))
+	case "lower":
+		return strings.ToLower(s)
+	case "upper":
+		return strings.ToUpper(s)
+	case "trim":
+		return strings.TrimSpace(s)
+	case "replace":
+		return strings.ReplaceAll(s, arg(0), arg(1))
+	case "split":
+		out := []any{}
+		for _, part := range strings.Split(s, arg(0)) {
+			out = append(out, part)
+		}
+		return out
+	}
+	return nil
+}
+
+func listMethod(list []any, name string, args []any) any {
+	contains := func(v any) bool {
+		for _, item := range list {
+			if equal(item, v) {
+				return true
+			}
+		}
+		return false
+	}
+	switch name {
+	case "contains":
+		return len(args) > 0 && contains(args[0])
+	case "containsAll":
+		for _, a := range flatten(args) {
+			if !contains(a) {
+				return false
+			}
+		}
+		return true
+	case "containsAny":
+		for _, a := range flatten(args) {
+			if contains(a) {
+				return true
+			}
+		}
+		return false
+	case "join":
+		sep := ", "
+		if len(args) > 0 {
+			sep = display(args[0])
+		}
+		parts := make([]string, 0, len(list))
+		for _, item := range list {
+			parts = append(parts, display(item))
+		}
+		return strings.Join(parts, sep)
+	case "unique":
+		out := []any{}
+		for _, item := range list {
+			dup := false
+			for _, seen := range out {
+				if equal(seen, item) {
+					dup = true
+					break
+				}
+			}
+			if !dup {
+				out = append(out, item)
+			}
+		}
+		return out
+	case "sort":
+		out := append([]any{}, list...)
+		sort.SliceStable(out, func(i, j int) bool { return compare(out[i], out[j]) < 0 })
+		return out
+	case "reverse":
+		out := make([]any, len(list))
+		for i, item := range list {
+			out[len(list)-1-i] = item
+		}
+		return out
+	}
+	return nil
+}
+
+func numberMethod(x float64, name string, args []any) any {
+	digits := 0.0
+	if len(args) > 0 {
+		digits, _ = toNumber(args[0])
+	}
+	switch name {
+	case "round":
+		p := math.Pow(10, digits)
+		return math.Round(x*p) / p
+	case "floor":
+		return math.Floor(x)
+	case "ceil":
+		return math.Ceil(x)
+	case "abs":
+		return math.Abs(x)
+	case "toFixed":
+		return strconv.FormatFloat(x, 'f', int(digits), 64)
+	}
+	return nil
+}
+
+var momentTokens = strings.NewReplacer(
+	"YYYY", "2006", "MM", "01", "DD", "02", "HH", "15", "mm", "04", "ss", "05",
+)
+
+// formatDate supports the common Moment.js tokens used in Bases formulas.
+func formatDate(t time.Time, pattern string) string {
+	return t.Format(momentTokens.Replace(pattern))
+}
+
+func flatten(args []any) []any {
+	out := []any{}
+	for _, a := range args {
+		if l, ok := a.([]any); ok {
+			out = append(out, l...)
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
@@ -0,0 +1,319 @@
+package bases
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+)
+
+type nodeKind int
+
+const (
+	nodeLiteral nodeKind = iota
+	nodeIdent
+	nodeMember
+	nodeIndex
+	nodeCall
+	nodeUnary
+	nodeBinary
+	nodeList
+)
+
+// node is a parsed Bases expression such as
+// `file.hasTag("book") && rating >= 4`.
+type node struct {
+	kind     nodeKind
+	op       string
+	name     string
+	value    any
+	children []*node
+}
+
+type token struct {
+	kind byte // 'i' ident, 'n' number, 's' string, 'o' operator, 0 EOF
+	text string
+	pos  int
+}
+
+func lexExpr(src string) ([]token, error) {
+	runes := []rune(src)
+	out := []token{}
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			quote := r
+			i++
+			var b strings.Builder
+			closed := false
+			for i < len(runes) {
+				c := runes[i]
+				i++
+				if c == '\\' && i < len(runes) {
+					b.WriteRune(runes[i])
+					i++
+					continue
+				}
+				if c == quote {
+					closed = true
+					break
+				}
+				b.WriteRune(c)
+			}
+			if !closed {
+				return nil, exprError(src, "unclosed string literal", start)
+			}
+			out = append(out, token{kind: 's', text: b.String(), pos: start})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			out = append(out, token{kind: 'n', text: string(runes[start:i]), pos: start})
+		case unicode.IsLetter(r) || r == '_' || r == '$':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '$') {
+				i++
+			}
+			out = append(out, token{kind: 'i', text: string(runes[start:i]), pos: start})
+		default:
+			start := i
+			two := ""
+			if i+1 < len(runes) {
+				two = string(runes[i : i+2])
+			}
+			switch two {
+			case "==", "!=", "<=", ">=", "&&", "||":
+				// JavaScript's strict operators mean the same thing here.
+				if i+2 < len(runes) && runes[i+2] == '=' && (two == "==" || two == "!=") {
+					i++
+				}
+				out = append(out, token{kind: 'o', text: two, pos: start})
+				i += 2
+				continue
+			}
+			if strings.ContainsRune("<>!+-*/%(),.[]", r) {
+				out = append(out, token{kind: 'o', text: string(r), pos: start})
+				i++
+				continue
+			}
+			return nil, exprError(src, "unexpected character '"+string(r)+"'", start)
+		}
+	}
+	return append(out, token{pos: len(runes)}), nil
+}
+
+type exprParser struct {
+	src    string
+	tokens []token
+	pos    int
+}
+
+// parseExpr compiles a Bases filter or formula expression.
+func parseExpr(src string) (*node, error) {
+	tokens, err := lexExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{src: src, tokens: tokens}
+	n, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != 0 {
+		return nil, exprError(src, "unexpected '"+tok.text+"'", tok.pos)
+	}
+	return n, nil
+}
+
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != 0 {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *exprParser) expect(op string) error {
+	tok := p.next()
+	if tok.kind != 'o' || tok.text != op {
+		return exprError(p.src, "expected '"+op+"'", tok.pos)
+	}
+	return nil
+}
+
+func (p *exprParser) parseBinary(minPrec int) (*node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		prec, ok := precedence[tok.text]
+		if tok.kind != 'o' || !ok || prec <= minPrec {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseBinary(prec)
+		if err != nil {
+			return nil, err
+		}
+		left = &node{kind: nodeBinary, op: tok.text, children: []*node{left, right}}
+	}
+}
+
+func (p *exprParser) parseUnary() (*node, error) {
+	tok := p.peek()
+	if tok.kind == 'o' && (tok.text == "!" || tok.text == "-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &node{kind: nodeUnary, op: tok.text, children: []*node{operand}}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *exprParser) parsePostfix() (*node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != 'o' {
+			return n, nil
+		}
+		switch tok.text {
+		case ".":
+			p.next()
+			name := p.next()
+			if name.kind != 'i' {
+				return nil, exprError(p.src, "expected a property name after '.'", name.pos)
+			}
+			n = &node{kind: nodeMember, name: name.text, children: []*node{n}}
+		case "[":
+			p.next()
+			idx, err := p.parseBinary(0)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = &node{kind: nodeIndex, children: []*node{n, idx}}
+		case "(":
+			if n.kind != nodeIdent && n.kind != nodeMember {
+				return nil, exprError(p.src, "only functions and methods can be called", tok.pos)
+			}
+			if n.kind == nodeIdent && !knownFunction(n.name) {
+				return nil, exprError(p.src, "unknown function "+n.name+"()", tok.pos)
+			}
+			p.next()
+			args, err := p.parseList(")")
+			if err != nil {
+				return nil, err
+			}
+			n = &node{kind: nodeCall, children: append([]*node{n}, args...)}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *exprParser) parseList(closing string) ([]*node, error) {
+	items := []*node{}
+	if tok := p.peek(); tok.kind == 'o' && tok.text == closing {
+		p.next()
+		return items, nil
+	}
+	for {
+		item, err := p.parseBinary(0)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		tok := p.next()
+		if tok.kind == 'o' && tok.text == closing {
+			return items, nil
+		}
+		if tok.kind != 'o' || tok.text != "," {
+			return nil, exprError(p.src, "expected ',' or '"+closing+"'", tok.pos)
+		}
+	}
+}
+
+func (p *exprParser) parsePrimary() (*node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case 'n':
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, exprError(p.src, "invalid number "+tok.text, tok.pos)
+		}
+		return &node{kind: nodeLiteral, value: f}, nil
+	case 's':
+		return &node{kind: nodeLiteral, value: tok.text}, nil
+	case 'i':
+		switch tok.text {
+		case "true":
+			return &node{kind: nodeLiteral, value: true}, nil
+		case "false":
+			return &node{kind: nodeLiteral, value: false}, nil
+		case "null":
+			return &node{kind: nodeLiteral, value: nil}, nil
+		}
+		return &node{kind: nodeIdent, name: tok.text}, nil
+	case 'o':
+		switch tok.text {
+		case "(":
+			n, err := p.parseBinary(0)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return n, nil
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return &node{kind: nodeList, children: items}, nil
+		}
+	}
+	if tok.kind == 0 {
+		return nil, exprError(p.src, "unexpected end of expression", tok.pos)
+	}
+	return nil, exprError(p.src, "unexpected '"+tok.text+"'", tok.pos)
+}
+
+func exprError(src, msg string, pos int) error {
+	return errs.NewDetailed(
+		errs.ExitValidation,
+		"invalid_base_expression",
+		"Check the filter or formula against the Bases expression syntax, e.g. file.hasTag(\"x\") && status != \"done\".",
+		"base expression "+strconv.Quote(src)+": "+msg+" at offset "+strconv.Itoa(pos),
+	)
+}
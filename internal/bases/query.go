@@ -0,0 +1,177 @@
+package bases
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/frontmatter"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+)
+
+// Column is a property shown by a view, with its configured label.
+type Column struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Result is a view evaluated against the vault. Rows are keyed by column id
+// plus "path", and "group" when the view groups rows.
+type Result struct {
+	Base    string           `json:"base"`
+	View    string           `json:"view"`
+	Type    string           `json:"type"`
+	GroupBy string           `json:"group_by,omitempty"`
+	Columns []Column         `json:"columns"`
+	Rows    []map[string]any `json:"rows"`
+}
+
+// QueryOptions selects the view and overrides its limit when Limit > 0.
+type QueryOptions struct {
+	View  string
+	Limit int
+	Now   time.Time
+}
+
+// Query evaluates a view of b over every indexed note. Note properties are
+// read with the frontmatter parser so YAML dates and numbers keep their
+// types.
+func Query(vaultRoot string, b *Base, snap store.Snapshot, opts QueryOptions) (Result, error) {
+	view, err := b.View(opts.View)
+	if err != nil {
+		return Result{}, err
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	order := view.Order
+	if len(order) == 0 {
+		order = []string{"file.name"}
+	}
+	res := Result{Base: b.Path, View: view.Name, Type: view.Type, Columns: []Column{}, Rows: []map[string]any{}}
+	for _, id := range order {
+		res.Columns = append(res.Columns, Column{ID: id, Name: b.DisplayName(id)})
+	}
+	if view.GroupBy != nil && strings.TrimSpace(view.GroupBy.Property) != "" {
+		res.GroupBy = view.GroupBy.Property
+	}
+
+	type row struct {
+		out  map[string]any
+		keys []any
+	}
+	sorts := view.Sort
+	if res.GroupBy != "" {
+		sorts = append([]SortSpec{*view.GroupBy}, sorts...)
+	}
+	rows := []row{}
+	for _, rel := range snap.Paths() {
+		raw, err := os.ReadFile(filepath.Join(vaultRoot, filepath.FromSlash(rel)))
+		if err != nil {
+			return Result{}, errs.Wrap(errs.ExitGeneric, "failed to read note "+rel, err)
+		}
+		props, _, _, err := frontmatter.ParseDocument(string(raw))
+		if err != nil {
+			return Result{}, errs.Wrap(errs.ExitGeneric, "failed to parse note "+rel, err)
+		}
+		file := &fileObj{rel: rel, entry: snap.Files[rel], props: normalize(props).(map[string]any)}
+		e := newEnv(b, file, now)
+		if !e.match(b.Filters) || !e.match(view.Filters) {
+			continue
+		}
+		out := map[string]any{"path": rel}
+		for _, col := range res.Columns {
+			out[col.ID] = export(e.eval(propertyNode(col.ID)))
+		}
+		keys := make([]any, len(sorts))
+		for i, s := range sorts {
+			keys[i] = e.eval(propertyNode(s.Property))
+		}
+		if res.GroupBy != "" {
+			out["group"] = export(keys[0])
+		}
+		rows = append(rows, row{out: out, keys: keys})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		for k, s := range sorts {
+			a, b := rows[i].keys[k], rows[j].keys[k]
+			// Empty values sort last in either direction.
+			if a == nil || b == nil {
+				if (a == nil) != (b == nil) {
+					return b == nil
+				}
+				continue
+			}
+			c := compare(a, b)
+			if c == 0 {
+				continue
+			}
+			if s.Desc() {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+	limit := view.Limit
+	if opts.Limit > 0 {
+		limit = opts.Limit
+	}
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+	for _, r := range rows {
+		res.Rows = append(res.Rows, r.out)
+	}
+	return res, nil
+}
+
+// propertyNode builds the lookup for a property id: "file.*", "formula.*",
+// "note.*" or a bare note property, which may contain spaces or dashes.
+func propertyNode(id string) *node {
+	ns, name := "note", strings.TrimSpace(id)
+	for _, prefix := range []string{"file", "formula", "note"} {
+		if strings.HasPrefix(name, prefix+".") {
+			ns, name = prefix, strings.TrimPrefix(name, prefix+".")
+			break
+		}
+	}
+	return &node{kind: nodeMember, name: name, children: []*node{{kind: nodeIdent, name: ns}}}
+}
+
+// WriteCSV writes the result with a header of path, group (when grouped)
+// and the column display names.
+func WriteCSV(w io.Writer, res Result) error {
+	writer := csv.NewWriter(w)
+	header := []string{"path"}
+	if res.GroupBy != "" {
+		header = append(header, "group")
+	}
+	for _, col := range res.Columns {
+		header = append(header, col.Name)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, r := range res.Rows {
+		record := []string{display(r["path"])}
+		if res.GroupBy != "" {
+			record = append(record, display(r["group"]))
+		}
+		for _, col := range res.Columns {
+			record = append(record, display(r[col.ID]))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
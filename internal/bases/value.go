@@ -0,0 +1,328 @@
+package bases
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+var durationPattern = regexp.MustCompile(`(-?\d+(?:\.\d+)?)\s*([A-Za-z]+)`)
+
+// normalize converts decoded frontmatter values to the evaluator's value
+// types: float64 numbers, UTC dates and []any lists.
+func normalize(v any) any {
+	switch x := v.(type) {
+	case int:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case uint64:
+		return float64(x)
+	case float32:
+		return float64(x)
+	case time.Time:
+		return x.UTC()
+	case []any:
+		out := make([]any, 0, len(x))
+		for _, item := range x {
+			out = append(out, normalize(item))
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, item := range x {
+			out[k] = normalize(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func parseDate(raw string) (time.Time, bool) {
+	s := strings.TrimSpace(raw)
+	if len(s) < 10 || s[4] != '-' {
+		return time.Time{}, false
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func asDate(v any) (time.Time, bool) {
+	switch x := v.(type) {
+	case time.Time:
+		return x, true
+	case string:
+		return parseDate(x)
+	}
+	return time.Time{}, false
+}
+
+// addDuration shifts t by a Bases duration string such as "1d", "2 weeks"
+// or "-3M"; months and years follow the calendar.
+func addDuration(t time.Time, raw string, sign int) (time.Time, bool) {
+	matches := durationPattern.FindAllStringSubmatch(raw, -1)
+	if len(matches) == 0 {
+		return time.Time{}, false
+	}
+	for _, m := range matches {
+		n, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		n *= float64(sign)
+		unit := m[2]
+		if unit != "M" {
+			unit = strings.ToLower(unit)
+		}
+		switch unit {
+		case "y", "year", "years":
+			t = t.AddDate(int(n), 0, 0)
+		case "M", "month", "months":
+			t = t.AddDate(0, int(n), 0)
+		case "w", "week", "weeks":
+			t = t.AddDate(0, 0, int(n*7))
+		case "d", "day", "days":
+			t = t.AddDate(0, 0, int(n))
+		case "h", "hour", "hours":
+			t = t.Add(time.Duration(n * float64(time.Hour)))
+		case "m", "minute", "minutes":
+			t = t.Add(time.Duration(n * float64(time.Minute)))
+		case "s", "second", "seconds":
+			t = t.Add(time.Duration(n * float64(time.Second)))
+		default:
+			return time.Time{}, false
+		}
+	}
+	return t, true
+}
+
+func toNumber(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case bool:
+		if x {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func truthy(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	case float64:
+		return x != 0 && !math.IsNaN(x)
+	case string:
+		return x != ""
+	case []any:
+		return len(x) > 0
+	case map[string]any:
+		return len(x) > 0
+	case time.Time:
+		return !x.IsZero()
+	default:
+		return true
+	}
+}
+
+func isEmpty(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(x) == ""
+	case []any:
+		return len(x) == 0
+	case map[string]any:
+		return len(x) == 0
+	}
+	return false
+}
+
+func typeRank(v any) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case float64:
+		return 2
+	case time.Time:
+		return 3
+	case string:
+		return 4
+	case []any:
+		return 5
+	default:
+		return 6
+	}
+}
+
+// compare orders two values. Strings that look like dates compare as
+// dates against date values; other mixed types order by type.
+func compare(a, b any) int {
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := asDate(b); ok {
+			return ta.Compare(tb)
+		}
+	}
+	if tb, ok := b.(time.Time); ok {
+		if ta, ok := asDate(a); ok {
+			return ta.Compare(tb)
+		}
+	}
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return cmpInt(ra, rb)
+	}
+	switch x := a.(type) {
+	case nil:
+		return 0
+	case bool:
+		y := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		default:
+			return 1
+		}
+	case float64:
+		y := b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		default:
+			return 0
+		}
+	case string:
+		return strings.Compare(strings.ToLower(x), strings.ToLower(b.(string)))
+	case []any:
+		y := b.([]any)
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := compare(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return cmpInt(len(x), len(y))
+	default:
+		return strings.Compare(display(a), display(b))
+	}
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func equal(a, b any) bool {
+	if s, ok := a.(string); ok {
+		if t, ok := b.(string); ok {
+			return s == t
+		}
+	}
+	if _, ok := a.(time.Time); !ok {
+		if _, ok := b.(time.Time); !ok && typeRank(a) != typeRank(b) {
+			return false
+		}
+	}
+	return compare(a, b) == 0
+}
+
+// export turns evaluator values into JSON-friendly output.
+func export(v any) any {
+	switch x := v.(type) {
+	case time.Time:
+		if x.Hour() == 0 && x.Minute() == 0 && x.Second() == 0 && x.Nanosecond() == 0 {
+			return x.Format("2006-01-02")
+		}
+		return x.Format(time.RFC3339)
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return nil
+		}
+		return x
+	case *fileObj:
+		return x.rel
+	case []any:
+		out := make([]any, 0, len(x))
+		for _, item := range x {
+			out = append(out, export(item))
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, item := range x {
+			out[k] = export(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// display renders a value as a single CSV/text cell.
+func display(v any) string {
+	switch x := export(v).(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case []any:
+		parts := make([]string, 0, len(x))
+		for _, item := range x {
+			parts = append(parts, display(item))
+		}
+		return strings.Join(parts, ", ")
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, k+": "+display(x[k]))
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(x)
+	}
+}
@@ -0,0 +1,318 @@
+package canvas
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/history"
+	"github.com/nightisyang/obsidian-cli/internal/index"
+)
+
+// Ext is the JSON Canvas file extension.
+const Ext = ".canvas"
+
+// Node types defined by JSON Canvas 1.0.
+const (
+	NodeText  = "text"
+	NodeFile  = "file"
+	NodeLink  = "link"
+	NodeGroup = "group"
+)
+
+const (
+	defaultWidth  = 400
+	defaultHeight = 200
+	nodeGap       = 40
+)
+
+// Canvas is a JSON Canvas document.
+type Canvas struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+type Node struct {
+	ID              string `json:"id"`
+	Type            string `json:"type"`
+	X               int    `json:"x"`
+	Y               int    `json:"y"`
+	Width           int    `json:"width"`
+	Height          int    `json:"height"`
+	Color           string `json:"color,omitempty"`
+	Text            string `json:"text,omitempty"`
+	File            string `json:"file,omitempty"`
+	Subpath         string `json:"subpath,omitempty"`
+	URL             string `json:"url,omitempty"`
+	Label           string `json:"label,omitempty"`
+	Background      string `json:"background,omitempty"`
+	BackgroundStyle string `json:"backgroundStyle,omitempty"`
+}
+
+type Edge struct {
+	ID       string `json:"id"`
+	FromNode string `json:"fromNode"`
+	FromSide string `json:"fromSide,omitempty"`
+	FromEnd  string `json:"fromEnd,omitempty"`
+	ToNode   string `json:"toNode"`
+	ToSide   string `json:"toSide,omitempty"`
+	ToEnd    string `json:"toEnd,omitempty"`
+	Color    string `json:"color,omitempty"`
+	Label    string `json:"label,omitempty"`
+}
+
+// Parse decodes a canvas; an empty file is an empty canvas.
+func Parse(data []byte) (Canvas, error) {
+	c := Canvas{}
+	if strings.TrimSpace(string(data)) != "" {
+		if err := json.Unmarshal(data, &c); err != nil {
+			return Canvas{}, errs.WrapDetailed(
+				errs.ExitValidation,
+				"invalid_canvas",
+				"Check the file is valid JSON Canvas with nodes and edges arrays.",
+				"failed to parse canvas",
+				err,
+			)
+		}
+	}
+	if c.Nodes == nil {
+		c.Nodes = []Node{}
+	}
+	if c.Edges == nil {
+		c.Edges = []Edge{}
+	}
+	return c, nil
+}
+
+// NormalizePath cleans a vault-relative canvas path and adds the extension.
+func NormalizePath(rel string) string {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(strings.ReplaceAll(rel, "\\", "/")), "/")
+	if !strings.HasSuffix(strings.ToLower(trimmed), Ext) {
+		trimmed += Ext
+	}
+	return filepath.ToSlash(filepath.Clean(trimmed))
+}
+
+func resolve(vaultRoot, rel string) (string, string, error) {
+	normalized := NormalizePath(rel)
+	abs := filepath.Join(vaultRoot, filepath.FromSlash(normalized))
+	if !strings.HasPrefix(abs, filepath.Clean(vaultRoot)+string(filepath.Separator)) {
+		return "", "", errs.New(errs.ExitValidation, "path escapes vault root")
+	}
+	return abs, normalized, nil
+}
+
+// Load reads a canvas and returns it with its normalized path.
+func Load(vaultRoot, rel string) (Canvas, string, error) {
+	abs, normalized, err := resolve(vaultRoot, rel)
+	if err != nil {
+		return Canvas{}, "", err
+	}
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Canvas{}, normalized, errs.New(errs.ExitNotFound, "canvas not found: "+normalized)
+		}
+		return Canvas{}, normalized, errs.Wrap(errs.ExitGeneric, "failed to read canvas", err)
+	}
+	c, err := Parse(data)
+	return c, normalized, err
+}
+
+// Save writes c tab-indented, as Obsidian does, creating parent folders.
+func Save(vaultRoot string, hist *history.Store, rel string, c Canvas) error {
+	abs, _, err := resolve(vaultRoot, rel)
+	if err != nil {
+		return err
+	}
+	payload, err := c.Marshal()
+	if err != nil {
+		return errs.Wrap(errs.ExitGeneric, "failed to encode canvas", err)
+	}
+	if err := hist.WriteFile(abs, payload); err != nil {
+		return errs.Wrap(errs.ExitGeneric, "failed to write canvas", err)
+	}
+	return nil
+}
+
+func (c Canvas) Marshal() ([]byte, error) {
+	if c.Nodes == nil {
+		c.Nodes = []Node{}
+	}
+	if c.Edges == nil {
+		c.Edges = []Edge{}
+	}
+	return json.MarshalIndent(c, "", "\t")
+}
+
+// List returns the vault-relative path of every canvas.
+func List(vaultRoot string) ([]string, error) {
+	files, err := index.ListCanvasFiles(vaultRoot)
+	if err != nil {
+		return nil, errs.Wrap(errs.ExitGeneric, "failed to scan vault for canvases", err)
+	}
+	out := make([]string, 0, len(files))
+	for _, abs := range files {
+		rel, err := filepath.Rel(vaultRoot, abs)
+		if err != nil {
+			return nil, errs.Wrap(errs.ExitGeneric, "failed to scan vault for canvases", err)
+		}
+		out = append(out, filepath.ToSlash(rel))
+	}
+	return out, nil
+}
+
+// AddNode appends n, assigning a stable ID when none is given, a default
+// size, and a slot below the existing nodes when it has no position.
+func (c *Canvas) AddNode(n Node) (Node, error) {
+	switch n.Type {
+	case NodeText, NodeFile, NodeLink, NodeGroup:
+	default:
+		return Node{}, errs.New(errs.ExitValidation, "node type must be text, file, link or group")
+	}
+	if n.Width <= 0 {
+		n.Width = defaultWidth
+	}
+	if n.Height <= 0 {
+		n.Height = defaultHeight
+	}
+	if n.X == 0 && n.Y == 0 && len(c.Nodes) > 0 {
+		minX, maxY := c.Nodes[0].X, c.Nodes[0].Y+c.Nodes[0].Height
+		for _, existing := range c.Nodes[1:] {
+			if existing.X < minX {
+				minX = existing.X
+			}
+			if bottom := existing.Y + existing.Height; bottom > maxY {
+				maxY = bottom
+			}
+		}
+		n.X, n.Y = minX, maxY+nodeGap
+	}
+	if n.ID == "" {
+		n.ID = c.newID("node", n.Type, n.Text, n.File, n.URL, n.Label)
+	} else if c.hasID(n.ID) {
+		return Node{}, errs.New(errs.ExitValidation, "id already exists: "+n.ID)
+	}
+	c.Nodes = append(c.Nodes, n)
+	return n, nil
+}
+
+// AddEdge appends e after checking both ends exist.
+func (c *Canvas) AddEdge(e Edge) (Edge, error) {
+	for _, id := range []string{e.FromNode, e.ToNode} {
+		if c.Node(id) == nil {
+			return Edge{}, errs.New(errs.ExitNotFound, "node not found: "+id)
+		}
+	}
+	if e.ID == "" {
+		e.ID = c.newID("edge", e.FromNode, e.ToNode, e.Label)
+	} else if c.hasID(e.ID) {
+		return Edge{}, errs.New(errs.ExitValidation, "id already exists: "+e.ID)
+	}
+	c.Edges = append(c.Edges, e)
+	return e, nil
+}
+
+// Node returns the node with id, or nil.
+func (c *Canvas) Node(id string) *Node {
+	for i := range c.Nodes {
+		if c.Nodes[i].ID == id {
+			return &c.Nodes[i]
+		}
+	}
+	return nil
+}
+
+func (c *Canvas) hasID(id string) bool {
+	if c.Node(id) != nil {
+		return true
+	}
+	for _, e := range c.Edges {
+		if e.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// newID derives a 16-hex-digit ID from the element's content so the same
+// edit produces the same ID, bumping a counter on collision.
+func (c *Canvas) newID(parts ...string) string {
+	seed := strings.Join(parts, "\x00")
+	for i := 0; ; i++ {
+		digest := sha256.Sum256([]byte(seed + "\x00" + strconv.Itoa(i)))
+		id := hex.EncodeToString(digest[:8])
+		if !c.hasID(id) {
+			return id
+		}
+	}
+}
+
+// Files returns the vault paths referenced by file nodes.
+func (c Canvas) Files() []string {
+	seen := map[string]struct{}{}
+	out := []string{}
+	for _, n := range c.Nodes {
+		if n.Type != NodeFile || strings.TrimSpace(n.File) == "" {
+			continue
+		}
+		file := filepath.ToSlash(filepath.Clean(strings.TrimPrefix(n.File, "/")))
+		if _, ok := seen[file]; ok {
+			continue
+		}
+		seen[file] = struct{}{}
+		out = append(out, file)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// TextLinks returns the normalized link targets found in text nodes.
+func (c Canvas) TextLinks() []string {
+	seen := map[string]struct{}{}
+	out := []string{}
+	for _, n := range c.Nodes {
+		if n.Type != NodeText {
+			continue
+		}
+		for _, target := range index.LinkTargets("", n.Text) {
+			if _, ok := seen[target]; ok {
+				continue
+			}
+			seen[target] = struct{}{}
+			out = append(out, target)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Links returns normalized link targets for file nodes and text-node
+// links, in the form the backlink index keys on.
+func (c Canvas) Links() []string {
+	seen := map[string]struct{}{}
+	out := []string{}
+	for _, file := range c.Files() {
+		target := index.NormalizeLinkTarget(file)
+		if _, ok := seen[target]; ok || target == "" {
+			continue
+		}
+		seen[target] = struct{}{}
+		out = append(out, target)
+	}
+	for _, target := range c.TextLinks() {
+		if _, ok := seen[target]; ok {
+			continue
+		}
+		seen[target] = struct{}{}
+		out = append(out, target)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,79 @@
+package canvas
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAddNodesAndEdgesRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	c := Canvas{}
+	text, err := c.AddNode(Node{Type: NodeText, Text: "See [[Alpha|a]] and [[notes/beta#Top]]"})
+	if err != nil {
+		t.Fatalf("AddNode text: %v", err)
+	}
+	file, err := c.AddNode(Node{Type: NodeFile, File: "projects/Plan.md"})
+	if err != nil {
+		t.Fatalf("AddNode file: %v", err)
+	}
+	if len(text.ID) != 16 || text.ID == file.ID {
+		t.Fatalf("unexpected ids: %q %q", text.ID, file.ID)
+	}
+	if file.X != 0 || file.Y != text.Y+text.Height+nodeGap || file.Width != defaultWidth {
+		t.Fatalf("unexpected placement: %+v", file)
+	}
+	if _, err := c.AddNode(Node{Type: "sticker"}); err == nil {
+		t.Fatalf("expected invalid type error")
+	}
+	if _, err := c.AddNode(Node{Type: NodeText, Text: "x", ID: text.ID}); err == nil {
+		t.Fatalf("expected duplicate id error")
+	}
+	edge, err := c.AddEdge(Edge{FromNode: text.ID, ToNode: file.ID, Label: "plans"})
+	if err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	if _, err := c.AddEdge(Edge{FromNode: text.ID, ToNode: "missing"}); err == nil {
+		t.Fatalf("expected missing node error")
+	}
+
+	if err := Save(root, nil, "boards/main", c); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(root, "boards", "main.canvas"))
+	if err != nil || !strings.Contains(string(raw), "\n\t\"nodes\"") {
+		t.Fatalf("expected tab-indented canvas, got %q (%v)", raw, err)
+	}
+	loaded, rel, err := Load(root, "boards/main.canvas")
+	if err != nil || rel != "boards/main.canvas" {
+		t.Fatalf("Load = %q, %v", rel, err)
+	}
+	if !reflect.DeepEqual(loaded, c) || loaded.Edges[0].ID != edge.ID {
+		t.Fatalf("round trip changed canvas: %+v", loaded)
+	}
+	if got := loaded.Links(); !reflect.DeepEqual(got, []string{"alpha", "notes/beta", "projects/plan"}) {
+		t.Fatalf("unexpected links: %v", got)
+	}
+	if got := loaded.Files(); !reflect.DeepEqual(got, []string{"projects/Plan.md"}) {
+		t.Fatalf("unexpected files: %v", got)
+	}
+
+	// The same edit on the same canvas yields the same ID.
+	again := Canvas{}
+	if n, _ := again.AddNode(Node{Type: NodeText, Text: "See [[Alpha|a]] and [[notes/beta#Top]]"}); n.ID != text.ID {
+		t.Fatalf("expected stable id, got %q vs %q", n.ID, text.ID)
+	}
+
+	paths, err := List(root)
+	if err != nil || !reflect.DeepEqual(paths, []string{"boards/main.canvas"}) {
+		t.Fatalf("List = %v, %v", paths, err)
+	}
+	if _, _, err := Load(root, "missing"); err == nil {
+		t.Fatalf("expected not found error")
+	}
+	if _, err := Parse([]byte("{not json")); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
@@ -0,0 +1,142 @@
+package dql
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/store"
+)
+
+func testSnapshot(t *testing.T) store.Snapshot {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"projects/alpha.md":   "---\nstatus: active\npriority: 3\ndue: 2024-05-01\ntags: [project]\n---\n# Alpha\nowner:: ana\n- [ ] draft spec [due:: 2024-04-01]\n- [x] kickoff\nSee [[beta]].\n",
+		"projects/beta.md":    "---\nstatus: done\npriority: 1\ndue: 2024-03-01\ntags: [project, archive/2024]\n---\n# Beta\n- [ ] follow up\n",
+		"projects/gamma.md":   "---\nstatus: active\npriority: 5\ntags: [project]\n---\nLinks to [[alpha]] and [[beta]].\n",
+		"daily/2024-04-02.md": "Worked on [[alpha]].\n- [ ] call bo\n",
+	}
+	for rel, content := range files {
+		abs := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(abs, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+	snap, _, err := store.Open(root, "").Refresh()
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	return snap
+}
+
+func runQuery(t *testing.T, snap store.Snapshot, src string) Result {
+	t.Helper()
+	q, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse(%q) error: %v", src, err)
+	}
+	return Execute(q, snap, time.Date(2024, 4, 15, 9, 0, 0, 0, time.UTC))
+}
+
+func column(res Result, name string) []any {
+	out := []any{}
+	for _, row := range res.Rows {
+		out = append(out, row[name])
+	}
+	return out
+}
+
+func TestTableWhereSortLimit(t *testing.T) {
+	snap := testSnapshot(t)
+	res := runQuery(t, snap, `TABLE status, priority AS "P", file.name FROM #project WHERE status = "active" SORT priority DESC LIMIT 5`)
+	if !reflect.DeepEqual(res.Columns, []string{"path", "status", "P", "file.name"}) {
+		t.Fatalf("unexpected columns: %v", res.Columns)
+	}
+	if got := column(res, "path"); !reflect.DeepEqual(got, []any{"projects/gamma.md", "projects/alpha.md"}) {
+		t.Fatalf("unexpected rows: %v", got)
+	}
+	if res.Rows[0]["P"] != 5.0 || res.Rows[1]["file.name"] != "alpha" {
+		t.Fatalf("unexpected values: %+v", res.Rows)
+	}
+}
+
+func TestListFromFolderAndLinks(t *testing.T) {
+	snap := testSnapshot(t)
+	cases := map[string][]any{
+		`LIST FROM "projects" AND -#archive`:          {"projects/alpha.md", "projects/gamma.md"},
+		`LIST FROM [[alpha]]`:                         {"daily/2024-04-02.md", "projects/gamma.md"},
+		`LIST FROM outgoing([[gamma]])`:               {"projects/alpha.md", "projects/beta.md"},
+		`LIST WHERE due < date(today) SORT due`:       {"projects/beta.md"},
+		`LIST WHERE owner = "ana"`:                    {"projects/alpha.md"},
+		`LIST WHERE file.day = date("2024-04-02")`:    {"daily/2024-04-02.md"},
+		`LIST WHERE contains(file.tags, "#archive")`:  {"projects/beta.md"},
+		`LIST WHERE length(file.inlinks) >= 2`:        {"projects/alpha.md", "projects/beta.md"},
+		`LIST WHERE due > date(today) - dur(30 days)`: {"projects/alpha.md"},
+	}
+	for src, want := range cases {
+		if got := column(runQuery(t, snap, src), "path"); !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: got %v, want %v", src, got, want)
+		}
+	}
+
+	res := runQuery(t, snap, `LIST WITHOUT ID file.name + ": " + status FROM #project SORT file.name`)
+	if got := column(res, "value"); !reflect.DeepEqual(got, []any{"alpha: active", "beta: done", "gamma: active"}) {
+		t.Fatalf("unexpected list values: %v", got)
+	}
+	if _, ok := res.Rows[0]["path"]; ok {
+		t.Fatalf("expected WITHOUT ID to drop path")
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	snap := testSnapshot(t)
+	res := runQuery(t, snap, `TABLE length(rows) AS count, rows.file.name AS names FROM #project GROUP BY status SORT key`)
+	if !res.Grouped || !reflect.DeepEqual(res.Columns, []string{"key", "count", "names"}) {
+		t.Fatalf("unexpected grouped result: %+v", res)
+	}
+	if len(res.Rows) != 2 || res.Rows[0]["key"] != "active" || res.Rows[0]["count"] != 2.0 {
+		t.Fatalf("unexpected groups: %+v", res.Rows)
+	}
+	if !reflect.DeepEqual(res.Rows[0]["names"], []any{"alpha", "gamma"}) {
+		t.Fatalf("unexpected group names: %v", res.Rows[0]["names"])
+	}
+}
+
+func TestTaskQuery(t *testing.T) {
+	snap := testSnapshot(t)
+	res := runQuery(t, snap, `TASK FROM "projects" WHERE !completed SORT file.name`)
+	if got := column(res, "text"); !reflect.DeepEqual(got, []any{"draft spec [due:: 2024-04-01]", "follow up"}) {
+		t.Fatalf("unexpected tasks: %v", got)
+	}
+	res = runQuery(t, snap, `TASK WHERE due < date(today)`)
+	if len(res.Rows) != 1 || res.Rows[0]["path"] != "projects/alpha.md" || res.Rows[0]["line"] != 9.0 {
+		t.Fatalf("expected only the task with an overdue inline date: %+v", res.Rows)
+	}
+	res = runQuery(t, snap, `TASK WHERE !completed GROUP BY file.folder`)
+	if !res.Grouped || len(res.Rows) != 2 {
+		t.Fatalf("unexpected grouped tasks: %+v", res.Rows)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"SELECT x",
+		"LIST FROM",
+		"TABLE a WHERE",
+		"LIST LIMIT x",
+		"LIST WHERE nosuchfn(a)",
+		`LIST WHERE a = "open`,
+		"LIST WHERE a FROM #x",
+	} {
+		if _, err := Parse(src); err == nil {
+			t.Fatalf("expected parse error for %q", src)
+		}
+	}
+}
@@ -0,0 +1,586 @@
+package dql
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// env is the evaluation scope for one row.
+type env struct {
+	row   map[string]any
+	vault *vaultData
+}
+
+type function func(e env, args []any) any
+
+var numberPattern = regexp.MustCompile(`-?\d+(\.\d+)?`)
+
+var functions map[string]function
+
+func init() {
+	functions = map[string]function{
+		"contains":   func(_ env, a []any) any { return arity(a, 2) && containsValue(a[0], a[1], false, true) },
+		"icontains":  func(_ env, a []any) any { return arity(a, 2) && containsValue(a[0], a[1], true, true) },
+		"econtains":  func(_ env, a []any) any { return arity(a, 2) && containsValue(a[0], a[1], false, false) },
+		"length":     fnLength,
+		"lower":      stringFn(strings.ToLower),
+		"upper":      stringFn(strings.ToUpper),
+		"startswith": func(_ env, a []any) any { return arity(a, 2) && strings.HasPrefix(str(a[0]), str(a[1])) },
+		"endswith":   func(_ env, a []any) any { return arity(a, 2) && strings.HasSuffix(str(a[0]), str(a[1])) },
+		"replace": func(_ env, a []any) any {
+			if !arity(a, 3) {
+				return nil
+			}
+			return strings.ReplaceAll(str(a[0]), str(a[1]), str(a[2]))
+		},
+		"regextest": func(_ env, a []any) any {
+			if !arity(a, 2) {
+				return false
+			}
+			re, err := regexp.Compile(str(a[0]))
+			return err == nil && re.MatchString(str(a[1]))
+		},
+		"join": func(_ env, a []any) any {
+			if len(a) == 0 {
+				return nil
+			}
+			sep := ", "
+			if len(a) > 1 {
+				sep = str(a[1])
+			}
+			parts := []string{}
+			for _, item := range asList(a[0]) {
+				parts = append(parts, display(item))
+			}
+			return strings.Join(parts, sep)
+		},
+		"default": func(_ env, a []any) any {
+			if !arity(a, 2) {
+				return nil
+			}
+			if a[0] == nil {
+				return a[1]
+			}
+			return a[0]
+		},
+		"choice": func(_ env, a []any) any {
+			if !arity(a, 3) {
+				return nil
+			}
+			if truthy(a[0]) {
+				return a[1]
+			}
+			return a[2]
+		},
+		"round": func(_ env, a []any) any {
+			if len(a) == 0 {
+				return nil
+			}
+			n, ok := a[0].(float64)
+			if !ok {
+				return nil
+			}
+			digits := 0.0
+			if len(a) > 1 {
+				digits, _ = a[1].(float64)
+			}
+			scale := math.Pow(10, digits)
+			return math.Round(n*scale) / scale
+		},
+		"min":  func(_ env, a []any) any { return extreme(a, -1) },
+		"max":  func(_ env, a []any) any { return extreme(a, 1) },
+		"sum":  fnSum,
+		"list": func(_ env, a []any) any { return append([]any{}, a...) },
+		"number": func(_ env, a []any) any {
+			if len(a) == 0 {
+				return nil
+			}
+			if n, ok := a[0].(float64); ok {
+				return n
+			}
+			m := numberPattern.FindString(str(a[0]))
+			if m == "" {
+				return nil
+			}
+			n, _ := strconv.ParseFloat(m, 64)
+			return n
+		},
+		"string": func(_ env, a []any) any {
+			if len(a) == 0 {
+				return nil
+			}
+			return display(a[0])
+		},
+		"typeof": func(_ env, a []any) any {
+			if len(a) == 0 {
+				return nil
+			}
+			return typeName(a[0])
+		},
+		"date":      fnDate,
+		"dur":       fnDur,
+		"striptime": fnStripTime,
+	}
+}
+
+func arity(args []any, n int) bool {
+	return len(args) >= n
+}
+
+func str(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	if v == nil {
+		return ""
+	}
+	return display(v)
+}
+
+func stringFn(fn func(string) string) function {
+	return func(_ env, a []any) any {
+		if len(a) == 0 || a[0] == nil {
+			return nil
+		}
+		return fn(str(a[0]))
+	}
+}
+
+func asList(v any) []any {
+	if list, ok := v.([]any); ok {
+		return list
+	}
+	if v == nil {
+		return []any{}
+	}
+	return []any{v}
+}
+
+// containsValue follows Dataview: strings match by substring (recursively
+// inside lists) unless exact is requested, maps by key.
+func containsValue(haystack, needle any, fold, substring bool) bool {
+	switch h := haystack.(type) {
+	case string:
+		n := str(needle)
+		if fold {
+			return strings.Contains(strings.ToLower(h), strings.ToLower(n))
+		}
+		return strings.Contains(h, n)
+	case []any:
+		for _, item := range h {
+			if s, ok := item.(string); ok && substring {
+				if containsValue(s, needle, fold, true) {
+					return true
+				}
+				continue
+			}
+			if fold {
+				if strings.EqualFold(display(item), display(needle)) {
+					return true
+				}
+				continue
+			}
+			if equal(item, needle) {
+				return true
+			}
+		}
+		return false
+	case map[string]any:
+		_, ok := lookup(h, str(needle))
+		return ok
+	default:
+		return haystack != nil && equal(haystack, needle)
+	}
+}
+
+func fnLength(_ env, a []any) any {
+	if len(a) == 0 {
+		return 0.0
+	}
+	switch x := a[0].(type) {
+	case []any:
+		return float64(len(x))
+	case string:
+		return float64(len([]rune(x)))
+	case map[string]any:
+		return float64(len(x))
+	case nil:
+		return 0.0
+	default:
+		return 1.0
+	}
+}
+
+func extreme(args []any, dir int) any {
+	items := args
+	if len(args) == 1 {
+		items = asList(args[0])
+	}
+	var best any
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		if best == nil || compare(item, best)*dir > 0 {
+			best = item
+		}
+	}
+	return best
+}
+
+func fnSum(_ env, a []any) any {
+	if len(a) == 0 {
+		return nil
+	}
+	var total any
+	for _, item := range asList(a[0]) {
+		if item == nil {
+			continue
+		}
+		if total == nil {
+			total = item
+			continue
+		}
+		total = arithmetic("+", total, item)
+	}
+	return total
+}
+
+func fnDate(e env, a []any) any {
+	if len(a) == 0 {
+		return nil
+	}
+	switch x := a[0].(type) {
+	case time.Time:
+		return x
+	case Link:
+		if t, ok := dayFromName(x.Path); ok {
+			return t
+		}
+		return nil
+	case string:
+		now := e.vault.now
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		switch strings.ToLower(strings.TrimSpace(x)) {
+		case "today":
+			return today
+		case "now":
+			return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second(), 0, time.UTC)
+		case "tomorrow":
+			return today.AddDate(0, 0, 1)
+		case "yesterday":
+			return today.AddDate(0, 0, -1)
+		case "sow":
+			return today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		case "eow":
+			return today.AddDate(0, 0, 6-((int(today.Weekday())+6)%7))
+		case "som":
+			return time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+		case "eom":
+			return time.Date(today.Year(), today.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+		case "soy":
+			return time.Date(today.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		case "eoy":
+			return time.Date(today.Year(), 12, 31, 0, 0, 0, 0, time.UTC)
+		}
+		if t, ok := parseDate(x); ok {
+			return t
+		}
+	}
+	return nil
+}
+
+func fnDur(_ env, a []any) any {
+	if len(a) == 0 {
+		return nil
+	}
+	switch x := a[0].(type) {
+	case time.Duration:
+		return x
+	case string:
+		if d, ok := parseDuration(x); ok {
+			return d
+		}
+	}
+	return nil
+}
+
+func fnStripTime(_ env, a []any) any {
+	if len(a) == 0 {
+		return nil
+	}
+	t, ok := a[0].(time.Time)
+	if !ok {
+		return nil
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case time.Time:
+		return "date"
+	case time.Duration:
+		return "duration"
+	case Link:
+		return "link"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
+
+func (e env) eval(x *Expr) any {
+	switch x.kind {
+	case exprLiteral:
+		return x.value
+	case exprLink:
+		return e.vault.resolve(x.name)
+	case exprField:
+		v, _ := lookup(e.row, x.name)
+		return v
+	case exprMember:
+		return e.member(e.eval(x.args[0]), x.name)
+	case exprIndex:
+		target := e.eval(x.args[0])
+		idx := e.eval(x.args[1])
+		switch t := target.(type) {
+		case []any:
+			n, ok := idx.(float64)
+			if !ok || n < 0 || int(n) >= len(t) {
+				return nil
+			}
+			return t[int(n)]
+		default:
+			return e.member(target, str(idx))
+		}
+	case exprList:
+		out := make([]any, 0, len(x.args))
+		for _, item := range x.args {
+			out = append(out, e.eval(item))
+		}
+		return out
+	case exprUnary:
+		v := e.eval(x.args[0])
+		if x.op == "!" {
+			return !truthy(v)
+		}
+		switch n := v.(type) {
+		case float64:
+			return -n
+		case time.Duration:
+			return -n
+		}
+		return nil
+	case exprBinary:
+		switch x.op {
+		case "and":
+			return truthy(e.eval(x.args[0])) && truthy(e.eval(x.args[1]))
+		case "or":
+			return truthy(e.eval(x.args[0])) || truthy(e.eval(x.args[1]))
+		}
+		left, right := e.eval(x.args[0]), e.eval(x.args[1])
+		switch x.op {
+		case "=":
+			return equal(left, right)
+		case "!=":
+			return !equal(left, right)
+		}
+		if left == nil || right == nil {
+			// Ordering against a missing value is never true.
+			switch x.op {
+			case "<", "<=", ">", ">=":
+				return false
+			}
+		}
+		switch x.op {
+		case "<":
+			return compare(left, right) < 0
+		case "<=":
+			return compare(left, right) <= 0
+		case ">":
+			return compare(left, right) > 0
+		case ">=":
+			return compare(left, right) >= 0
+		}
+		return arithmetic(x.op, left, right)
+	case exprCall:
+		args := make([]any, 0, len(x.args))
+		for _, arg := range x.args {
+			args = append(args, e.eval(arg))
+		}
+		return functions[x.name](e, args)
+	}
+	return nil
+}
+
+// member reads a field; on a list it maps over the items, as Dataview
+// does for rows.file.name after GROUP BY.
+func (e env) member(target any, name string) any {
+	switch t := target.(type) {
+	case map[string]any:
+		v, _ := lookup(t, name)
+		return v
+	case []any:
+		out := make([]any, 0, len(t))
+		for _, item := range t {
+			out = append(out, e.member(item, name))
+		}
+		return out
+	case Link:
+		if page, ok := e.vault.pages[t.Path]; ok {
+			v, _ := lookup(page, name)
+			return v
+		}
+		return nil
+	case time.Time:
+		switch strings.ToLower(name) {
+		case "year":
+			return float64(t.Year())
+		case "month":
+			return float64(t.Month())
+		case "day":
+			return float64(t.Day())
+		case "hour":
+			return float64(t.Hour())
+		case "minute":
+			return float64(t.Minute())
+		case "second":
+			return float64(t.Second())
+		case "weekday":
+			return float64((int(t.Weekday())+6)%7 + 1)
+		}
+	case time.Duration:
+		switch strings.ToLower(name) {
+		case "days":
+			return t.Hours() / 24
+		case "hours":
+			return t.Hours()
+		case "minutes":
+			return t.Minutes()
+		case "seconds":
+			return t.Seconds()
+		}
+	}
+	return nil
+}
+
+func arithmetic(op string, left, right any) any {
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			if s, isStr := right.(string); isStr && op == "+" {
+				return display(l) + s
+			}
+			return nil
+		}
+		switch op {
+		case "+":
+			return l + r
+		case "-":
+			return l - r
+		case "*":
+			return l * r
+		case "/":
+			if r == 0 {
+				return nil
+			}
+			return l / r
+		case "%":
+			if r == 0 {
+				return nil
+			}
+			return math.Mod(l, r)
+		}
+	case string:
+		if op == "+" {
+			return l + str(right)
+		}
+	case time.Time:
+		switch r := right.(type) {
+		case time.Duration:
+			switch op {
+			case "+":
+				return addDuration(l, r)
+			case "-":
+				return addDuration(l, -r)
+			}
+		case time.Time:
+			if op == "-" {
+				return l.Sub(r)
+			}
+		}
+	case time.Duration:
+		switch r := right.(type) {
+		case time.Duration:
+			switch op {
+			case "+":
+				return l + r
+			case "-":
+				return l - r
+			}
+		case time.Time:
+			if op == "+" {
+				return addDuration(r, l)
+			}
+		case float64:
+			switch op {
+			case "*":
+				return time.Duration(float64(l) * r)
+			case "/":
+				if r == 0 {
+					return nil
+				}
+				return time.Duration(float64(l) / r)
+			}
+		}
+	case []any:
+		if r, ok := right.([]any); ok && op == "+" {
+			return append(append([]any{}, l...), r...)
+		}
+	}
+	return nil
+}
+
+// addDuration keeps whole-day durations on calendar days.
+func addDuration(t time.Time, d time.Duration) time.Time {
+	day := 24 * time.Hour
+	if d%day == 0 {
+		return t.AddDate(0, 0, int(d/day))
+	}
+	return t.Add(d)
+}
+
+// lookup finds a field by exact name, then case-insensitively, then by
+// Dataview's sanitized form (lowercase, spaces as dashes).
+func lookup(m map[string]any, name string) (any, bool) {
+	if v, ok := m[name]; ok {
+		return v, true
+	}
+	want := sanitizeKey(name)
+	for k, v := range m {
+		if strings.EqualFold(k, name) || sanitizeKey(k) == want {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func sanitizeKey(key string) string {
+	return strings.ToLower(strings.Join(strings.Fields(key), "-"))
+}
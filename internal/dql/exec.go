@@ -0,0 +1,237 @@
+package dql
+
+import (
+	"sort"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/store"
+)
+
+// Result is the tabular output of a query. Rows are keyed by the names in
+// Columns; grouped results carry the group value under "key".
+type Result struct {
+	Type    QueryType        `json:"type"`
+	Columns []string         `json:"columns"`
+	Rows    []map[string]any `json:"rows"`
+	Grouped bool             `json:"grouped,omitempty"`
+}
+
+var taskColumns = []string{"path", "line", "status", "text", "completed"}
+
+// Run parses src and executes it over the indexed vault.
+func Run(src string, snap store.Snapshot) (Result, error) {
+	q, err := Parse(src)
+	if err != nil {
+		return Result{}, err
+	}
+	return Execute(q, snap, time.Now()), nil
+}
+
+// Execute evaluates a parsed query; now anchors date(today) and friends.
+func Execute(q *Query, snap store.Snapshot, now time.Time) Result {
+	vault := newVaultData(snap, now)
+	rows := []map[string]any{}
+	for _, rel := range vault.paths {
+		if !vault.matchSource(q.From, rel) {
+			continue
+		}
+		page := vault.pages[rel]
+		if q.Type != QueryTask {
+			rows = append(rows, page)
+			continue
+		}
+		// Task rows see their own fields plus the page's file.* fields.
+		file := page["file"].(map[string]any)
+		for _, item := range file["tasks"].([]any) {
+			row := map[string]any{"file": file}
+			for k, v := range item.(map[string]any) {
+				row[k] = v
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	grouped := false
+	for _, cmd := range q.Commands {
+		switch cmd.Kind {
+		case CommandWhere:
+			kept := rows[:0:0]
+			for _, row := range rows {
+				if truthy((env{row: row, vault: vault}).eval(cmd.Expr)) {
+					kept = append(kept, row)
+				}
+			}
+			rows = kept
+		case CommandSort:
+			sortRows(rows, cmd.Sorts, vault)
+		case CommandGroup:
+			rows = groupRows(rows, cmd, vault)
+			grouped = true
+		case CommandLimit:
+			if len(rows) > cmd.Limit {
+				rows = rows[:cmd.Limit]
+			}
+		}
+	}
+	return render(q, rows, grouped, vault)
+}
+
+func sortRows(rows []map[string]any, keys []SortKey, vault *vaultData) {
+	computed := make([][]any, len(rows))
+	for i := range rows {
+		e := env{row: rows[i], vault: vault}
+		vals := make([]any, len(keys))
+		for k, key := range keys {
+			vals[k] = e.eval(key.Expr)
+		}
+		computed[i] = vals
+	}
+	idx := make([]int, len(rows))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		for k, key := range keys {
+			c := compare(computed[idx[a]][k], computed[idx[b]][k])
+			if c == 0 {
+				continue
+			}
+			if key.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+	sorted := make([]map[string]any, len(rows))
+	for i, j := range idx {
+		sorted[i] = rows[j]
+	}
+	copy(rows, sorted)
+}
+
+// groupRows collapses rows sharing a key into {key, rows}, in order of
+// first appearance.
+func groupRows(rows []map[string]any, cmd Command, vault *vaultData) []map[string]any {
+	out := []map[string]any{}
+	for _, row := range rows {
+		key := (env{row: row, vault: vault}).eval(cmd.Expr)
+		var group map[string]any
+		for _, g := range out {
+			if equal(g["key"], key) {
+				group = g
+				break
+			}
+		}
+		if group == nil {
+			group = map[string]any{"key": key, "rows": []any{}}
+			if cmd.Name != "" {
+				group[cmd.Name] = key
+			}
+			out = append(out, group)
+		}
+		group["rows"] = append(group["rows"].([]any), row)
+	}
+	return out
+}
+
+func render(q *Query, rows []map[string]any, grouped bool, vault *vaultData) Result {
+	res := Result{Type: q.Type, Rows: []map[string]any{}, Grouped: grouped}
+	id := "path"
+	if grouped {
+		id = "key"
+	}
+	idValue := func(row map[string]any) any {
+		if grouped {
+			return export(row["key"])
+		}
+		return rowPath(row)
+	}
+
+	switch q.Type {
+	case QueryTask:
+		if grouped {
+			res.Columns = []string{"key", "tasks"}
+			for _, row := range rows {
+				tasks := []any{}
+				for _, item := range row["rows"].([]any) {
+					tasks = append(tasks, taskRow(item.(map[string]any)))
+				}
+				res.Rows = append(res.Rows, map[string]any{"key": export(row["key"]), "tasks": tasks})
+			}
+			return res
+		}
+		res.Columns = append([]string{}, taskColumns...)
+		for _, row := range rows {
+			res.Rows = append(res.Rows, taskRow(row))
+		}
+		return res
+	case QueryList:
+		if !q.WithoutID {
+			res.Columns = append(res.Columns, id)
+		}
+		switch {
+		case len(q.Fields) > 0:
+			res.Columns = append(res.Columns, "value")
+		case grouped:
+			res.Columns = append(res.Columns, "rows")
+		}
+		for _, row := range rows {
+			out := map[string]any{}
+			if !q.WithoutID {
+				out[id] = idValue(row)
+			}
+			switch {
+			case len(q.Fields) > 0:
+				out["value"] = export((env{row: row, vault: vault}).eval(q.Fields[0].Expr))
+			case grouped:
+				paths := []any{}
+				for _, item := range row["rows"].([]any) {
+					paths = append(paths, rowPath(item.(map[string]any)))
+				}
+				out["rows"] = paths
+			}
+			res.Rows = append(res.Rows, out)
+		}
+		return res
+	default:
+		if !q.WithoutID {
+			res.Columns = append(res.Columns, id)
+		}
+		for _, f := range q.Fields {
+			res.Columns = append(res.Columns, f.Name)
+		}
+		for _, row := range rows {
+			out := map[string]any{}
+			if !q.WithoutID {
+				out[id] = idValue(row)
+			}
+			e := env{row: row, vault: vault}
+			for _, f := range q.Fields {
+				out[f.Name] = export(e.eval(f.Expr))
+			}
+			res.Rows = append(res.Rows, out)
+		}
+		return res
+	}
+}
+
+func taskRow(row map[string]any) map[string]any {
+	out := map[string]any{}
+	for _, col := range taskColumns {
+		out[col] = export(row[col])
+	}
+	return out
+}
+
+func rowPath(row map[string]any) any {
+	if file, ok := row["file"].(map[string]any); ok {
+		return file["path"]
+	}
+	return export(row["key"])
+}
+
+// Display renders a result value for plain-text output.
+func Display(v any) string {
+	return display(v)
+}
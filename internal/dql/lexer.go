@@ -0,0 +1,135 @@
+package dql
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokTag
+	tokLink
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits a DQL query into tokens. Identifiers may contain '-' as in
+// Dataview, so subtraction needs surrounding spaces.
+func lex(src string) ([]token, error) {
+	runes := []rune(src)
+	out := []token{}
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			quote := r
+			i++
+			var b strings.Builder
+			closed := false
+			for i < len(runes) {
+				c := runes[i]
+				i++
+				if c == '\\' && i < len(runes) {
+					b.WriteRune(runes[i])
+					i++
+					continue
+				}
+				if c == quote {
+					closed = true
+					break
+				}
+				b.WriteRune(c)
+			}
+			if !closed {
+				return nil, syntaxError("unclosed string literal", start)
+			}
+			out = append(out, token{kind: tokString, text: b.String(), pos: start})
+		case r == '[' && i+1 < len(runes) && runes[i+1] == '[':
+			start := i
+			end := strings.Index(string(runes[i+2:]), "]]")
+			if end < 0 {
+				return nil, syntaxError("unclosed [[link]]", start)
+			}
+			inner := []rune(string(runes[i+2:])[:end])
+			out = append(out, token{kind: tokLink, text: string(inner), pos: start})
+			i += 2 + len(inner) + 2
+		case r == '#' && i+1 < len(runes) && isIdentRune(runes[i+1]):
+			start := i
+			i++
+			for i < len(runes) && (isIdentRune(runes[i]) || runes[i] == '/') {
+				i++
+			}
+			out = append(out, token{kind: tokTag, text: string(runes[start:i]), pos: start})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			out = append(out, token{kind: tokNumber, text: string(runes[start:i]), pos: start})
+		case isIdentStart(r):
+			start := i
+			for i < len(runes) && (isIdentRune(runes[i]) || runes[i] == '-') {
+				i++
+			}
+			// A trailing '-' belongs to the next token ("a- 1" is odd but legal).
+			for i > start+1 && runes[i-1] == '-' {
+				i--
+			}
+			out = append(out, token{kind: tokIdent, text: string(runes[start:i]), pos: start})
+		default:
+			start := i
+			two := ""
+			if i+1 < len(runes) {
+				two = string(runes[i : i+2])
+			}
+			switch two {
+			case "!=", "<=", ">=", "&&", "||":
+				out = append(out, token{kind: tokOp, text: two, pos: start})
+				i += 2
+				continue
+			}
+			if strings.ContainsRune("=<>!+-*/%(),.[]&|", r) {
+				out = append(out, token{kind: tokOp, text: string(r), pos: start})
+				i++
+				continue
+			}
+			return nil, syntaxError("unexpected character '"+string(r)+"'", start)
+		}
+	}
+	out = append(out, token{kind: tokEOF, pos: len(runes)})
+	return out, nil
+}
+
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
+}
+
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func syntaxError(msg string, pos int) error {
+	return errs.NewDetailed(
+		errs.ExitValidation,
+		"invalid_dql",
+		"Check the query against Dataview DQL syntax (LIST/TABLE/TASK, FROM, WHERE, SORT, GROUP BY, LIMIT).",
+		"dql: "+msg+" at offset "+strconv.Itoa(pos),
+	)
+}
@@ -0,0 +1,259 @@
+package dql
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/index"
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/nightisyang/obsidian-cli/internal/store"
+)
+
+var dayInNamePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+
+// vaultData holds one page object per indexed note, keyed by path.
+type vaultData struct {
+	paths  []string
+	pages  map[string]map[string]any
+	byPath map[string]string
+	byName map[string]string
+	tags   map[string][]string
+	now    time.Time
+}
+
+// newVaultData builds Dataview page objects from the index snapshot:
+// frontmatter and inline fields at the top level plus the implicit file.*
+// fields.
+func newVaultData(snap store.Snapshot, now time.Time) *vaultData {
+	v := &vaultData{
+		paths:  snap.Paths(),
+		pages:  map[string]map[string]any{},
+		byPath: map[string]string{},
+		byName: map[string]string{},
+		tags:   map[string][]string{},
+		now:    now,
+	}
+	for _, rel := range v.paths {
+		noExt := strings.ToLower(strings.TrimSuffix(rel, ".md"))
+		v.byPath[noExt] = rel
+		base := path.Base(noExt)
+		// Prefer the shortest path for a shared basename, as Obsidian does.
+		if prev, ok := v.byName[base]; !ok || len(rel) < len(prev) {
+			v.byName[base] = rel
+		}
+	}
+
+	outlinks := map[string][]any{}
+	inlinks := map[string][]any{}
+	for _, rel := range v.paths {
+		for _, target := range snap.Files[rel].Links {
+			link := v.resolve(target)
+			outlinks[rel] = append(outlinks[rel], link)
+			if _, ok := snap.Files[link.Path]; ok {
+				inlinks[link.Path] = append(inlinks[link.Path], Link{Path: rel})
+			}
+		}
+	}
+
+	for _, rel := range v.paths {
+		entry := snap.Files[rel]
+		v.tags[rel] = entry.Tags
+		page := map[string]any{}
+		for key, value := range entry.Properties {
+			page[key] = normalize(value)
+		}
+		for key, value := range entry.Fields {
+			typed := inferInline(value, v.resolve)
+			if prev, ok := page[key]; ok {
+				page[key] = append(asList(prev), asList(typed)...)
+				continue
+			}
+			page[key] = typed
+		}
+
+		name := strings.TrimSuffix(path.Base(rel), ".md")
+		folder := path.Dir(rel)
+		if folder == "." {
+			folder = ""
+		}
+		mtime := time.Unix(0, entry.MTime).UTC()
+		file := map[string]any{
+			"name":        name,
+			"path":        rel,
+			"folder":      folder,
+			"ext":         "md",
+			"link":        Link{Path: rel},
+			"size":        float64(entry.Size),
+			"mtime":       mtime,
+			"mday":        time.Date(mtime.Year(), mtime.Month(), mtime.Day(), 0, 0, 0, 0, time.UTC),
+			"tags":        expandTags(entry.Tags),
+			"etags":       hashTags(entry.Tags),
+			"outlinks":    nonNil(outlinks[rel]),
+			"inlinks":     nonNil(inlinks[rel]),
+			"frontmatter": normalize(entry.Properties),
+			"aliases":     asList(normalize(entry.Properties["aliases"])),
+		}
+		if day, ok := dayFromName(name); ok {
+			file["day"] = day
+		}
+		page["file"] = file
+
+		taskList := []any{}
+		for _, t := range entry.Tasks {
+			taskList = append(taskList, v.taskObject(rel, t.Line, t.Status, t.Text))
+		}
+		file["tasks"] = taskList
+		v.pages[rel] = page
+	}
+	return v
+}
+
+func (v *vaultData) taskObject(rel string, line int, status, text string) map[string]any {
+	task := map[string]any{}
+	for key, value := range note.InlineFields(text) {
+		task[key] = inferInline(value, v.resolve)
+	}
+	task["text"] = text
+	task["status"] = status
+	task["completed"] = strings.EqualFold(status, "x")
+	task["checked"] = status != " "
+	task["line"] = float64(line)
+	task["path"] = rel
+	task["link"] = Link{Path: rel}
+	return task
+}
+
+// resolve maps link text to a note the way wikilinks resolve: by full
+// path first, then by basename.
+func (v *vaultData) resolve(target string) Link {
+	clean := target
+	if idx := strings.Index(clean, "|"); idx >= 0 {
+		clean = clean[:idx]
+	}
+	if idx := strings.Index(clean, "#"); idx >= 0 {
+		clean = clean[:idx]
+	}
+	norm := index.NormalizeLinkTarget(clean)
+	if rel, ok := v.byPath[norm]; ok {
+		return Link{Path: rel}
+	}
+	if rel, ok := v.byName[path.Base(norm)]; ok && !strings.Contains(norm, "/") {
+		return Link{Path: rel}
+	}
+	return Link{Path: strings.TrimSpace(clean)}
+}
+
+// matchSource reports whether the page at rel is selected by src.
+func (v *vaultData) matchSource(src *Source, rel string) bool {
+	if src == nil {
+		return true
+	}
+	switch src.Kind {
+	case SourceTag:
+		want := index.NormalizeTag(src.Value)
+		for _, tag := range v.tags[rel] {
+			if tag == want || strings.HasPrefix(tag, want+"/") {
+				return true
+			}
+		}
+		return false
+	case SourceFolder:
+		folder := strings.Trim(strings.TrimSpace(src.Value), "/")
+		if folder == "" {
+			return true
+		}
+		return rel == folder || rel == folder+".md" || strings.HasPrefix(rel, folder+"/")
+	case SourceLink:
+		target := v.resolve(src.Value)
+		for _, link := range asList(v.fileField(rel, "outlinks")) {
+			if l, ok := link.(Link); ok && strings.EqualFold(l.Path, target.Path) {
+				return true
+			}
+		}
+		return false
+	case SourceOutgoing:
+		target := v.resolve(src.Value)
+		for _, link := range asList(v.fileField(target.Path, "outlinks")) {
+			if l, ok := link.(Link); ok && l.Path == rel {
+				return true
+			}
+		}
+		return false
+	case SourceAnd:
+		for _, child := range src.Children {
+			if !v.matchSource(child, rel) {
+				return false
+			}
+		}
+		return true
+	case SourceOr:
+		for _, child := range src.Children {
+			if v.matchSource(child, rel) {
+				return true
+			}
+		}
+		return false
+	case SourceNot:
+		return !v.matchSource(src.Children[0], rel)
+	}
+	return false
+}
+
+func (v *vaultData) fileField(rel, name string) any {
+	page, ok := v.pages[rel]
+	if !ok {
+		return nil
+	}
+	file, _ := page["file"].(map[string]any)
+	return file[name]
+}
+
+// expandTags lists tags with '#' and every parent of nested tags.
+func expandTags(tags []string) []any {
+	seen := map[string]struct{}{}
+	out := []string{}
+	for _, tag := range tags {
+		parts := strings.Split(tag, "/")
+		for i := range parts {
+			t := "#" + strings.Join(parts[:i+1], "/")
+			if _, ok := seen[t]; ok {
+				continue
+			}
+			seen[t] = struct{}{}
+			out = append(out, t)
+		}
+	}
+	sort.Strings(out)
+	list := make([]any, 0, len(out))
+	for _, t := range out {
+		list = append(list, t)
+	}
+	return list
+}
+
+func hashTags(tags []string) []any {
+	out := make([]any, 0, len(tags))
+	for _, tag := range tags {
+		out = append(out, "#"+tag)
+	}
+	return out
+}
+
+func dayFromName(name string) (time.Time, bool) {
+	m := dayInNamePattern.FindString(path.Base(name))
+	if m == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("2006-01-02", m)
+	return t, err == nil
+}
+
+func nonNil(list []any) []any {
+	if list == nil {
+		return []any{}
+	}
+	return list
+}
@@ -0,0 +1,660 @@
+package dql
+
+import (
+	"strconv"
+	"strings"
+)
+
+type QueryType string
+
+const (
+	QueryList  QueryType = "list"
+	QueryTable QueryType = "table"
+	QueryTask  QueryType = "task"
+)
+
+// Query is a parsed DQL query.
+type Query struct {
+	Type      QueryType
+	WithoutID bool
+	Fields    []Field
+	From      *Source
+	Commands  []Command
+}
+
+// Field is a LIST value or TABLE column, with its display name.
+type Field struct {
+	Expr *Expr
+	Name string
+}
+
+type CommandKind string
+
+const (
+	CommandWhere CommandKind = "where"
+	CommandSort  CommandKind = "sort"
+	CommandGroup CommandKind = "group"
+	CommandLimit CommandKind = "limit"
+)
+
+// Command is a data command applied, in order, after FROM.
+type Command struct {
+	Kind  CommandKind
+	Expr  *Expr
+	Sorts []SortKey
+	Name  string
+	Limit int
+}
+
+type SortKey struct {
+	Expr *Expr
+	Desc bool
+}
+
+type SourceKind string
+
+const (
+	SourceTag      SourceKind = "tag"
+	SourceFolder   SourceKind = "folder"
+	SourceLink     SourceKind = "link"
+	SourceOutgoing SourceKind = "outgoing"
+	SourceAnd      SourceKind = "and"
+	SourceOr       SourceKind = "or"
+	SourceNot      SourceKind = "not"
+)
+
+// Source is a FROM clause: tags, folders, incoming links to [[note]] or
+// outgoing([[note]]), combined with and/or/negation.
+type Source struct {
+	Kind     SourceKind
+	Value    string
+	Children []*Source
+}
+
+type exprKind int
+
+const (
+	exprLiteral exprKind = iota
+	exprField
+	exprMember
+	exprIndex
+	exprUnary
+	exprBinary
+	exprCall
+	exprList
+	exprLink
+)
+
+// Expr is a node of a DQL expression.
+type Expr struct {
+	kind  exprKind
+	op    string
+	name  string
+	value any
+	args  []*Expr
+}
+
+// Parse compiles a DQL query.
+func Parse(src string) (*Query, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	return p.parseQuery()
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(words ...string) bool {
+	t := p.peek()
+	if t.kind != tokIdent {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(t.text, w) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) isOp(ops ...string) bool {
+	t := p.peek()
+	if t.kind != tokOp {
+		return false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expectOp(op string) error {
+	if !p.isOp(op) {
+		return p.errorf("expected '" + op + "'")
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) errorf(msg string) error {
+	t := p.peek()
+	if t.kind == tokEOF {
+		return syntaxError(msg+", found end of query", t.pos)
+	}
+	return syntaxError(msg+", found '"+t.text+"'", t.pos)
+}
+
+// atClause reports whether the next token starts a clause, ending the
+// query header.
+func (p *parser) atClause() bool {
+	return p.peek().kind == tokEOF || p.isKeyword("FROM", "WHERE", "SORT", "GROUP", "LIMIT", "FLATTEN")
+}
+
+func (p *parser) parseQuery() (*Query, error) {
+	q := &Query{}
+	switch {
+	case p.isKeyword("LIST"):
+		q.Type = QueryList
+	case p.isKeyword("TABLE"):
+		q.Type = QueryTable
+	case p.isKeyword("TASK"):
+		q.Type = QueryTask
+	default:
+		return nil, p.errorf("query must start with LIST, TABLE or TASK")
+	}
+	p.next()
+
+	if q.Type != QueryTask && p.isKeyword("WITHOUT") {
+		p.next()
+		if !p.isKeyword("ID") {
+			return nil, p.errorf("expected ID after WITHOUT")
+		}
+		p.next()
+		q.WithoutID = true
+	}
+	if q.Type != QueryTask && !p.atClause() {
+		for {
+			field, err := p.parseField()
+			if err != nil {
+				return nil, err
+			}
+			q.Fields = append(q.Fields, field)
+			if q.Type == QueryList || !p.isOp(",") {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if p.isKeyword("FROM") {
+		p.next()
+		src, err := p.parseSourceOr()
+		if err != nil {
+			return nil, err
+		}
+		q.From = src
+	}
+
+	for p.peek().kind != tokEOF {
+		cmd, err := p.parseCommand()
+		if err != nil {
+			return nil, err
+		}
+		q.Commands = append(q.Commands, cmd)
+	}
+	return q, nil
+}
+
+func (p *parser) parseField() (Field, error) {
+	start := p.pos
+	expr, err := p.parseExpr()
+	if err != nil {
+		return Field{}, err
+	}
+	name := p.sourceText(start, p.pos)
+	if p.isKeyword("AS") {
+		p.next()
+		t := p.next()
+		if t.kind != tokIdent && t.kind != tokString {
+			return Field{}, syntaxError("expected column name after AS", t.pos)
+		}
+		name = t.text
+	}
+	return Field{Expr: expr, Name: name}, nil
+}
+
+// sourceText rebuilds a readable column name from tokens [from, to).
+func (p *parser) sourceText(from, to int) string {
+	var b strings.Builder
+	for i := from; i < to; i++ {
+		t := p.tokens[i]
+		text := t.text
+		switch t.kind {
+		case tokString:
+			text = strconv.Quote(t.text)
+		case tokLink:
+			text = "[[" + t.text + "]]"
+		}
+		if i > from && !(t.kind == tokOp && (text == "." || text == "(" || text == ")" || text == "," || text == "[" || text == "]")) {
+			prev := p.tokens[i-1]
+			if !(prev.kind == tokOp && (prev.text == "." || prev.text == "(" || prev.text == "[")) {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+func (p *parser) parseCommand() (Command, error) {
+	switch {
+	case p.isKeyword("WHERE"):
+		p.next()
+		expr, err := p.parseExpr()
+		if err != nil {
+			return Command{}, err
+		}
+		return Command{Kind: CommandWhere, Expr: expr}, nil
+	case p.isKeyword("SORT"):
+		p.next()
+		cmd := Command{Kind: CommandSort}
+		for {
+			expr, err := p.parseExpr()
+			if err != nil {
+				return Command{}, err
+			}
+			key := SortKey{Expr: expr}
+			switch {
+			case p.isKeyword("DESC", "DESCENDING"):
+				p.next()
+				key.Desc = true
+			case p.isKeyword("ASC", "ASCENDING"):
+				p.next()
+			}
+			cmd.Sorts = append(cmd.Sorts, key)
+			if !p.isOp(",") {
+				break
+			}
+			p.next()
+		}
+		return cmd, nil
+	case p.isKeyword("GROUP"):
+		p.next()
+		if !p.isKeyword("BY") {
+			return Command{}, p.errorf("expected BY after GROUP")
+		}
+		p.next()
+		expr, err := p.parseExpr()
+		if err != nil {
+			return Command{}, err
+		}
+		cmd := Command{Kind: CommandGroup, Expr: expr}
+		if p.isKeyword("AS") {
+			p.next()
+			t := p.next()
+			if t.kind != tokIdent {
+				return Command{}, syntaxError("expected name after AS", t.pos)
+			}
+			cmd.Name = t.text
+		}
+		return cmd, nil
+	case p.isKeyword("LIMIT"):
+		p.next()
+		t := p.next()
+		n, err := strconv.Atoi(t.text)
+		if t.kind != tokNumber || err != nil || n < 0 {
+			return Command{}, syntaxError("LIMIT requires a non-negative integer", t.pos)
+		}
+		return Command{Kind: CommandLimit, Limit: n}, nil
+	case p.isKeyword("FROM"):
+		return Command{}, p.errorf("FROM must come directly after the query type")
+	default:
+		return Command{}, p.errorf("expected WHERE, SORT, GROUP BY or LIMIT")
+	}
+}
+
+func (p *parser) parseSourceOr() (*Source, error) {
+	left, err := p.parseSourceAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []*Source{left}
+	for p.isKeyword("OR") || p.isOp("|", "||") {
+		p.next()
+		right, err := p.parseSourceAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, right)
+	}
+	if len(children) == 1 {
+		return left, nil
+	}
+	return &Source{Kind: SourceOr, Children: children}, nil
+}
+
+func (p *parser) parseSourceAnd() (*Source, error) {
+	left, err := p.parseSourceUnary()
+	if err != nil {
+		return nil, err
+	}
+	children := []*Source{left}
+	for p.isKeyword("AND") || p.isOp("&", "&&") {
+		p.next()
+		right, err := p.parseSourceUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, right)
+	}
+	if len(children) == 1 {
+		return left, nil
+	}
+	return &Source{Kind: SourceAnd, Children: children}, nil
+}
+
+func (p *parser) parseSourceUnary() (*Source, error) {
+	if p.isOp("-", "!") {
+		p.next()
+		child, err := p.parseSourceUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Source{Kind: SourceNot, Children: []*Source{child}}, nil
+	}
+	t := p.peek()
+	switch {
+	case t.kind == tokTag:
+		p.next()
+		return &Source{Kind: SourceTag, Value: t.text}, nil
+	case t.kind == tokString:
+		p.next()
+		return &Source{Kind: SourceFolder, Value: t.text}, nil
+	case t.kind == tokLink:
+		p.next()
+		return &Source{Kind: SourceLink, Value: t.text}, nil
+	case p.isKeyword("outgoing"):
+		p.next()
+		if err := p.expectOp("("); err != nil {
+			return nil, err
+		}
+		link := p.next()
+		if link.kind != tokLink {
+			return nil, syntaxError("outgoing() expects a [[link]]", link.pos)
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return &Source{Kind: SourceOutgoing, Value: link.text}, nil
+	case p.isOp("("):
+		p.next()
+		src, err := p.parseSourceOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return src, nil
+	default:
+		return nil, p.errorf("expected #tag, \"folder\", [[link]] or outgoing([[link]]) in FROM")
+	}
+}
+
+func (p *parser) parseExpr() (*Expr, error) {
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() (*Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") || p.isOp("|", "||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expr{kind: exprBinary, op: "or", args: []*Expr{left, right}}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (*Expr, error) {
+	left, err := p.parseCompare()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") || p.isOp("&", "&&") {
+		p.next()
+		right, err := p.parseCompare()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expr{kind: exprBinary, op: "and", args: []*Expr{left, right}}
+	}
+	return left, nil
+}
+
+func (p *parser) parseCompare() (*Expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("=", "!=", "<", "<=", ">", ">=") {
+		op := p.next().text
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &Expr{kind: exprBinary, op: op, args: []*Expr{left, right}}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (*Expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+", "-") {
+		op := p.next().text
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expr{kind: exprBinary, op: op, args: []*Expr{left, right}}
+	}
+	return left, nil
+}
+
+func (p *parser) parseMultiplicative() (*Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*", "/", "%") {
+		op := p.next().text
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expr{kind: exprBinary, op: op, args: []*Expr{left, right}}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (*Expr, error) {
+	if p.isOp("!", "-") {
+		op := p.next().text
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Expr{kind: exprUnary, op: op, args: []*Expr{child}}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (*Expr, error) {
+	expr, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.isOp("."):
+			p.next()
+			t := p.next()
+			if t.kind != tokIdent {
+				return nil, syntaxError("expected field name after '.'", t.pos)
+			}
+			expr = &Expr{kind: exprMember, name: t.text, args: []*Expr{expr}}
+		case p.isOp("["):
+			p.next()
+			idx, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp("]"); err != nil {
+				return nil, err
+			}
+			expr = &Expr{kind: exprIndex, args: []*Expr{expr, idx}}
+		default:
+			return expr, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (*Expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokNumber:
+		p.next()
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, syntaxError("invalid number '"+t.text+"'", t.pos)
+		}
+		return &Expr{kind: exprLiteral, value: n}, nil
+	case tokString:
+		p.next()
+		return &Expr{kind: exprLiteral, value: t.text}, nil
+	case tokLink:
+		p.next()
+		return &Expr{kind: exprLink, name: t.text}, nil
+	case tokIdent:
+		p.next()
+		switch strings.ToLower(t.text) {
+		case "true":
+			return &Expr{kind: exprLiteral, value: true}, nil
+		case "false":
+			return &Expr{kind: exprLiteral, value: false}, nil
+		case "null":
+			return &Expr{kind: exprLiteral, value: nil}, nil
+		}
+		if p.isOp("(") {
+			return p.parseCall(t)
+		}
+		return &Expr{kind: exprField, name: t.text}, nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			p.next()
+			expr, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			return expr, nil
+		case "[":
+			p.next()
+			items := []*Expr{}
+			for !p.isOp("]") {
+				item, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+				if !p.isOp(",") {
+					break
+				}
+				p.next()
+			}
+			if err := p.expectOp("]"); err != nil {
+				return nil, err
+			}
+			return &Expr{kind: exprList, args: items}, nil
+		}
+	}
+	return nil, p.errorf("expected an expression")
+}
+
+func (p *parser) parseCall(name token) (*Expr, error) {
+	fn := strings.ToLower(name.text)
+	if _, ok := functions[fn]; !ok {
+		return nil, syntaxError("unknown function '"+name.text+"'", name.pos)
+	}
+	p.next() // (
+	args := []*Expr{}
+	// dur(7 days) and date(today) take bare words rather than expressions.
+	bare := []string{}
+	switch {
+	case fn == "dur" && p.peek().kind != tokString:
+		for (p.peek().kind == tokNumber || p.peek().kind == tokIdent) && !p.isOp(")") {
+			bare = append(bare, p.next().text)
+		}
+	case fn == "date" && p.peek().kind == tokIdent && isDateKeyword(p.peek().text) && p.tokens[p.pos+1].kind == tokOp && p.tokens[p.pos+1].text == ")":
+		bare = append(bare, p.next().text)
+	}
+	if len(bare) > 0 {
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return &Expr{kind: exprCall, name: fn, args: []*Expr{{kind: exprLiteral, value: strings.Join(bare, " ")}}}, nil
+	}
+	for !p.isOp(")") {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if !p.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	if err := p.expectOp(")"); err != nil {
+		return nil, err
+	}
+	return &Expr{kind: exprCall, name: fn, args: args}, nil
+}
+
+func isDateKeyword(word string) bool {
+	switch strings.ToLower(word) {
+	case "today", "now", "tomorrow", "yesterday", "sow", "eow", "som", "eom", "soy", "eoy":
+		return true
+	}
+	return false
+}
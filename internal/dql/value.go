@@ -0,0 +1,353 @@
+package dql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Link is a reference to a note; Path is vault-relative when the target
+// resolved and the raw link text otherwise.
+type Link struct {
+	Path string
+}
+
+func (l Link) String() string {
+	return "[[" + strings.TrimSuffix(l.Path, ".md") + "]]"
+}
+
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006-01",
+}
+
+var durationPattern = regexp.MustCompile(`(?i)(-?\d+(?:\.\d+)?)\s*([a-z]+)`)
+
+// normalize converts decoded YAML/JSON values to the value types the
+// evaluator works with: float64 numbers, time.Time dates and []any lists.
+func normalize(v any) any {
+	switch x := v.(type) {
+	case json.Number:
+		if f, err := x.Float64(); err == nil {
+			return f
+		}
+		return x.String()
+	case int:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case uint64:
+		return float64(x)
+	case float32:
+		return float64(x)
+	case string:
+		if t, ok := parseDate(x); ok {
+			return t
+		}
+		return x
+	case []string:
+		out := make([]any, 0, len(x))
+		for _, item := range x {
+			out = append(out, normalize(item))
+		}
+		return out
+	case []any:
+		out := make([]any, 0, len(x))
+		for _, item := range x {
+			out = append(out, normalize(item))
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, item := range x {
+			out[k] = normalize(item)
+		}
+		return out
+	case time.Time:
+		return x.UTC()
+	default:
+		return v
+	}
+}
+
+// inferInline types an inline field value written as plain text.
+func inferInline(v any, resolve func(string) Link) any {
+	switch x := v.(type) {
+	case string:
+		s := strings.TrimSpace(x)
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+		switch strings.ToLower(s) {
+		case "true":
+			return true
+		case "false":
+			return false
+		}
+		if strings.HasPrefix(s, "[[") && strings.HasSuffix(s, "]]") {
+			return resolve(s[2 : len(s)-2])
+		}
+		return normalize(s)
+	case []any:
+		out := make([]any, 0, len(x))
+		for _, item := range x {
+			out = append(out, inferInline(item, resolve))
+		}
+		return out
+	default:
+		return normalize(v)
+	}
+}
+
+func parseDate(raw string) (time.Time, bool) {
+	s := strings.TrimSpace(raw)
+	if len(s) < 7 || s[4] != '-' {
+		return time.Time{}, false
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseDuration(raw string) (time.Duration, bool) {
+	matches := durationPattern.FindAllStringSubmatch(raw, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	total := time.Duration(0)
+	for _, m := range matches {
+		n, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, false
+		}
+		var unit time.Duration
+		switch strings.ToLower(m[2]) {
+		case "s", "sec", "secs", "second", "seconds":
+			unit = time.Second
+		case "m", "min", "mins", "minute", "minutes":
+			unit = time.Minute
+		case "h", "hr", "hrs", "hour", "hours":
+			unit = time.Hour
+		case "d", "day", "days":
+			unit = 24 * time.Hour
+		case "w", "wk", "wks", "week", "weeks":
+			unit = 7 * 24 * time.Hour
+		case "mo", "month", "months":
+			unit = 30 * 24 * time.Hour
+		case "y", "yr", "yrs", "year", "years":
+			unit = 365 * 24 * time.Hour
+		default:
+			return 0, false
+		}
+		total += time.Duration(n * float64(unit))
+	}
+	return total, true
+}
+
+func truthy(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	case float64:
+		return x != 0
+	case string:
+		return x != ""
+	case []any:
+		return len(x) > 0
+	case map[string]any:
+		return len(x) > 0
+	case time.Time:
+		return !x.IsZero()
+	case time.Duration:
+		return x != 0
+	default:
+		return true
+	}
+}
+
+// typeRank orders values of different types so sorting is total.
+func typeRank(v any) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case float64:
+		return 2
+	case time.Duration:
+		return 3
+	case time.Time:
+		return 4
+	case string:
+		return 5
+	case Link:
+		return 6
+	case []any:
+		return 7
+	default:
+		return 8
+	}
+}
+
+// compare orders two values; values of different types order by type.
+func compare(a, b any) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return cmpInt(ra, rb)
+	}
+	switch x := a.(type) {
+	case nil:
+		return 0
+	case bool:
+		y := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		default:
+			return 1
+		}
+	case float64:
+		y := b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		default:
+			return 0
+		}
+	case time.Duration:
+		return cmpInt(int(x), int(b.(time.Duration)))
+	case time.Time:
+		return x.Compare(b.(time.Time))
+	case string:
+		return strings.Compare(x, b.(string))
+	case Link:
+		return strings.Compare(strings.ToLower(x.Path), strings.ToLower(b.(Link).Path))
+	case []any:
+		y := b.([]any)
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := compare(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return cmpInt(len(x), len(y))
+	default:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func equal(a, b any) bool {
+	if typeRank(a) != typeRank(b) {
+		return false
+	}
+	if _, ok := a.(map[string]any); ok {
+		ea, _ := json.Marshal(export(a))
+		eb, _ := json.Marshal(export(b))
+		return string(ea) == string(eb)
+	}
+	return compare(a, b) == 0
+}
+
+// export turns evaluator values into JSON-friendly output.
+func export(v any) any {
+	switch x := v.(type) {
+	case time.Time:
+		if x.Hour() == 0 && x.Minute() == 0 && x.Second() == 0 && x.Nanosecond() == 0 {
+			return x.Format("2006-01-02")
+		}
+		return x.Format(time.RFC3339)
+	case time.Duration:
+		return formatDuration(x)
+	case Link:
+		return x.String()
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return nil
+		}
+		return x
+	case []any:
+		out := make([]any, 0, len(x))
+		for _, item := range x {
+			out = append(out, export(item))
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, item := range x {
+			out[k] = export(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	day := 24 * time.Hour
+	if d%day == 0 {
+		days := int64(d / day)
+		if days == 1 || days == -1 {
+			return fmt.Sprintf("%d day", days)
+		}
+		return fmt.Sprintf("%d days", days)
+	}
+	return d.String()
+}
+
+// display renders a value for plain-text output.
+func display(v any) string {
+	switch x := export(v).(type) {
+	case nil:
+		return "-"
+	case []any:
+		parts := make([]string, 0, len(x))
+		for _, item := range x {
+			parts = append(parts, display(item))
+		}
+		return strings.Join(parts, ", ")
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, k+": "+display(x[k]))
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return fmt.Sprint(x)
+	}
+}
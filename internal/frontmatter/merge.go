@@ -10,6 +10,7 @@ import (
 
 type Data struct {
 	Title     string
+	Aliases   []string
 	Tags      []string
 	CreatedAt *time.Time
 	UpdatedAt *time.Time
@@ -25,6 +26,8 @@ func MapToFrontmatter(values map[string]any) Data {
 		switch k {
 		case "title":
 			fm.Title = toString(v)
+		case "aliases":
+			fm.Aliases = toStringSlice(v)
 		case "tags":
 			fm.Tags = toStringSlice(v)
 		case "kind":
@@ -59,6 +62,9 @@ func FrontmatterToMap(fm Data) map[string]any {
 	if fm.Title != "" {
 		out["title"] = fm.Title
 	}
+	if len(fm.Aliases) > 0 {
+		out["aliases"] = dedupeStrings(fm.Aliases)
+	}
 	if len(fm.Tags) > 0 {
 		out["tags"] = dedupeStrings(fm.Tags)
 	}
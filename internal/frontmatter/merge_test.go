@@ -44,3 +44,21 @@ func TestFrontmatterRoundTripPreservesUnknownFields(t *testing.T) {
 		t.Fatalf("count field changed after roundtrip: %v", got)
 	}
 }
+
+func TestAliasesAreFirstClass(t *testing.T) {
+	values, _, _, err := ParseDocument("---\naliases: [Q3 Plan, Roadmap, Q3 Plan]\n---\nbody")
+	if err != nil {
+		t.Fatalf("ParseDocument error: %v", err)
+	}
+	fm := MapToFrontmatter(values)
+	if len(fm.Aliases) != 2 || fm.Aliases[0] != "Q3 Plan" || fm.Extra != nil {
+		t.Fatalf("unexpected aliases: %+v", fm)
+	}
+	if single := MapToFrontmatter(map[string]any{"aliases": "Solo"}); len(single.Aliases) != 1 || single.Aliases[0] != "Solo" {
+		t.Fatalf("expected string alias to be accepted, got %+v", single.Aliases)
+	}
+	out := FrontmatterToMap(fm)
+	if got, ok := out["aliases"].([]string); !ok || len(got) != 2 {
+		t.Fatalf("aliases not written back: %#v", out["aliases"])
+	}
+}
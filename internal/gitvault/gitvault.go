@@ -0,0 +1,192 @@
+// Package gitvault commits vault changes to, and reads note history from,
+// the git repository that contains the vault.
+package gitvault
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+)
+
+// Commit is one git commit touching the vault.
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Short   string    `json:"short"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+}
+
+// Repo runs git for the vault at Root. Exclude lists vault-relative paths,
+// such as the index dir, that are never committed.
+type Repo struct {
+	Root    string
+	Exclude []string
+}
+
+// Open returns the repository containing vaultRoot, or an error when the
+// vault is not inside a git work tree.
+func Open(vaultRoot string, exclude ...string) (*Repo, error) {
+	r := &Repo{Root: vaultRoot}
+	if out, err := r.git("rev-parse", "--is-inside-work-tree"); err != nil || strings.TrimSpace(out) != "true" {
+		return nil, errs.NewDetailed(
+			errs.ExitConfig,
+			"not_a_git_repo",
+			"Run git init in the vault, or turn off git_auto_commit.",
+			"vault is not inside a git repository: "+vaultRoot,
+		)
+	}
+	for _, dir := range exclude {
+		if rel, err := filepath.Rel(vaultRoot, dir); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			r.Exclude = append(r.Exclude, filepath.ToSlash(rel))
+		}
+	}
+	return r, nil
+}
+
+// CommitAll stages every change in the vault and commits it with message.
+// It reports false, and commits nothing, when the vault has no changes.
+func (r *Repo) CommitAll(message string) (Commit, bool, error) {
+	return r.commit(message, r.pathspec())
+}
+
+// Commit stages and commits only paths, vault-relative files or folders
+// changed by a command, leaving any other change in the vault untouched.
+// Paths git cannot see, such as ignored files or files created and then
+// removed again, are skipped. It reports false when none of the paths
+// changed.
+func (r *Repo) Commit(message string, paths []string) (Commit, bool, error) {
+	files, err := r.commitFiles(paths)
+	if err != nil || len(files) == 0 {
+		return Commit{}, false, err
+	}
+	return r.commit(message, files, "--literal-pathspecs")
+}
+
+func (r *Repo) commit(message string, spec []string, opts ...string) (Commit, bool, error) {
+	run := func(args ...string) (string, error) {
+		return r.git(append(append(append([]string{}, opts...), args...), spec...)...)
+	}
+	if _, err := run("add", "-A", "--"); err != nil {
+		return Commit{}, false, err
+	}
+	if _, err := run("diff", "--cached", "--quiet", "--"); err == nil {
+		return Commit{}, false, nil
+	}
+	if _, err := run("commit", "-q", "-m", message, "--"); err != nil {
+		return Commit{}, false, err
+	}
+	commits, err := r.Log("", 1)
+	if err != nil || len(commits) == 0 {
+		return Commit{}, true, err
+	}
+	return commits[0], true, nil
+}
+
+// commitFiles expands paths to the files git tracks or would add under
+// them, dropping excluded paths.
+func (r *Repo) commitFiles(paths []string) ([]string, error) {
+	spec := []string{}
+	for _, p := range paths {
+		if p = strings.Trim(filepath.ToSlash(p), "/"); p != "" && !r.excluded(p) {
+			spec = append(spec, p)
+		}
+	}
+	if len(spec) == 0 {
+		return []string{}, nil
+	}
+	files := []string{}
+	seen := map[string]struct{}{}
+	for _, args := range [][]string{{"ls-files", "-z", "--cached"}, {"ls-files", "-z", "--others", "--exclude-standard"}} {
+		out, err := r.git(append(append([]string{"--literal-pathspecs"}, args...), append([]string{"--"}, spec...)...)...)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range strings.Split(out, "\x00") {
+			if _, dup := seen[file]; file == "" || dup || r.excluded(file) {
+				continue
+			}
+			seen[file] = struct{}{}
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func (r *Repo) excluded(rel string) bool {
+	for _, dir := range r.Exclude {
+		if rel == dir || strings.HasPrefix(rel, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Log returns up to limit commits touching rel, or the whole vault when rel
+// is empty, newest first. Renames of a single note are followed.
+func (r *Repo) Log(rel string, limit int) ([]Commit, error) {
+	args := []string{"log", "--format=%H%x1f%h%x1f%an%x1f%aI%x1f%s"}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	if rel != "" {
+		args = append(args, "--follow", "--", rel)
+	} else {
+		args = append(args, "--", ".")
+	}
+	out, err := r.git(args...)
+	if err != nil {
+		// A repository without commits has no history yet.
+		if _, headErr := r.git("rev-parse", "--verify", "-q", "HEAD"); headErr != nil {
+			return []Commit{}, nil
+		}
+		return nil, err
+	}
+	commits := []Commit{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[3])
+		commits = append(commits, Commit{Hash: fields[0], Short: fields[1], Author: fields[2], Date: date, Subject: fields[4]})
+	}
+	return commits, nil
+}
+
+// Show returns the content of rel at revision rev.
+func (r *Repo) Show(rev, rel string) ([]byte, error) {
+	out, err := r.git("show", rev+":./"+rel)
+	if err != nil {
+		return nil, errs.New(errs.ExitNotFound, rel+" not found at revision "+rev)
+	}
+	return []byte(out), nil
+}
+
+func (r *Repo) pathspec() []string {
+	spec := []string{"."}
+	for _, dir := range r.Exclude {
+		spec = append(spec, ":(exclude)"+dir)
+	}
+	return spec
+}
+
+func (r *Repo) git(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", r.Root}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = "git " + args[0] + " failed"
+		}
+		return stdout.String(), errs.Wrap(errs.ExitGeneric, msg, err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,85 @@
+package gitvault
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	return root
+}
+
+func TestCommitLogAndShow(t *testing.T) {
+	root := initRepo(t)
+	index := filepath.Join(root, ".obsidian-cli-index")
+	repo, err := Open(root, index)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if commits, err := repo.Log("", 0); err != nil || len(commits) != 0 {
+		t.Fatalf("log of empty repo = %+v (%v)", commits, err)
+	}
+
+	if err := os.MkdirAll(index, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for rel, content := range map[string]string{"plan.md": "one\n", ".obsidian-cli-index/index.json": "{}"} {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(rel)), []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	first, committed, err := repo.CommitAll("first")
+	if err != nil || !committed || first.Subject != "first" {
+		t.Fatalf("commit = %+v %v (%v)", first, committed, err)
+	}
+	if _, committed, err := repo.CommitAll("again"); err != nil || committed {
+		t.Fatalf("expected nothing to commit, got %v (%v)", committed, err)
+	}
+	if out, _ := exec.Command("git", "-C", root, "ls-files").Output(); string(out) != "plan.md\n" {
+		t.Fatalf("tracked files = %q", out)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "plan.md"), []byte("two\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "draft.md"), []byte("mine\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, _, err := repo.Commit("second", []string{"plan.md", "gone.md", ".obsidian-cli-index/index.json"}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if out, _ := exec.Command("git", "-C", root, "status", "--porcelain").Output(); string(out) != "?? .obsidian-cli-index/\n?? draft.md\n" {
+		t.Fatalf("status after path commit = %q", out)
+	}
+	if _, committed, err := repo.Commit("none", []string{"missing.md"}); err != nil || committed {
+		t.Fatalf("expected nothing to commit for unknown paths, got %v (%v)", committed, err)
+	}
+	commits, err := repo.Log("plan.md", 0)
+	if err != nil || len(commits) != 2 || commits[0].Subject != "second" {
+		t.Fatalf("log = %+v (%v)", commits, err)
+	}
+	content, err := repo.Show(commits[1].Short, "plan.md")
+	if err != nil || string(content) != "one\n" {
+		t.Fatalf("show = %q (%v)", content, err)
+	}
+
+	if _, err := Open(t.TempDir()); err == nil {
+		t.Fatal("expected a plain folder to be rejected")
+	}
+}
@@ -0,0 +1,382 @@
+// Package history keeps compressed prior versions of notes so overwritten
+// content can be listed, diffed and restored.
+package history
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+)
+
+// DefaultKeep is the number of versions kept per note when the config
+// leaves history_keep unset.
+const DefaultKeep = 50
+
+// Version is one stored prior version of a note. Versions are numbered
+// from 1 and never reused, so numbers stay valid after pruning.
+type Version struct {
+	Version int       `json:"version"`
+	SavedAt time.Time `json:"saved_at"`
+	Size    int       `json:"size"`
+	Hash    string    `json:"hash"`
+}
+
+// JournalEnv names a file that commands append the vault paths they
+// change to, one per line, so a parent process such as ops apply learns
+// what each child changed.
+const JournalEnv = "OBSIDIAN_CLI_CHANGED_PATHS"
+
+// Store saves versions under dir, one folder per note path, and remembers
+// which vault paths were changed through it.
+type Store struct {
+	// BeforeTouch, when set, is called with each vault-relative path
+	// before Touch records it, while the file still holds its old content.
+	BeforeTouch func(rel string) error
+
+	vaultRoot string
+	dir       string
+	keep      int
+	journal   string
+	touched   map[string]struct{}
+}
+
+// Open returns the history store kept in indexDir/history. keep is the
+// number of versions retained per note: 0 means DefaultKeep and a
+// negative value turns history off.
+func Open(vaultRoot, indexDir string, keep int) *Store {
+	if keep == 0 {
+		keep = DefaultKeep
+	}
+	return &Store{
+		vaultRoot: filepath.Clean(vaultRoot),
+		dir:       filepath.Join(indexDir, "history"),
+		keep:      keep,
+		journal:   os.Getenv(JournalEnv),
+		touched:   map[string]struct{}{},
+	}
+}
+
+// Touch records that the files at abs are being created, changed, moved or
+// removed, and appends them to the journal file when JournalEnv is set.
+// Paths outside the vault are ignored.
+func (s *Store) Touch(abs ...string) error {
+	if s == nil {
+		return nil
+	}
+	lines := []string{}
+	for _, p := range abs {
+		rel, err := filepath.Rel(s.vaultRoot, p)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		if s.BeforeTouch != nil {
+			if err := s.BeforeTouch(rel); err != nil {
+				return errs.Wrap(errs.ExitGeneric, "failed to save "+rel+" to the checkpoint", err)
+			}
+		}
+		s.touched[rel] = struct{}{}
+		lines = append(lines, rel+"\n")
+	}
+	if s.journal == "" || len(lines) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(s.journal, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return errs.Wrap(errs.ExitGeneric, "failed to record changed paths", err)
+	}
+	if _, err := f.WriteString(strings.Join(lines, "")); err != nil {
+		f.Close()
+		return errs.Wrap(errs.ExitGeneric, "failed to record changed paths", err)
+	}
+	return f.Close()
+}
+
+// Touched returns the sorted vault-relative paths recorded by Touch and
+// Capture.
+func (s *Store) Touched() []string {
+	if s == nil {
+		return []string{}
+	}
+	out := make([]string, 0, len(s.touched))
+	for rel := range s.touched {
+		out = append(out, rel)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ReadJournal returns the distinct paths recorded in a journal file, in
+// the order first recorded. A missing file holds no paths.
+func ReadJournal(path string) ([]string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	out := []string{}
+	seen := map[string]struct{}{}
+	for _, line := range strings.Split(string(raw), "\n") {
+		if _, dup := seen[line]; line == "" || dup {
+			continue
+		}
+		seen[line] = struct{}{}
+		out = append(out, line)
+	}
+	return out, nil
+}
+
+// Capture touches abs and saves the current content of the note or canvas
+// there, if any, before a write replaces or removes it. Every function
+// that changes vault files takes the store to capture into; a nil store
+// captures nothing.
+func (s *Store) Capture(abs string) error {
+	if s == nil {
+		return nil
+	}
+	if err := s.Touch(abs); err != nil {
+		return err
+	}
+	if s.keep < 0 {
+		return nil
+	}
+	switch strings.ToLower(filepath.Ext(abs)) {
+	case ".md", ".canvas":
+	default:
+		return nil
+	}
+	content, err := os.ReadFile(abs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	rel, err := filepath.Rel(s.vaultRoot, abs)
+	if err != nil {
+		return err
+	}
+	if _, err := s.Save(filepath.ToSlash(rel), content); err != nil {
+		return errs.Wrap(errs.ExitGeneric, "failed to save note history", err)
+	}
+	return nil
+}
+
+// WriteFile captures the current content of the file at abs and then
+// replaces it with content, creating parent folders. It is the one write
+// path for vault files that history covers; a nil store only writes.
+func (s *Store) WriteFile(abs string, content []byte) error {
+	if err := s.Capture(abs); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+		return err
+	}
+	tmp := abs + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, abs)
+}
+
+// Save stores content as the newest version of rel unless it matches the
+// newest version already stored, then prunes versions beyond retention.
+// It stores nothing when history is turned off.
+func (s *Store) Save(rel string, content []byte) (Version, error) {
+	if s.keep < 0 {
+		return Version{}, nil
+	}
+	numbers, err := s.numbers(rel)
+	if err != nil {
+		return Version{}, err
+	}
+	hash := hashContent(content)
+	v := Version{Version: 1, SavedAt: time.Now().UTC(), Size: len(content), Hash: hash}
+	if len(numbers) > 0 {
+		newest, err := s.header(rel, numbers[0])
+		if err != nil {
+			return Version{}, err
+		}
+		if newest.Hash == hash {
+			return newest, nil
+		}
+		v.Version = numbers[0] + 1
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Name = rel
+	zw.Comment = hash
+	zw.ModTime = v.SavedAt
+	zw.Extra = sizeExtra(len(content))
+	if _, err := zw.Write(content); err != nil {
+		return Version{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return Version{}, err
+	}
+	dir := s.noteDir(rel)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Version{}, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, versionFile(v.Version)), buf.Bytes(), 0o644); err != nil {
+		return Version{}, err
+	}
+
+	numbers = append([]int{v.Version}, numbers...)
+	for _, old := range numbers[min(len(numbers), s.keep):] {
+		if err := os.Remove(filepath.Join(dir, versionFile(old))); err != nil && !os.IsNotExist(err) {
+			return Version{}, err
+		}
+	}
+	return v, nil
+}
+
+// List returns the stored versions of rel, newest first. Only the gzip
+// headers are read; Read decompresses a version's content.
+func (s *Store) List(rel string) ([]Version, error) {
+	numbers, err := s.numbers(rel)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Version, 0, len(numbers))
+	for _, n := range numbers {
+		v, err := s.header(rel, n)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// Restore writes version of rel back to the note, keeping the content it
+// replaces as a new version.
+func (s *Store) Restore(rel string, version int) (Version, error) {
+	content, v, err := s.Read(rel, version)
+	if err != nil {
+		return Version{}, err
+	}
+	return v, s.WriteFile(filepath.Join(s.vaultRoot, filepath.FromSlash(rel)), content)
+}
+
+// Read returns the content of one stored version of rel.
+func (s *Store) Read(rel string, version int) ([]byte, Version, error) {
+	zr, f, err := s.open(rel, version)
+	if err != nil {
+		return nil, Version{}, err
+	}
+	defer f.Close()
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, Version{}, errs.Wrap(errs.ExitGeneric, "corrupt history version", err)
+	}
+	return content, Version{Version: version, SavedAt: zr.ModTime.UTC(), Size: len(content), Hash: hashContent(content)}, nil
+}
+
+// numbers returns the stored version numbers of rel, newest first.
+func (s *Store) numbers(rel string) ([]int, error) {
+	entries, err := os.ReadDir(s.noteDir(rel))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []int{}, nil
+		}
+		return nil, err
+	}
+	out := []int{}
+	for _, e := range entries {
+		n, err := strconv.Atoi(strings.TrimSuffix(e.Name(), ".gz"))
+		if err != nil || e.IsDir() {
+			continue
+		}
+		out = append(out, n)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(out)))
+	return out, nil
+}
+
+// header describes a stored version from its gzip header, which holds the
+// content hash, save time and size. Versions saved without a size in the
+// header are decompressed to measure it.
+func (s *Store) header(rel string, version int) (Version, error) {
+	zr, f, err := s.open(rel, version)
+	if err != nil {
+		return Version{}, err
+	}
+	defer f.Close()
+	v := Version{Version: version, SavedAt: zr.ModTime.UTC(), Hash: zr.Comment}
+	if size, ok := parseSizeExtra(zr.Extra); ok && v.Hash != "" {
+		v.Size = size
+		return v, nil
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		return Version{}, errs.Wrap(errs.ExitGeneric, "corrupt history version", err)
+	}
+	v.Size, v.Hash = len(content), hashContent(content)
+	return v, nil
+}
+
+func (s *Store) open(rel string, version int) (*gzip.Reader, *os.File, error) {
+	f, err := os.Open(filepath.Join(s.noteDir(rel), versionFile(version)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, errs.New(errs.ExitNotFound, fmt.Sprintf("version %d of %s not found", version, rel))
+		}
+		return nil, nil, err
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, errs.Wrap(errs.ExitGeneric, "corrupt history version", err)
+	}
+	return zr, f, nil
+}
+
+// sizeExtra encodes the content size as a gzip extra subfield "SZ".
+func sizeExtra(size int) []byte {
+	digits := strconv.Itoa(size)
+	return append([]byte{'S', 'Z', byte(len(digits)), 0}, digits...)
+}
+
+func parseSizeExtra(extra []byte) (int, bool) {
+	for len(extra) >= 4 {
+		n := int(extra[2]) | int(extra[3])<<8
+		if len(extra) < 4+n {
+			return 0, false
+		}
+		if extra[0] == 'S' && extra[1] == 'Z' {
+			size, err := strconv.Atoi(string(extra[4 : 4+n]))
+			return size, err == nil
+		}
+		extra = extra[4+n:]
+	}
+	return 0, false
+}
+
+func (s *Store) noteDir(rel string) string {
+	return filepath.Join(s.dir, url.PathEscape(filepath.ToSlash(rel)))
+}
+
+func versionFile(version int) string {
+	return fmt.Sprintf("%06d.gz", version)
+}
+
+func hashContent(content []byte) string {
+	digest := sha256.Sum256(content)
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,125 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveSkipsDuplicatesAndPrunes(t *testing.T) {
+	root := t.TempDir()
+	s := Open(root, filepath.Join(root, ".obsidian-cli-index"), 2)
+	for _, content := range []string{"one\n", "one\n", "two\n", "three\n"} {
+		if _, err := s.Save("notes/plan.md", []byte(content)); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+	versions, err := s.List("notes/plan.md")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != 3 || versions[1].Version != 2 {
+		t.Fatalf("unexpected versions %+v", versions)
+	}
+	content, _, err := s.Read("notes/plan.md", 2)
+	if err != nil || string(content) != "two\n" {
+		t.Fatalf("read = %q (%v)", content, err)
+	}
+	if _, _, err := s.Read("notes/plan.md", 1); err == nil {
+		t.Fatal("expected pruned version to be gone")
+	}
+}
+
+func TestWriteFileCapturesPriorContent(t *testing.T) {
+	root := t.TempDir()
+	s := Open(root, filepath.Join(root, ".obsidian-cli-index"), 0)
+
+	abs := filepath.Join(root, "notes", "plan.md")
+	for _, content := range []string{"draft\n", "final\n", "again\n"} {
+		if err := s.WriteFile(abs, []byte(content)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	versions, _ := s.List("notes/plan.md")
+	if len(versions) != 2 || versions[0].Size != len("final\n") {
+		t.Fatalf("unexpected versions %+v", versions)
+	}
+
+	// Attachments are not kept, and a nil store only writes.
+	image := filepath.Join(root, "image.png")
+	if err := s.WriteFile(image, []byte("png")); err != nil {
+		t.Fatalf("write image: %v", err)
+	}
+	if err := s.Capture(image); err != nil {
+		t.Fatalf("capture image: %v", err)
+	}
+	if versions, _ := s.List("image.png"); len(versions) != 0 {
+		t.Fatalf("unexpected attachment versions %+v", versions)
+	}
+	var none *Store
+	if err := none.WriteFile(abs, []byte("untracked\n")); err != nil {
+		t.Fatalf("write without history: %v", err)
+	}
+	if raw, _ := os.ReadFile(abs); string(raw) != "untracked\n" {
+		t.Fatalf("content = %q", raw)
+	}
+}
+
+func TestRestoreWritesVersionAndKeepsCurrent(t *testing.T) {
+	root := t.TempDir()
+	s := Open(root, filepath.Join(root, ".obsidian-cli-index"), 0)
+
+	abs := filepath.Join(root, "plan.md")
+	for _, content := range []string{"draft\n", "final\n"} {
+		if err := s.WriteFile(abs, []byte(content)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if _, err := s.Restore("plan.md", 1); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if raw, _ := os.ReadFile(abs); string(raw) != "draft\n" {
+		t.Fatalf("restored content = %q", raw)
+	}
+	versions, err := s.List("plan.md")
+	if err != nil || len(versions) != 2 || versions[0].Hash != hashContent([]byte("final\n")) || versions[0].Size != len("final\n") {
+		t.Fatalf("expected the overwritten content to be kept, got %+v (%v)", versions, err)
+	}
+	if _, err := s.Restore("plan.md", 9); err == nil {
+		t.Fatal("expected a missing version to be rejected")
+	}
+}
+
+func TestUnified(t *testing.T) {
+	a := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	b := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl"
+	got := Unified("old", "new", a, b)
+	want := strings.Join([]string{
+		"--- old",
+		"+++ new",
+		"@@ -1,5 +1,5 @@",
+		" a",
+		"-b",
+		"+B",
+		" c",
+		" d",
+		" e",
+		"@@ -9,3 +9,4 @@",
+		" i",
+		" j",
+		" k",
+		"+l",
+		"\\ No newline at end of file",
+		"",
+	}, "\n")
+	if got != want {
+		t.Fatalf("unexpected diff:\n%s", got)
+	}
+	if Unified("old", "new", a, a) != "" {
+		t.Fatal("expected no diff for equal content")
+	}
+	if got := Unified("old", "new", "", "x\n"); got != "--- old\n+++ new\n@@ -0,0 +1 @@\n+x\n" {
+		t.Fatalf("unexpected diff from empty:\n%s", got)
+	}
+}
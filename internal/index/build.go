@@ -4,12 +4,18 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
 	"time"
 
 	"github.com/nightisyang/obsidian-cli/internal/note"
 )
 
+// Backlink is a note or canvas linking to a target, with every linking
+// occurrence. Canvas sources carry no occurrences.
+type Backlink struct {
+	Source string      `json:"source"`
+	Links  []note.Link `json:"links"`
+}
+
 type BacklinkIndex struct {
 	BuiltAt        time.Time           `json:"built_at"`
 	SourceToTarget map[string][]string `json:"source_to_target"`
@@ -40,7 +46,7 @@ func BuildIndex(vaultRoot string) (BacklinkIndex, error) {
 		if readErr != nil {
 			return BacklinkIndex{}, readErr
 		}
-		targets := ParseWikiLinks(n.Body)
+		targets := LinkTargets(rel, n.Body)
 		idx.SourceToTarget[rel] = targets
 		for _, target := range targets {
 			idx.TargetToSource[target] = append(idx.TargetToSource[target], rel)
@@ -89,20 +95,23 @@ func IsStale(vaultRoot string, idx BacklinkIndex) (bool, error) {
 	return false, nil
 }
 
+// BacklinksForPath returns the sources whose links resolve to relPath.
+// Ambiguous links are not attributed to any of their candidates.
 func BacklinksForPath(vaultRoot string, index BacklinkIndex, relPath string) []string {
-	target := NormalizeLinkTarget(strings.TrimSuffix(relPath, ".md"))
-	base := NormalizeLinkTarget(strings.TrimSuffix(filepath.Base(relPath), ".md"))
-	combined := map[string]struct{}{}
-	for _, source := range index.TargetToSource[target] {
-		combined[source] = struct{}{}
+	sources := make([]string, 0, len(index.SourceToTarget))
+	for source := range index.SourceToTarget {
+		sources = append(sources, source)
 	}
-	for _, source := range index.TargetToSource[base] {
-		combined[source] = struct{}{}
-	}
-	out := make([]string, 0, len(combined))
-	for source := range combined {
-		out = append(out, source)
+	sort.Strings(sources)
+	resolver := NewResolver(append(sources, relPath))
+	out := []string{}
+	for _, source := range sources {
+		for _, target := range index.SourceToTarget[source] {
+			if resolver.Resolve(source, target).Path == relPath {
+				out = append(out, source)
+				break
+			}
+		}
 	}
-	sort.Strings(out)
 	return out
 }
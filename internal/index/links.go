@@ -5,10 +5,33 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/note"
 )
 
 var wikilinkRe = regexp.MustCompile(`\[\[([^\]]+)\]\]`)
 
+// LinkTargets returns the sorted, de-duplicated normalized targets of every
+// wikilink, markdown link and embed in body, resolving relative paths
+// against sourceRel.
+func LinkTargets(sourceRel, body string) []string {
+	seen := map[string]struct{}{}
+	out := []string{}
+	for _, l := range note.ParseLinks(body) {
+		key := l.Key(sourceRel)
+		if key == "" {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, key)
+	}
+	sort.Strings(out)
+	return out
+}
+
 func ParseWikiLinks(body string) []string {
 	matches := wikilinkRe.FindAllStringSubmatch(body, -1)
 	seen := map[string]struct{}{}
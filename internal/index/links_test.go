@@ -13,3 +13,76 @@ func TestParseWikiLinks(t *testing.T) {
 		t.Fatalf("unexpected links. got=%v want=%v", got, want)
 	}
 }
+
+func TestLinkTargetsIncludesMarkdownLinksAndEmbeds(t *testing.T) {
+	body := "[[Wiki]] ![[img.png]] [md](Folder/Note%20A.md#h) [rel](./Sib.md) [web](http://x.y/z.md)"
+	got := LinkTargets("dir/source.md", body)
+	want := []string{"dir/sib", "folder/note a", "img.png", "wiki"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected targets. got=%v want=%v", got, want)
+	}
+}
+
+func TestResolverFollowsObsidianRules(t *testing.T) {
+	r := NewResolver([]string{"Meeting.md", "work/Meeting.md", "home/Meeting.md", "projects/Plan.md", "img/diagram.png", "archive/Old Plan.md"})
+	cases := []struct {
+		source, key string
+		want        Resolution
+	}{
+		{"notes/x.md", "meeting", Resolution{Path: "Meeting.md"}},
+		{"notes/x.md", "work/meeting", Resolution{Path: "work/Meeting.md"}},
+		{"notes/x.md", "plan", Resolution{Path: "projects/Plan.md"}},
+		{"notes/x.md", "diagram.png", Resolution{Path: "img/diagram.png"}},
+		{"notes/x.md", "old plan", Resolution{Path: "archive/Old Plan.md"}},
+		{"notes/x.md", "missing", Resolution{}},
+		{"notes/x.md", "", Resolution{Path: "notes/x.md"}},
+	}
+	for _, tc := range cases {
+		if got := r.Resolve(tc.source, tc.key); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("Resolve(%q, %q) = %+v, want %+v", tc.source, tc.key, got, tc.want)
+		}
+	}
+
+	r = NewResolver([]string{"work/Meeting.md", "home/Meeting.md"})
+	if got := r.Resolve("home/today.md", "meeting"); got.Path != "home/Meeting.md" {
+		t.Fatalf("expected same-folder match, got %+v", got)
+	}
+	got := r.Resolve("daily/today.md", "meeting")
+	if !got.Ambiguous() || got.Path != "" || !reflect.DeepEqual(got.Candidates, []string{"home/Meeting.md", "work/Meeting.md"}) {
+		t.Fatalf("expected ambiguous resolution, got %+v", got)
+	}
+}
+
+func TestBacklinksForPathDoesNotGuessAmbiguousLinks(t *testing.T) {
+	idx := BacklinkIndex{SourceToTarget: map[string][]string{
+		"daily.md":          {"meeting"},
+		"work/Meeting.md":   {},
+		"home/Meeting.md":   {},
+		"work/standup.md":   {"meeting"},
+		"notes/explicit.md": {"home/meeting"},
+	}}
+	if got := BacklinksForPath("", idx, "work/Meeting.md"); !reflect.DeepEqual(got, []string{"work/standup.md"}) {
+		t.Fatalf("unexpected backlinks for work/Meeting.md: %v", got)
+	}
+	if got := BacklinksForPath("", idx, "home/Meeting.md"); !reflect.DeepEqual(got, []string{"notes/explicit.md"}) {
+		t.Fatalf("unexpected backlinks for home/Meeting.md: %v", got)
+	}
+}
+
+func TestResolverFallsBackToAliases(t *testing.T) {
+	r := NewResolver([]string{"projects/Roadmap.md", "Plan.md", "other/Notes.md"})
+	r.AddAlias("Q3 Plan", "projects/Roadmap.md")
+	r.AddAlias("Plan", "projects/Roadmap.md")
+	r.AddAlias("Shared", "projects/Roadmap.md")
+	r.AddAlias("shared", "other/Notes.md")
+
+	if got := r.Resolve("a.md", "q3 plan"); got.Path != "projects/Roadmap.md" {
+		t.Fatalf("expected alias resolution, got %+v", got)
+	}
+	if got := r.Resolve("a.md", "plan"); got.Path != "Plan.md" {
+		t.Fatalf("expected file name to win over alias, got %+v", got)
+	}
+	if got := r.Resolve("a.md", "shared"); !got.Ambiguous() || !reflect.DeepEqual(got.Candidates, []string{"other/Notes.md", "projects/Roadmap.md"}) {
+		t.Fatalf("expected conflicting alias to be ambiguous, got %+v", got)
+	}
+}
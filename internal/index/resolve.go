@@ -0,0 +1,126 @@
+package index
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/note"
+)
+
+// Resolution is the outcome of resolving one link target. Path is empty when
+// the link is unresolved or ambiguous; Candidates lists every matching file
+// when more than one could be meant.
+type Resolution struct {
+	Path       string   `json:"path,omitempty"`
+	Candidates []string `json:"candidates,omitempty"`
+}
+
+func (r Resolution) Ambiguous() bool {
+	return len(r.Candidates) > 1
+}
+
+// Resolver maps normalized link targets (see note.Link.Key) to vault files
+// the way Obsidian does: an exact vault path wins, then a match in the
+// linking note's folder, then a unique file whose path ends with the target.
+// Frontmatter aliases are tried only when no file matches.
+type Resolver struct {
+	exact   map[string]string
+	byName  map[string][]string
+	aliases map[string][]string
+}
+
+// NewResolver indexes the given vault-relative file paths.
+func NewResolver(paths []string) *Resolver {
+	r := &Resolver{exact: map[string]string{}, byName: map[string][]string{}, aliases: map[string][]string{}}
+	for _, rel := range paths {
+		r.Add(rel)
+	}
+	return r
+}
+
+// Add registers one more vault-relative file.
+func (r *Resolver) Add(rel string) {
+	key := linkKey(rel)
+	if key == "" {
+		return
+	}
+	if _, ok := r.exact[key]; ok {
+		return
+	}
+	r.exact[key] = rel
+	name := path.Base(key)
+	r.byName[name] = append(r.byName[name], rel)
+	sort.Strings(r.byName[name])
+}
+
+// AddAlias registers alias as another name for the file at rel.
+func (r *Resolver) AddAlias(alias, rel string) {
+	key := strings.ToLower(strings.TrimSpace(alias))
+	if key == "" {
+		return
+	}
+	for _, existing := range r.aliases[key] {
+		if existing == rel {
+			return
+		}
+	}
+	r.aliases[key] = append(r.aliases[key], rel)
+	sort.Strings(r.aliases[key])
+}
+
+// Resolve resolves key, a normalized target written in sourceRel. An empty
+// key is a same-note link such as [[#Heading]].
+func (r *Resolver) Resolve(sourceRel, key string) Resolution {
+	if key == "" {
+		return Resolution{Path: sourceRel}
+	}
+	if rel, ok := r.exact[key]; ok {
+		return Resolution{Path: rel}
+	}
+	candidates := []string{}
+	for _, rel := range r.byName[path.Base(key)] {
+		if strings.HasSuffix(linkKey(rel), "/"+key) {
+			candidates = append(candidates, rel)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return r.resolveAlias(key)
+	case 1:
+		return Resolution{Path: candidates[0]}
+	}
+	dir := path.Dir(sourceRel)
+	for _, rel := range candidates {
+		if path.Dir(rel) == dir {
+			return Resolution{Path: rel}
+		}
+	}
+	return Resolution{Candidates: candidates}
+}
+
+func (r *Resolver) resolveAlias(key string) Resolution {
+	switch paths := r.aliases[key]; len(paths) {
+	case 0:
+		return Resolution{}
+	case 1:
+		return Resolution{Path: paths[0]}
+	default:
+		return Resolution{Candidates: append([]string(nil), paths...)}
+	}
+}
+
+// ResolveLinks fills in Path, or Candidates for ambiguous targets, on links
+// written in sourceRel.
+func (r *Resolver) ResolveLinks(sourceRel string, links []note.Link) []note.Link {
+	for i := range links {
+		res := r.Resolve(sourceRel, links[i].Key(sourceRel))
+		links[i].Path, links[i].Candidates = res.Path, res.Candidates
+	}
+	return links
+}
+
+// linkKey is the normalized form of a vault path, matching note.Link.Key.
+func linkKey(rel string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(path.Clean(strings.ReplaceAll(rel, "\\", "/")), "/"), ".md"))
+}
@@ -31,7 +31,7 @@ func ExtractInlineTags(body string) []string {
 		if len(m) < 2 {
 			continue
 		}
-		tag := normalizeTag(m[1])
+		tag := NormalizeTag(m[1])
 		if tag == "" {
 			continue
 		}
@@ -58,29 +58,59 @@ func AggregateTags(vaultRoot string) (map[string]int, error) {
 		if readErr != nil {
 			return nil, readErr
 		}
-		seen := map[string]struct{}{}
-		for _, tag := range n.Frontmatter.Tags {
-			norm := normalizeTag(tag)
-			if norm == "" {
-				continue
-			}
-			seen[norm] = struct{}{}
-		}
-		for _, tag := range ExtractInlineTags(n.Body) {
-			seen[tag] = struct{}{}
-		}
-		for tag := range seen {
+		for _, tag := range NoteTags(n) {
 			counts[tag]++
 		}
 	}
 	return counts, nil
 }
 
+// NoteTags returns the normalized, de-duplicated frontmatter and inline tags of a note.
+func NoteTags(n note.Note) []string {
+	seen := map[string]struct{}{}
+	out := []string{}
+	add := func(tag string) {
+		norm := NormalizeTag(tag)
+		if norm == "" {
+			return
+		}
+		if _, ok := seen[norm]; ok {
+			return
+		}
+		seen[norm] = struct{}{}
+		out = append(out, norm)
+	}
+	for _, tag := range n.Frontmatter.Tags {
+		add(tag)
+	}
+	for _, tag := range ExtractInlineTags(n.Body) {
+		add(tag)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// HasTag reports whether a note carries tag in frontmatter or inline.
+func HasTag(n note.Note, tag string) bool {
+	norm := NormalizeTag(tag)
+	for _, t := range NoteTags(n) {
+		if t == norm {
+			return true
+		}
+	}
+	return false
+}
+
 func ListTags(vaultRoot string, opts TagListOptions) ([]TagCount, error) {
 	counts, err := AggregateTags(vaultRoot)
 	if err != nil {
 		return nil, err
 	}
+	return RankTags(counts, opts), nil
+}
+
+// RankTags converts tag counts into a sorted, limited list.
+func RankTags(counts map[string]int, opts TagListOptions) []TagCount {
 	items := make([]TagCount, 0, len(counts))
 	for tag, count := range counts {
 		items = append(items, TagCount{Tag: tag, Count: count})
@@ -101,11 +131,11 @@ func ListTags(vaultRoot string, opts TagListOptions) ([]TagCount, error) {
 	if opts.Limit > 0 && len(items) > opts.Limit {
 		items = items[:opts.Limit]
 	}
-	return items, nil
+	return items
 }
 
 func SearchTag(vaultRoot, tag string, limit int) ([]search.SearchResult, error) {
-	norm := normalizeTag(tag)
+	norm := NormalizeTag(tag)
 	if norm == "" {
 		return []search.SearchResult{}, nil
 	}
@@ -121,28 +151,8 @@ func SearchTag(vaultRoot, tag string, limit int) ([]search.SearchResult, error)
 		if readErr != nil {
 			return nil, readErr
 		}
-		found := false
-		for _, t := range n.Frontmatter.Tags {
-			if normalizeTag(t) == norm {
-				found = true
-				break
-			}
-		}
-		if !found {
-			for _, t := range ExtractInlineTags(n.Body) {
-				if t == norm {
-					found = true
-					break
-				}
-			}
-		}
-		if found {
-			results = append(results, search.SearchResult{
-				Path:      rel,
-				Match:     "#" + norm,
-				Snippet:   "tag match",
-				MatchType: "tag",
-			})
+		if HasTag(n, norm) {
+			results = append(results, TagMatch(rel, norm))
 			if limit > 0 && len(results) >= limit {
 				break
 			}
@@ -151,7 +161,44 @@ func SearchTag(vaultRoot, tag string, limit int) ([]search.SearchResult, error)
 	return results, nil
 }
 
+// TagMatch builds the search result reported for a note carrying tag.
+func TagMatch(relPath, tag string) search.SearchResult {
+	return search.SearchResult{
+		Path:      relPath,
+		Match:     "#" + NormalizeTag(tag),
+		Snippet:   "tag match",
+		MatchType: "tag",
+	}
+}
+
 func ListMarkdownFiles(root string) ([]string, error) {
+	return listFiles(root, ".md")
+}
+
+// ListCanvasFiles returns absolute paths of every .canvas file, skipping
+// hidden folders like ListMarkdownFiles.
+func ListCanvasFiles(root string) ([]string, error) {
+	return listFiles(root, ".canvas")
+}
+
+// ListVaultFiles returns absolute paths of every file in the vault,
+// attachments included, skipping hidden files and folders such as
+// .DS_Store and .obsidian-cli.yaml.
+func ListVaultFiles(root string) ([]string, error) {
+	files, err := listFiles(root, "")
+	if err != nil {
+		return nil, err
+	}
+	out := files[:0]
+	for _, path := range files {
+		if !strings.HasPrefix(filepath.Base(path), ".") {
+			out = append(out, path)
+		}
+	}
+	return out, nil
+}
+
+func listFiles(root, ext string) ([]string, error) {
 	files := []string{}
 	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
@@ -163,7 +210,7 @@ func ListMarkdownFiles(root string) ([]string, error) {
 			}
 			return nil
 		}
-		if strings.HasSuffix(strings.ToLower(d.Name()), ".md") {
+		if strings.HasSuffix(strings.ToLower(d.Name()), ext) {
 			files = append(files, path)
 		}
 		return nil
@@ -175,7 +222,8 @@ func ListMarkdownFiles(root string) ([]string, error) {
 	return files, nil
 }
 
-func normalizeTag(tag string) string {
+// NormalizeTag lowercases a tag and strips the leading '#'.
+func NormalizeTag(tag string) string {
 	clean := strings.TrimSpace(strings.TrimPrefix(tag, "#"))
 	return strings.ToLower(clean)
 }
@@ -0,0 +1,76 @@
+package note
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/history"
+)
+
+// AttachmentMove reports an attachment move and the notes whose embeds or
+// links were rewritten.
+type AttachmentMove struct {
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	Rewritten []string `json:"rewritten"`
+	DryRun    bool     `json:"dry_run"`
+}
+
+// MoveAttachment moves or renames a non-note file and rewrites every
+// ![[...]], ![](...) and plain link to it. A dst naming an existing folder,
+// or ending in "/", keeps the file name. Nothing is written when dryRun is
+// set.
+func MoveAttachment(vaultRoot string, hist *history.Store, src, dst string, resolve LinkResolver, dryRun bool) (AttachmentMove, error) {
+	srcAbs, srcRel, err := resolveVaultPath(vaultRoot, src)
+	if err != nil {
+		return AttachmentMove{}, err
+	}
+	if strings.EqualFold(path.Ext(srcRel), ".md") {
+		return AttachmentMove{}, errs.New(errs.ExitValidation, "use note move for notes")
+	}
+	if info, statErr := os.Stat(srcAbs); statErr != nil || info.IsDir() {
+		return AttachmentMove{}, errs.New(errs.ExitNotFound, "attachment not found")
+	}
+	intoFolder := strings.HasSuffix(strings.TrimSpace(strings.ReplaceAll(dst, "\\", "/")), "/")
+	dstAbs, dstRel, err := resolveVaultPath(vaultRoot, dst)
+	if err != nil {
+		return AttachmentMove{}, err
+	}
+	if info, statErr := os.Stat(dstAbs); intoFolder || (statErr == nil && info.IsDir()) {
+		dstRel = dstRel + "/" + path.Base(srcRel)
+		dstAbs = filepath.Join(dstAbs, filepath.Base(srcAbs))
+	}
+	if strings.EqualFold(path.Ext(dstRel), ".md") {
+		return AttachmentMove{}, errs.New(errs.ExitValidation, "attachments cannot be renamed to .md")
+	}
+	if dstRel == srcRel {
+		return AttachmentMove{}, errs.New(errs.ExitValidation, "source and destination are the same")
+	}
+	if _, statErr := os.Stat(dstAbs); statErr == nil {
+		return AttachmentMove{}, errs.New(errs.ExitValidation, "destination already exists")
+	}
+
+	rewrites, originals, rewritten, err := planLinkRewrites(vaultRoot, map[string]string{srcRel: dstRel}, resolve)
+	if err != nil {
+		return AttachmentMove{}, err
+	}
+	out := AttachmentMove{From: srcRel, To: dstRel, Rewritten: rewritten, DryRun: dryRun}
+	if dryRun {
+		return out, nil
+	}
+	if err := applyMove(vaultRoot, hist, rewrites, originals, func() error {
+		if err := hist.Touch(srcAbs, dstAbs); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(dstAbs), 0o755); err != nil {
+			return err
+		}
+		return os.Rename(srcAbs, dstAbs)
+	}); err != nil {
+		return AttachmentMove{}, err
+	}
+	return out, nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/history"
 )
 
 var blockIDPattern = regexp.MustCompile(`^[a-z0-9-]{3,}$`)
@@ -15,47 +16,56 @@ func GetBlock(vaultRoot, path, blockID string) (Block, error) {
 	if err != nil {
 		return Block{}, err
 	}
-	block, err := blockFromBody(n.Body, n.Path, blockID)
-	if err != nil {
-		return Block{}, err
-	}
-	return block, nil
+	return BlockFromNote(n, blockID)
 }
 
-func SetBlock(vaultRoot, path, blockID, content string) (Block, error) {
-	if strings.Contains(content, "\n") {
-		return Block{}, errs.New(errs.ExitValidation, "content must be a single line")
-	}
+// BlockFromNote locates a block reference in an already loaded note.
+func BlockFromNote(n Note, blockID string) (Block, error) {
+	return blockFromBody(n.Body, n.Path, blockID)
+}
 
+func SetBlock(vaultRoot string, hist *history.Store, path, blockID, content string) (Block, error) {
 	n, err := Read(vaultRoot, path)
 	if err != nil {
 		return Block{}, err
 	}
+	n, err = ReplaceBlock(n, blockID, content)
+	if err != nil {
+		return Block{}, err
+	}
+	updated, err := Write(vaultRoot, hist, n.Path, n, false, time.Now())
+	if err != nil {
+		return Block{}, err
+	}
+	return blockFromBody(updated.Body, updated.Path, blockID)
+}
+
+// ReplaceBlock swaps the content of a block in the note body without writing it.
+func ReplaceBlock(n Note, blockID, content string) (Note, error) {
+	if strings.Contains(content, "\n") {
+		return Note{}, errs.New(errs.ExitValidation, "content must be a single line")
+	}
 
 	lines := strings.Split(n.Body, "\n")
 	index, style, err := findBlockLine(lines, blockID)
 	if err != nil {
-		return Block{}, err
+		return Note{}, err
 	}
 
 	switch style {
 	case blockStyleAnchorOnly:
 		if index == 0 {
-			return Block{}, errs.New(errs.ExitValidation, "anchor-only block has no content line")
+			return Note{}, errs.New(errs.ExitValidation, "anchor-only block has no content line")
 		}
 		lines[index-1] = content
 	case blockStyleInline:
 		lines[index] = content + " ^" + blockID
 	default:
-		return Block{}, errs.New(errs.ExitGeneric, "unknown block style")
+		return Note{}, errs.New(errs.ExitGeneric, "unknown block style")
 	}
 
 	n.Body = strings.Join(lines, "\n")
-	updated, err := Write(vaultRoot, n.Path, n, false, time.Now())
-	if err != nil {
-		return Block{}, err
-	}
-	return blockFromBody(updated.Body, updated.Path, blockID)
+	return n, nil
 }
 
 type blockStyle int
@@ -98,6 +108,23 @@ func blockFromBody(body, path, blockID string) (Block, error) {
 	}
 }
 
+// BlockIDs lists the block reference IDs defined in a note body.
+func BlockIDs(body string) []string {
+	out := []string{}
+	for _, raw := range strings.Split(body, "\n") {
+		line := strings.TrimSpace(strings.TrimRight(raw, "\r"))
+		idx := strings.LastIndex(line, "^")
+		if idx < 0 || (idx > 0 && line[idx-1] != ' ') {
+			continue
+		}
+		id := line[idx+1:]
+		if blockIDPattern.MatchString(id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
 func findBlockLine(lines []string, blockID string) (int, blockStyle, error) {
 	cleanID := strings.TrimSpace(blockID)
 	if cleanID == "" {
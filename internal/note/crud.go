@@ -9,9 +9,27 @@ import (
 	"time"
 
 	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/history"
 )
 
-func Create(vaultRoot string, in CreateInput) (Note, error) {
+func Create(vaultRoot string, hist *history.Store, in CreateInput) (Note, error) {
+	n, err := Prepare(in)
+	if err != nil {
+		return Note{}, err
+	}
+
+	abs, normalized, err := resolveNoteAbs(vaultRoot, n.Path)
+	if err != nil {
+		return Note{}, err
+	}
+	if _, err := os.Stat(abs); err == nil {
+		return Note{}, errs.New(errs.ExitValidation, "note already exists")
+	}
+	return Write(vaultRoot, hist, normalized, n, true, time.Now())
+}
+
+// Prepare validates create input and builds the unsaved note, including its slugged path.
+func Prepare(in CreateInput) (Note, error) {
 	title := strings.TrimSpace(in.Title)
 	if title == "" {
 		return Note{}, errs.New(errs.ExitValidation, "title is required")
@@ -22,16 +40,12 @@ func Create(vaultRoot string, in CreateInput) (Note, error) {
 	if dir != "" {
 		rel = filepath.ToSlash(filepath.Join(dir, rel))
 	}
-
-	abs, normalized, err := resolveNoteAbs(vaultRoot, rel)
-	if err != nil {
-		return Note{}, err
-	}
-	if _, err := os.Stat(abs); err == nil {
-		return Note{}, errs.New(errs.ExitValidation, "note already exists")
+	normalized := normalizeNotePath(rel)
+	if strings.HasPrefix(normalized, "../") || normalized == ".." {
+		return Note{}, errs.New(errs.ExitValidation, "path escapes vault root")
 	}
 
-	n := Note{
+	return Note{
 		Path:  normalized,
 		Title: title,
 		Frontmatter: Frontmatter{
@@ -43,8 +57,7 @@ func Create(vaultRoot string, in CreateInput) (Note, error) {
 			Extra:  map[string]any{},
 		},
 		Body: in.Content,
-	}
-	return Write(vaultRoot, normalized, n, true, time.Now())
+	}, nil
 }
 
 func Get(vaultRoot, path string) (Note, error) {
@@ -89,7 +102,11 @@ func List(vaultRoot, dir string, opts ListOptions) ([]Note, error) {
 	if err != nil {
 		return nil, err
 	}
+	return SortNotes(entries, opts), nil
+}
 
+// SortNotes orders notes by the requested sort key and applies the limit.
+func SortNotes(entries []Note, opts ListOptions) []Note {
 	sortBy := opts.Sort
 	if sortBy == "" {
 		sortBy = "name"
@@ -124,58 +141,69 @@ func List(vaultRoot, dir string, opts ListOptions) ([]Note, error) {
 	if opts.Limit > 0 && len(entries) > opts.Limit {
 		entries = entries[:opts.Limit]
 	}
-	return entries, nil
+	return entries
 }
 
-func Delete(vaultRoot, path string) error {
-	abs, _, err := resolveNoteAbs(vaultRoot, path)
+// Delete removes a note, moving it to the vault trash unless the vault's
+// trashOption is "none".
+func Delete(vaultRoot string, hist *history.Store, path string) error {
+	abs, normalized, err := resolveNoteAbs(vaultRoot, path)
 	if err != nil {
 		return err
 	}
-	if err := os.Remove(abs); err != nil {
+	if _, err := os.Stat(abs); err != nil {
 		if os.IsNotExist(err) {
 			return errs.New(errs.ExitNotFound, "note not found")
 		}
 		return err
 	}
-	return nil
+	_, err = Remove(vaultRoot, hist, normalized)
+	return err
 }
 
-func Append(vaultRoot, path, content string) (Note, error) {
-	return AppendWithOptions(vaultRoot, path, content, false)
+func Append(vaultRoot string, hist *history.Store, path, content string) (Note, error) {
+	return AppendWithOptions(vaultRoot, hist, path, content, false)
 }
 
-func AppendWithOptions(vaultRoot, path, content string, inline bool) (Note, error) {
+func AppendWithOptions(vaultRoot string, hist *history.Store, path, content string, inline bool) (Note, error) {
 	n, err := Read(vaultRoot, path)
 	if err != nil {
 		return Note{}, err
 	}
-	text := content
-	if !inline && n.Body != "" && !strings.HasSuffix(n.Body, "\n") {
-		n.Body += "\n"
+	n.Body = AppendText(n.Body, content, inline)
+	return Write(vaultRoot, hist, n.Path, n, false, time.Now())
+}
+
+// AppendText returns body with content appended, starting a new line unless inline.
+func AppendText(body, content string, inline bool) string {
+	if !inline && body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
 	}
-	n.Body += text
-	return Write(vaultRoot, n.Path, n, false, time.Now())
+	return body + content
 }
 
-func Prepend(vaultRoot, path, content string) (Note, error) {
-	return PrependWithOptions(vaultRoot, path, content, false)
+func Prepend(vaultRoot string, hist *history.Store, path, content string) (Note, error) {
+	return PrependWithOptions(vaultRoot, hist, path, content, false)
 }
 
-func PrependWithOptions(vaultRoot, path, content string, inline bool) (Note, error) {
+func PrependWithOptions(vaultRoot string, hist *history.Store, path, content string, inline bool) (Note, error) {
 	n, err := Read(vaultRoot, path)
 	if err != nil {
 		return Note{}, err
 	}
-	text := content
-	if !inline && text != "" && !strings.HasSuffix(text, "\n") {
-		text += "\n"
+	n.Body = PrependText(n.Body, content, inline)
+	return Write(vaultRoot, hist, n.Path, n, false, time.Now())
+}
+
+// PrependText returns body with content prepended on its own line unless inline.
+func PrependText(body, content string, inline bool) string {
+	if !inline && content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
 	}
-	n.Body = text + n.Body
-	return Write(vaultRoot, n.Path, n, false, time.Now())
+	return content + body
 }
 
-func Move(vaultRoot, src, dst string, opts MoveOptions) (Note, []string, error) {
+func Move(vaultRoot string, hist *history.Store, src, dst string, opts MoveOptions) (Note, []string, error) {
 	srcAbs, srcNorm, err := resolveNoteAbs(vaultRoot, src)
 	if err != nil {
 		return Note{}, nil, err
@@ -203,11 +231,17 @@ func Move(vaultRoot, src, dst string, opts MoveOptions) (Note, []string, error)
 		n.Path = dstNorm
 		n.Title = titleFromPath(dstNorm)
 		if opts.UpdateLinks {
-			rewritten, _ = RewriteLinks(vaultRoot, srcNorm, dstNorm, true)
+			rewritten, _ = RewriteLinks(vaultRoot, hist, srcNorm, dstNorm, true)
 		}
 		return n, rewritten, nil
 	}
 
+	if err := hist.Capture(srcAbs); err != nil {
+		return Note{}, nil, err
+	}
+	if err := hist.Touch(dstAbs); err != nil {
+		return Note{}, nil, err
+	}
 	if err := os.MkdirAll(filepath.Dir(dstAbs), 0o755); err != nil {
 		return Note{}, nil, err
 	}
@@ -219,13 +253,13 @@ func Move(vaultRoot, src, dst string, opts MoveOptions) (Note, []string, error)
 	if err != nil {
 		return Note{}, nil, err
 	}
-	n, err = Write(vaultRoot, dstNorm, n, false, time.Now())
+	n, err = Write(vaultRoot, hist, dstNorm, n, false, time.Now())
 	if err != nil {
 		return Note{}, nil, err
 	}
 
 	if opts.UpdateLinks {
-		rewritten, err = RewriteLinks(vaultRoot, srcNorm, dstNorm, false)
+		rewritten, err = RewriteLinks(vaultRoot, hist, srcNorm, dstNorm, false)
 		if err != nil {
 			return Note{}, nil, err
 		}
@@ -9,7 +9,7 @@ import (
 func TestNoteCRUDLifecycle(t *testing.T) {
 	root := t.TempDir()
 
-	created, err := Create(root, CreateInput{Title: "My Test Note", Tags: []string{"alpha"}})
+	created, err := Create(root, nil, CreateInput{Title: "My Test Note", Tags: []string{"alpha"}})
 	if err != nil {
 		t.Fatalf("Create error: %v", err)
 	}
@@ -28,7 +28,7 @@ func TestNoteCRUDLifecycle(t *testing.T) {
 		t.Fatalf("unexpected title: %s", got.Title)
 	}
 
-	appended, err := Append(root, created.Path, "line one")
+	appended, err := Append(root, nil, created.Path, "line one")
 	if err != nil {
 		t.Fatalf("Append error: %v", err)
 	}
@@ -36,7 +36,7 @@ func TestNoteCRUDLifecycle(t *testing.T) {
 		t.Fatalf("unexpected appended body: %q", appended.Body)
 	}
 
-	prepended, err := Prepend(root, created.Path, "intro")
+	prepended, err := Prepend(root, nil, created.Path, "intro")
 	if err != nil {
 		t.Fatalf("Prepend error: %v", err)
 	}
@@ -52,7 +52,7 @@ func TestNoteCRUDLifecycle(t *testing.T) {
 		t.Fatalf("expected 1 note, got %d", len(listed))
 	}
 
-	if err := Delete(root, created.Path); err != nil {
+	if err := Delete(root, nil, created.Path); err != nil {
 		t.Fatalf("Delete error: %v", err)
 	}
 	_, err = Get(root, created.Path)
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/history"
 )
 
 type dailyNotesConfig struct {
@@ -16,10 +17,19 @@ type dailyNotesConfig struct {
 }
 
 func ResolveDailyPath(vaultRoot string, at time.Time) (string, error) {
-	cfg := dailyNotesConfig{Format: "YYYY-MM-DD"}
 	path := filepath.Join(vaultRoot, ".obsidian", "daily-notes.json")
 	payload, err := os.ReadFile(path)
-	if err == nil {
+	if err != nil {
+		payload = nil
+	}
+	return DailyPathFromConfig(payload, at), nil
+}
+
+// DailyPathFromConfig resolves the daily note path from raw daily-notes.json content.
+// Missing or malformed config falls back to YYYY-MM-DD in the vault root.
+func DailyPathFromConfig(payload []byte, at time.Time) string {
+	cfg := dailyNotesConfig{Format: "YYYY-MM-DD"}
+	if len(payload) > 0 {
 		_ = json.Unmarshal(payload, &cfg)
 	}
 
@@ -31,12 +41,12 @@ func ResolveDailyPath(vaultRoot string, at time.Time) (string, error) {
 
 	folder := strings.Trim(strings.ReplaceAll(cfg.Folder, "\\", "/"), "/")
 	if folder == "" {
-		return filepath.ToSlash(name), nil
+		return filepath.ToSlash(name)
 	}
-	return filepath.ToSlash(filepath.Join(folder, name)), nil
+	return filepath.ToSlash(filepath.Join(folder, name))
 }
 
-func EnsureExists(vaultRoot, path string) (Note, error) {
+func EnsureExists(vaultRoot string, hist *history.Store, path string) (Note, error) {
 	abs, normalized, err := resolveNoteAbs(vaultRoot, path)
 	if err != nil {
 		return Note{}, err
@@ -45,7 +55,7 @@ func EnsureExists(vaultRoot, path string) (Note, error) {
 		return Read(vaultRoot, normalized)
 	}
 
-	created, err := Write(vaultRoot, normalized, Note{
+	created, err := Write(vaultRoot, hist, normalized, Note{
 		Path:  normalized,
 		Title: titleFromPath(normalized),
 		Frontmatter: Frontmatter{
@@ -59,14 +69,14 @@ func EnsureExists(vaultRoot, path string) (Note, error) {
 	return created, nil
 }
 
-func DailyRead(vaultRoot string, at time.Time, create bool) (Note, string, error) {
+func DailyRead(vaultRoot string, hist *history.Store, at time.Time, create bool) (Note, string, error) {
 	path, err := ResolveDailyPath(vaultRoot, at)
 	if err != nil {
 		return Note{}, "", err
 	}
 
 	if create {
-		n, createErr := EnsureExists(vaultRoot, path)
+		n, createErr := EnsureExists(vaultRoot, hist, path)
 		return n, path, createErr
 	}
 
@@ -77,27 +87,27 @@ func DailyRead(vaultRoot string, at time.Time, create bool) (Note, string, error
 	return n, path, nil
 }
 
-func DailyAppend(vaultRoot string, at time.Time, content string, inline bool) (Note, string, error) {
+func DailyAppend(vaultRoot string, hist *history.Store, at time.Time, content string, inline bool) (Note, string, error) {
 	path, err := ResolveDailyPath(vaultRoot, at)
 	if err != nil {
 		return Note{}, "", err
 	}
-	if _, err := EnsureExists(vaultRoot, path); err != nil {
+	if _, err := EnsureExists(vaultRoot, hist, path); err != nil {
 		return Note{}, "", err
 	}
-	n, err := AppendWithOptions(vaultRoot, path, content, inline)
+	n, err := AppendWithOptions(vaultRoot, hist, path, content, inline)
 	return n, path, err
 }
 
-func DailyPrepend(vaultRoot string, at time.Time, content string, inline bool) (Note, string, error) {
+func DailyPrepend(vaultRoot string, hist *history.Store, at time.Time, content string, inline bool) (Note, string, error) {
 	path, err := ResolveDailyPath(vaultRoot, at)
 	if err != nil {
 		return Note{}, "", err
 	}
-	if _, err := EnsureExists(vaultRoot, path); err != nil {
+	if _, err := EnsureExists(vaultRoot, hist, path); err != nil {
 		return Note{}, "", err
 	}
-	n, err := PrependWithOptions(vaultRoot, path, content, inline)
+	n, err := PrependWithOptions(vaultRoot, hist, path, content, inline)
 	return n, path, err
 }
 
@@ -28,7 +28,7 @@ func TestDailyPathAndReadCreate(t *testing.T) {
 		t.Fatalf("unexpected daily path: %s", path)
 	}
 
-	n, resolved, err := DailyRead(root, at, true)
+	n, resolved, err := DailyRead(root, nil, at, true)
 	if err != nil {
 		t.Fatalf("DailyRead create error: %v", err)
 	}
@@ -79,7 +79,7 @@ func TestGetAndSetBlock(t *testing.T) {
 		t.Fatalf("unexpected anchor block: %+v", anchor)
 	}
 
-	updated, err := SetBlock(root, "block.md", "para1", "new text")
+	updated, err := SetBlock(root, nil, "block.md", "para1", "new text")
 	if err != nil {
 		t.Fatalf("SetBlock error: %v", err)
 	}
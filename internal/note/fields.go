@@ -0,0 +1,53 @@
+package note
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	inlineFieldLinePattern    = regexp.MustCompile(`^\s*(?:[-*+]\s+(?:\[.\]\s+)?)?([\p{L}\p{N}_][\p{L}\p{N}_ -]*?)::\s*(.*?)\s*$`)
+	inlineFieldBracketPattern = regexp.MustCompile(`[\[(]([\p{L}\p{N}_][\p{L}\p{N}_ -]*?)::\s*([^\])]*?)\s*[\])]`)
+)
+
+// InlineFields collects Dataview-style inline fields ("key:: value" on its
+// own line, or "[key:: value]" / "(key:: value)" inside text). A key seen
+// more than once yields a list of its values.
+func InlineFields(body string) map[string]any {
+	out := map[string]any{}
+	add := func(key, value string) {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return
+		}
+		switch prev := out[key].(type) {
+		case nil:
+			out[key] = value
+		case string:
+			out[key] = []any{prev, value}
+		case []any:
+			out[key] = append(prev, value)
+		}
+	}
+	inFence := false
+	for _, raw := range strings.Split(body, "\n") {
+		line := strings.TrimRight(raw, "\r")
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if matches := inlineFieldBracketPattern.FindAllStringSubmatch(line, -1); len(matches) > 0 {
+			for _, m := range matches {
+				add(m[1], m[2])
+			}
+			continue
+		}
+		if m := inlineFieldLinePattern.FindStringSubmatch(line); len(m) == 3 {
+			add(m[1], m[2])
+		}
+	}
+	return out
+}
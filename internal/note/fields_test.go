@@ -0,0 +1,20 @@
+package note
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInlineFields(t *testing.T) {
+	body := "owner:: ana\n- [ ] task [due:: 2024-01-02] (rating:: 4)\nTags:: a\nTags:: b\n```\nignored:: yes\n```\nurl: http://x\n"
+	got := InlineFields(body)
+	want := map[string]any{
+		"owner":  "ana",
+		"due":    "2024-01-02",
+		"rating": "4",
+		"Tags":   []any{"a", "b"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("InlineFields = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,266 @@
+package note
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/history"
+)
+
+// FileMove is one file relocated by a folder move.
+type FileMove struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// FolderMove reports a folder move: every file relocated, notes included
+// or not, and the notes whose links were rewritten, by their new path.
+type FolderMove struct {
+	Source      string     `json:"source"`
+	Destination string     `json:"destination"`
+	Moves       []FileMove `json:"moves"`
+	Rewritten   []string   `json:"rewritten"`
+	DryRun      bool       `json:"dry_run"`
+}
+
+// LinkResolver returns the vault path link, found in the note at
+// sourceRel, points at, or "" when it is unresolved or ambiguous.
+type LinkResolver func(sourceRel string, link Link) string
+
+// MoveFolder moves every file under src to the same place under dst and
+// rewrites the links that point into the folder, plus relative links
+// leaving it, in a single pass over the vault. It refuses to
+// overwrite existing files and writes nothing when dryRun is set.
+func MoveFolder(vaultRoot string, hist *history.Store, src, dst string, resolve LinkResolver, dryRun bool) (FolderMove, error) {
+	srcAbs, srcRel, err := resolveVaultPath(vaultRoot, src)
+	if err != nil {
+		return FolderMove{}, err
+	}
+	dstAbs, dstRel, err := resolveVaultPath(vaultRoot, dst)
+	if err != nil {
+		return FolderMove{}, err
+	}
+	if info, statErr := os.Stat(srcAbs); statErr != nil || !info.IsDir() {
+		return FolderMove{}, errs.New(errs.ExitNotFound, "source folder not found")
+	}
+	if dstRel == srcRel || strings.HasPrefix(dstRel, srcRel+"/") {
+		return FolderMove{}, errs.New(errs.ExitValidation, "destination must not be the source folder or inside it")
+	}
+	if info, statErr := os.Stat(dstAbs); statErr == nil && !info.IsDir() {
+		return FolderMove{}, errs.New(errs.ExitValidation, "destination exists and is not a folder")
+	}
+
+	out := FolderMove{Source: srcRel, Destination: dstRel, Moves: []FileMove{}, Rewritten: []string{}, DryRun: dryRun}
+	moved := map[string]string{}
+	conflicts := []string{}
+	err = filepath.WalkDir(srcAbs, func(p string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil || d.IsDir() {
+			return walkErr
+		}
+		rel, _ := filepath.Rel(vaultRoot, p)
+		rel = filepath.ToSlash(rel)
+		to := dstRel + strings.TrimPrefix(rel, srcRel)
+		if _, statErr := os.Stat(filepath.Join(vaultRoot, filepath.FromSlash(to))); statErr == nil {
+			conflicts = append(conflicts, to)
+		}
+		moved[rel] = to
+		out.Moves = append(out.Moves, FileMove{From: rel, To: to})
+		return nil
+	})
+	if err != nil {
+		return FolderMove{}, err
+	}
+	if len(conflicts) > 0 {
+		return FolderMove{}, errs.NewDetailed(
+			errs.ExitValidation,
+			"destination_exists",
+			"Move or remove the conflicting files first, or pick another destination.",
+			"folder move would overwrite existing files: "+strings.Join(conflicts, ", "),
+		)
+	}
+
+	rewrites, originals, rewritten, err := planLinkRewrites(vaultRoot, moved, resolve)
+	if err != nil {
+		return FolderMove{}, err
+	}
+	out.Rewritten = rewritten
+	if dryRun {
+		return out, nil
+	}
+	if err := applyMove(vaultRoot, hist, rewrites, originals, func() error {
+		for _, m := range out.Moves {
+			if err := hist.Touch(filepath.Join(vaultRoot, filepath.FromSlash(m.From)), filepath.Join(vaultRoot, filepath.FromSlash(m.To))); err != nil {
+				return err
+			}
+		}
+		return moveFolderFiles(vaultRoot, srcAbs, dstAbs, out.Moves)
+	}); err != nil {
+		return FolderMove{}, err
+	}
+	return out, nil
+}
+
+// planLinkRewrites returns the new content of every note whose links
+// change with moved, plus the original content, keyed by current path, and
+// the sorted post-move paths of those notes.
+func planLinkRewrites(vaultRoot string, moved map[string]string, resolve LinkResolver) (map[string]string, map[string]string, []string, error) {
+	paths, err := listMarkdown(vaultRoot)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rewrites := map[string]string{}
+	originals := map[string]string{}
+	rewritten := []string{}
+	for _, abs := range paths {
+		rel, _ := filepath.Rel(vaultRoot, abs)
+		rel = filepath.ToSlash(rel)
+		raw, readErr := os.ReadFile(abs)
+		if readErr != nil {
+			return nil, nil, nil, readErr
+		}
+		content := string(raw)
+		updated := rewriteMovedLinks(content, rel, moved, resolve)
+		if updated == content {
+			continue
+		}
+		rewrites[rel], originals[rel] = updated, content
+		if to, ok := moved[rel]; ok {
+			rewritten = append(rewritten, to)
+		} else {
+			rewritten = append(rewritten, rel)
+		}
+	}
+	sort.Strings(rewritten)
+	return rewrites, originals, rewritten, nil
+}
+
+// applyMove writes the planned rewrites and then runs move, restoring the
+// original note contents when either step fails.
+func applyMove(vaultRoot string, hist *history.Store, rewrites, originals map[string]string, move func() error) error {
+	written := []string{}
+	rollback := func(cause error) error {
+		for _, rel := range written {
+			_ = os.WriteFile(filepath.Join(vaultRoot, filepath.FromSlash(rel)), []byte(originals[rel]), 0o644)
+		}
+		return errs.Wrap(errs.ExitGeneric, "move failed, changes rolled back", cause)
+	}
+	for rel, content := range rewrites {
+		abs := filepath.Join(vaultRoot, filepath.FromSlash(rel))
+		if err := hist.WriteFile(abs, []byte(content)); err != nil {
+			return rollback(err)
+		}
+		written = append(written, rel)
+	}
+	if err := move(); err != nil {
+		return rollback(err)
+	}
+	return nil
+}
+
+// rewriteMovedLinks rewrites the links of content, the note at sourceRel
+// before the move, whose target or relative base changes with moved.
+func rewriteMovedLinks(content, sourceRel string, moved map[string]string, resolve LinkResolver) string {
+	newSource, sourceMoved := moved[sourceRel]
+	if !sourceMoved {
+		newSource = sourceRel
+	}
+	var b strings.Builder
+	last := 0
+	for _, l := range ParseLinks(content) {
+		target := ""
+		if l.Target != "" {
+			target = resolve(sourceRel, l)
+		}
+		if target == "" {
+			continue
+		}
+		newTarget, targetMoved := moved[target]
+		relative := strings.HasPrefix(l.Target, "./") || strings.HasPrefix(l.Target, "../")
+		if !targetMoved && !(sourceMoved && relative) {
+			continue
+		}
+		if !targetMoved {
+			newTarget = target
+		}
+		b.WriteString(content[last:l.start])
+		if l.wiki {
+			b.WriteString(rewriteWikiLink(l, newTarget))
+		} else {
+			b.WriteString(rewriteMarkdownLink(l, newSource, newTarget))
+		}
+		last = l.end
+	}
+	if last == 0 {
+		return content
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// moveFolderFiles renames the whole folder when dst does not exist yet and
+// otherwise moves file by file, undoing earlier renames on failure.
+func moveFolderFiles(vaultRoot, srcAbs, dstAbs string, moves []FileMove) error {
+	if err := os.MkdirAll(filepath.Dir(dstAbs), 0o755); err != nil {
+		return err
+	}
+	if _, err := os.Stat(dstAbs); os.IsNotExist(err) {
+		return os.Rename(srcAbs, dstAbs)
+	}
+	done := []FileMove{}
+	for _, m := range moves {
+		from := filepath.Join(vaultRoot, filepath.FromSlash(m.From))
+		to := filepath.Join(vaultRoot, filepath.FromSlash(m.To))
+		err := os.MkdirAll(filepath.Dir(to), 0o755)
+		if err == nil {
+			err = os.Rename(from, to)
+		}
+		if err != nil {
+			for i := len(done) - 1; i >= 0; i-- {
+				_ = os.Rename(filepath.Join(vaultRoot, filepath.FromSlash(done[i].To)), filepath.Join(vaultRoot, filepath.FromSlash(done[i].From)))
+			}
+			return err
+		}
+		done = append(done, m)
+	}
+	return removeEmptyDirs(srcAbs)
+}
+
+// removeEmptyDirs deletes root and its subfolders when they hold no files.
+func removeEmptyDirs(root string) error {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			return nil
+		}
+		if err := removeEmptyDirs(filepath.Join(root, e.Name())); err != nil {
+			return err
+		}
+	}
+	if entries, err = os.ReadDir(root); err != nil || len(entries) > 0 {
+		return err
+	}
+	return os.Remove(root)
+}
+
+// resolveVaultPath cleans a vault-relative file or folder path, rejecting
+// the vault root itself and paths that escape it.
+func resolveVaultPath(vaultRoot, rel string) (string, string, error) {
+	clean := strings.Trim(strings.ReplaceAll(strings.TrimSpace(rel), "\\", "/"), "/")
+	clean = path.Clean(clean)
+	if clean == "." || clean == "" {
+		return "", "", errs.New(errs.ExitValidation, "path is required and cannot be the vault root")
+	}
+	abs := filepath.Clean(filepath.Join(vaultRoot, filepath.FromSlash(clean)))
+	root := filepath.Clean(vaultRoot)
+	if !strings.HasPrefix(abs, root+string(filepath.Separator)) {
+		return "", "", errs.New(errs.ExitValidation, "path escapes vault root")
+	}
+	return abs, clean, nil
+}
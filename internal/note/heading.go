@@ -14,7 +14,11 @@ func ReadHeading(vaultRoot, path, heading string) (HeadingSection, error) {
 	if err != nil {
 		return HeadingSection{}, err
 	}
+	return SectionFromNote(n, heading)
+}
 
+// SectionFromNote extracts a heading section from an already loaded note.
+func SectionFromNote(n Note, heading string) (HeadingSection, error) {
 	target := normalizeHeading(heading)
 	if target == "" {
 		return HeadingSection{}, errs.New(errs.ExitValidation, "heading is required")
@@ -65,6 +69,49 @@ func ReadHeading(vaultRoot, path, heading string) (HeadingSection, error) {
 	}, nil
 }
 
+// Headings lists the headings of a note body in document order.
+func Headings(body string) []Heading {
+	out := []Heading{}
+	for i, line := range strings.Split(body, "\n") {
+		m := headingLinePattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if len(m) != 3 {
+			continue
+		}
+		out = append(out, Heading{Level: len(m[1]), Text: strings.TrimSpace(m[2]), Line: i + 1})
+	}
+	return out
+}
+
+// fencedHeadings lists the headings of a note body like Headings, leaving
+// out heading-like lines inside code fences.
+func fencedHeadings(body string) []Heading {
+	out := []Heading{}
+	inFence := false
+	fence := ""
+	for i, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if marker := fenceMarker(trimmed); marker != "" {
+			if !inFence {
+				inFence, fence = true, marker
+				continue
+			}
+			if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+				continue
+			}
+		}
+		if inFence {
+			continue
+		}
+		m := headingLinePattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if len(m) != 3 {
+			continue
+		}
+		out = append(out, Heading{Level: len(m[1]), Text: strings.TrimSpace(m[2]), Line: i + 1})
+	}
+	return out
+}
+
 func normalizeHeading(raw string) string {
 	out := strings.TrimSpace(raw)
 	out = strings.TrimSpace(strings.TrimPrefix(out, "#"))
@@ -0,0 +1,216 @@
+package note
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/frontmatter"
+	"github.com/nightisyang/obsidian-cli/internal/history"
+)
+
+// HeadingRename reports a heading rename and the notes it touched,
+// including the note holding the heading.
+type HeadingRename struct {
+	Path    string   `json:"path"`
+	Old     string   `json:"old"`
+	New     string   `json:"new"`
+	Level   int      `json:"level"`
+	Changed []string `json:"changed"`
+	DryRun  bool     `json:"dry_run"`
+}
+
+// LinkFilter reports whether link, found in the note at sourceRel, points
+// at the note whose heading is being renamed.
+type LinkFilter func(sourceRel string, link Link) bool
+
+// RenameHeading renames the first heading of relPath matching oldHeading
+// and rewrites every #anchor that targets it in notes where targets
+// matches. Nothing is written when dryRun is set.
+func RenameHeading(vaultRoot string, hist *history.Store, relPath, oldHeading, newHeading string, targets LinkFilter, dryRun bool) (HeadingRename, error) {
+	newHeading = strings.TrimSpace(newHeading)
+	if newHeading == "" || strings.ContainsAny(newHeading, "\r\n") {
+		return HeadingRename{}, errs.New(errs.ExitValidation, "new heading must be a single non-empty line")
+	}
+	abs, rel, err := resolveNoteAbs(vaultRoot, relPath)
+	if err != nil {
+		return HeadingRename{}, err
+	}
+	content, err := os.ReadFile(abs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return HeadingRename{}, errs.New(errs.ExitNotFound, "note not found")
+		}
+		return HeadingRename{}, err
+	}
+	renamed, found, err := renameHeadingLine(string(content), oldHeading, newHeading)
+	if err != nil {
+		return HeadingRename{}, err
+	}
+	out := HeadingRename{Path: rel, Old: found.Text, New: newHeading, Level: found.Level, Changed: []string{}, DryRun: dryRun}
+
+	paths, err := listMarkdown(vaultRoot)
+	if err != nil {
+		return HeadingRename{}, err
+	}
+	for _, srcAbs := range paths {
+		srcRel, _ := filepath.Rel(vaultRoot, srcAbs)
+		srcRel = filepath.ToSlash(srcRel)
+		original := renamed
+		if srcRel != rel {
+			raw, readErr := os.ReadFile(srcAbs)
+			if readErr != nil {
+				return HeadingRename{}, readErr
+			}
+			original = string(raw)
+		}
+		updated := RewriteHeadingLinkText(original, srcRel, found.Text, newHeading, func(l Link) bool {
+			if l.Target == "" {
+				return srcRel == rel
+			}
+			return targets(srcRel, l)
+		})
+		if srcRel != rel && updated == original {
+			continue
+		}
+		out.Changed = append(out.Changed, srcRel)
+		if !dryRun {
+			if err := hist.WriteFile(srcAbs, []byte(updated)); err != nil {
+				return HeadingRename{}, err
+			}
+		}
+	}
+	return out, nil
+}
+
+// RewriteHeadingLinkText points the #oldHeading anchors of the links in
+// content accepted by match at newHeading. Nested subpaths such as
+// [[Note#Parent#Old]] keep their other parts, and aliases and markdown
+// link titles are preserved.
+func RewriteHeadingLinkText(content, sourceRel, oldHeading, newHeading string, match func(Link) bool) string {
+	want := anchorHeading(oldHeading)
+	replacement := linkHeading(newHeading)
+	var b strings.Builder
+	last := 0
+	for _, l := range ParseLinks(content) {
+		if l.Heading == "" || !match(l) {
+			continue
+		}
+		parts := strings.Split(l.Heading, "#")
+		hit := false
+		for i, part := range parts {
+			if anchorHeading(part) == want {
+				parts[i], hit = replacement, true
+			}
+		}
+		if !hit {
+			continue
+		}
+		l.Heading = strings.Join(parts, "#")
+		b.WriteString(content[last:l.start])
+		if l.wiki {
+			b.WriteString(rewriteWikiLink(l, l.Target))
+		} else {
+			b.WriteString(rewriteMarkdownAnchor(l))
+		}
+		last = l.end
+	}
+	if last == 0 {
+		return content
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// renameHeadingLine rewrites the first heading matching oldHeading outside
+// frontmatter and code fences, keeping its level.
+func renameHeadingLine(content, oldHeading, newHeading string) (string, Heading, error) {
+	target := normalizeHeading(oldHeading)
+	if target == "" {
+		return "", Heading{}, errs.New(errs.ExitValidation, "heading is required")
+	}
+	_, _, inFrontmatter, _ := frontmatter.ParseDocument(content)
+	lines := strings.SplitAfter(content, "\n")
+	index := -1
+	found := Heading{}
+	inFence := false
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if inFrontmatter {
+			if i > 0 && trimmed == "---" {
+				inFrontmatter = false
+			}
+			continue
+		}
+		if marker := fenceMarker(trimmed); marker != "" {
+			if !inFence {
+				inFence, fence = true, marker
+				continue
+			}
+			if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+				continue
+			}
+		}
+		if inFence {
+			continue
+		}
+		m := headingLinePattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+		if len(m) != 3 {
+			continue
+		}
+		text := normalizeHeading(m[2])
+		if index < 0 && text == target {
+			index = i
+			found = Heading{Level: len(m[1]), Text: strings.TrimSpace(m[2]), Line: i + 1}
+			continue
+		}
+		if text == normalizeHeading(newHeading) {
+			return "", Heading{}, errs.New(errs.ExitValidation, "heading already exists: "+strings.TrimSpace(m[2]))
+		}
+	}
+	if index < 0 {
+		return "", Heading{}, errs.New(errs.ExitNotFound, "heading not found")
+	}
+	ending := lines[index][len(strings.TrimRight(lines[index], "\r\n")):]
+	lines[index] = strings.Repeat("#", found.Level) + " " + newHeading + ending
+	return strings.Join(lines, ""), found, nil
+}
+
+// linkHeading turns heading text into a link subpath, replacing the
+// characters links cannot carry the way Obsidian does.
+func linkHeading(text string) string {
+	replaced := strings.NewReplacer("#", " ", ":", " ", "|", " ", "^", " ", "%", " ", "[", " ", "]", " ").Replace(text)
+	return strings.Join(strings.Fields(replaced), " ")
+}
+
+// rewriteMarkdownAnchor swaps the #fragment of a markdown link for
+// l.Heading, keeping the path, title and angle-bracket form as written.
+func rewriteMarkdownAnchor(l Link) string {
+	prefix := "["
+	if l.Kind == LinkEmbed {
+		prefix = "!["
+	}
+	head := prefix + l.Text + "]("
+	dest := strings.TrimSuffix(l.Raw[len(head):], ")")
+	hash := strings.Index(dest, "#")
+	if hash < 0 {
+		return l.Raw
+	}
+	end := len(dest)
+	angle := strings.HasPrefix(strings.TrimSpace(dest), "<")
+	if angle {
+		if idx := strings.Index(dest, ">"); idx > hash {
+			end = idx
+		}
+	} else if idx := strings.Index(dest, ` "`); idx > hash && strings.HasSuffix(dest, `"`) {
+		end = idx
+	}
+	fragment := l.Heading
+	if !angle {
+		fragment = strings.ReplaceAll(fragment, " ", "%20")
+	}
+	return head + dest[:hash+1] + fragment + dest[end:] + ")"
+}
@@ -0,0 +1,46 @@
+package note
+
+import "testing"
+
+func TestRewriteHeadingLinkText(t *testing.T) {
+	content := "See [[Plan#Old Goals|goals]], ![[Plan#Intro#Old Goals]], [x](Plan.md#Old%20Goals \"t\"), [y](<Plan.md#old goals>) and [[Other#Old Goals]]."
+	got := RewriteHeadingLinkText(content, "daily.md", "Old Goals", "Q3: Goals", func(l Link) bool { return l.Target != "Other" })
+	want := "See [[Plan#Q3 Goals|goals]], ![[Plan#Intro#Q3 Goals]], [x](Plan.md#Q3%20Goals \"t\"), [y](<Plan.md#Q3 Goals>) and [[Other#Old Goals]]."
+	if got != want {
+		t.Fatalf("unexpected rewrite:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestRenameHeadingLine(t *testing.T) {
+	content := "---\ntitle: x\n---\n# Plan\n\n## Old Goals\r\ntext\n## Next\n"
+	got, found, err := renameHeadingLine(content, "old goals", "Goals")
+	if err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if found.Level != 2 || found.Text != "Old Goals" {
+		t.Fatalf("unexpected heading %+v", found)
+	}
+	if want := "---\ntitle: x\n---\n# Plan\n\n## Goals\r\ntext\n## Next\n"; got != want {
+		t.Fatalf("unexpected content %q", got)
+	}
+	if _, _, err := renameHeadingLine(content, "Old Goals", "next"); err == nil {
+		t.Fatal("expected duplicate heading error")
+	}
+	if _, _, err := renameHeadingLine(content, "Missing", "New"); err == nil {
+		t.Fatal("expected missing heading error")
+	}
+}
+
+func TestRenameHeadingLineSkipsCodeFences(t *testing.T) {
+	content := "```sh\n# Old\n# New\n```\n\n# Old\nbody\n"
+	got, found, err := renameHeadingLine(content, "Old", "New")
+	if err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if found.Line != 6 {
+		t.Fatalf("renamed heading on line %d, want 6", found.Line)
+	}
+	if want := "```sh\n# Old\n# New\n```\n\n# New\nbody\n"; got != want {
+		t.Fatalf("unexpected content %q", got)
+	}
+}
@@ -0,0 +1,266 @@
+package note
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Link kinds recognised by ParseLinks.
+const (
+	LinkWiki     = "wikilink"
+	LinkMarkdown = "markdown"
+	LinkEmbed    = "embed"
+)
+
+var (
+	wikiLinkPattern     = regexp.MustCompile(`(!?)\[\[([^\]]+)\]\]`)
+	markdownLinkPattern = regexp.MustCompile(`(!?)\[([^\]]*)\]\(([^)]*)\)`)
+	urlSchemePattern    = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+)
+
+// Link is one internal link occurrence in a note body. Target is the
+// decoded path as written, without its #heading or #^block subpath; Text is
+// the alias or link text. Line and Column are 1-based within the body, with
+// Column counting runes, and Snippet is the surrounding line. Path,
+// Candidates and BrokenAnchor are filled in by callers that resolve the
+// link against the vault.
+type Link struct {
+	Kind         string   `json:"kind"`
+	Target       string   `json:"target"`
+	Heading      string   `json:"heading,omitempty"`
+	Block        string   `json:"block,omitempty"`
+	Text         string   `json:"text,omitempty"`
+	Line         int      `json:"line"`
+	Column       int      `json:"column"`
+	Raw          string   `json:"raw"`
+	Snippet      string   `json:"snippet,omitempty"`
+	Path         string   `json:"path,omitempty"`
+	Candidates   []string `json:"candidates,omitempty"`
+	BrokenAnchor bool     `json:"broken_anchor,omitempty"`
+
+	start int
+	end   int
+	wiki  bool
+}
+
+// Subpath returns the "#heading" or "#^block" suffix of the link, if any.
+func (l Link) Subpath() string {
+	switch {
+	case l.Block != "":
+		return "#^" + l.Block
+	case l.Heading != "":
+		return "#" + l.Heading
+	}
+	return ""
+}
+
+// AnchorExists reports whether the link's subpath names one of the given
+// headings or block IDs. Nested heading links such as [[Note#A#B]] need
+// each heading to appear in order. Links without a subpath always match.
+func (l Link) AnchorExists(headings []Heading, blocks []string) bool {
+	if l.Block != "" {
+		for _, id := range blocks {
+			if id == l.Block {
+				return true
+			}
+		}
+		return false
+	}
+	if l.Heading == "" {
+		return true
+	}
+	next := 0
+	for _, part := range strings.Split(l.Heading, "#") {
+		want := anchorHeading(part)
+		if want == "" {
+			continue
+		}
+		found := false
+		for next < len(headings) {
+			h := headings[next]
+			next++
+			if anchorHeading(h.Text) == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// anchorHeading normalizes a heading the way Obsidian matches link
+// subpaths, treating characters that cannot appear in links as spaces.
+func anchorHeading(text string) string {
+	return normalizeHeading(strings.NewReplacer(":", " ", "|", " ", "^", " ", "%", " ", "[", " ", "]", " ").Replace(text))
+}
+
+// Key returns the normalized target used for link lookups: lowercased,
+// without ".md", with ./ and ../ paths resolved against sourceRel's folder.
+func (l Link) Key(sourceRel string) string {
+	target := strings.ReplaceAll(strings.TrimSpace(l.Target), "\\", "/")
+	if target == "" {
+		return ""
+	}
+	if strings.HasPrefix(target, "./") || strings.HasPrefix(target, "../") {
+		target = path.Join(path.Dir(sourceRel), target)
+	}
+	target = path.Clean(strings.TrimSuffix(target, ".md"))
+	for strings.HasPrefix(target, "../") {
+		target = strings.TrimPrefix(target, "../")
+	}
+	return strings.ToLower(strings.TrimPrefix(target, "/"))
+}
+
+// ParseLinks returns every wikilink, markdown link and embed in body in
+// document order. Links inside code fences and inline code are ignored, as
+// are external URLs.
+func ParseLinks(body string) []Link {
+	out := []Link{}
+	inFence := false
+	fence := ""
+	offset := 0
+	for i, line := range strings.SplitAfter(body, "\n") {
+		lineStart := offset
+		offset += len(line)
+		trimmed := strings.TrimSpace(line)
+		if marker := fenceMarker(trimmed); marker != "" {
+			if !inFence {
+				inFence, fence = true, marker
+				continue
+			}
+			if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+				continue
+			}
+		}
+		if inFence {
+			continue
+		}
+		masked := maskInlineCode(line)
+		taken := make([]bool, len(line))
+		for _, m := range wikiLinkPattern.FindAllStringSubmatchIndex(masked, -1) {
+			for j := m[0]; j < m[1]; j++ {
+				taken[j] = true
+			}
+			l := parseWikiLink(line[m[4]:m[5]])
+			if l.Target == "" && l.Heading == "" && l.Block == "" {
+				continue
+			}
+			if m[3] > m[2] {
+				l.Kind = LinkEmbed
+			}
+			out = append(out, placeLink(l, line, i+1, lineStart, m[0], m[1]))
+		}
+		for _, m := range markdownLinkPattern.FindAllStringSubmatchIndex(masked, -1) {
+			if taken[m[0]] {
+				continue
+			}
+			l, ok := parseMarkdownLink(line[m[6]:m[7]])
+			if !ok {
+				continue
+			}
+			l.Text = line[m[4]:m[5]]
+			if m[3] > m[2] {
+				l.Kind = LinkEmbed
+			}
+			out = append(out, placeLink(l, line, i+1, lineStart, m[0], m[1]))
+		}
+	}
+	sort.SliceStable(out, func(a, b int) bool { return out[a].start < out[b].start })
+	return out
+}
+
+// placeLink records where l sits: line number, rune column, raw text,
+// snippet and byte offsets within the body.
+func placeLink(l Link, line string, lineNo, lineStart, start, end int) Link {
+	l.Line = lineNo
+	l.Column = utf8.RuneCountInString(line[:start]) + 1
+	l.Raw = line[start:end]
+	l.Snippet = lineSnippet(line, start, end)
+	l.start, l.end = lineStart+start, lineStart+end
+	return l
+}
+
+func fenceMarker(trimmed string) string {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(trimmed, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+// maskInlineCode blanks `code spans` so links inside them are skipped
+// while byte offsets stay aligned with the original line.
+func maskInlineCode(line string) string {
+	if !strings.Contains(line, "`") {
+		return line
+	}
+	b := []byte(line)
+	open := -1
+	for i := 0; i < len(b); i++ {
+		if b[i] != '`' {
+			continue
+		}
+		if open < 0 {
+			open = i
+			continue
+		}
+		for j := open; j <= i; j++ {
+			b[j] = ' '
+		}
+		open = -1
+	}
+	return string(b)
+}
+
+func parseWikiLink(inner string) Link {
+	l := Link{Kind: LinkWiki, wiki: true}
+	target := inner
+	if idx := strings.Index(inner, "|"); idx >= 0 {
+		target, l.Text = inner[:idx], strings.TrimSpace(inner[idx+1:])
+	}
+	l.Target, l.Heading, l.Block = splitSubpath(strings.TrimSpace(target))
+	return l
+}
+
+func parseMarkdownLink(dest string) (Link, bool) {
+	dest = strings.TrimSpace(dest)
+	if strings.HasPrefix(dest, "<") {
+		end := strings.Index(dest, ">")
+		if end < 0 {
+			return Link{}, false
+		}
+		dest = dest[1:end]
+	} else if idx := strings.Index(dest, ` "`); idx >= 0 && strings.HasSuffix(dest, `"`) {
+		dest = strings.TrimSpace(dest[:idx])
+	}
+	if dest == "" || urlSchemePattern.MatchString(dest) {
+		return Link{}, false
+	}
+	if decoded, err := url.PathUnescape(dest); err == nil {
+		dest = decoded
+	}
+	l := Link{Kind: LinkMarkdown}
+	l.Target, l.Heading, l.Block = splitSubpath(dest)
+	return l, true
+}
+
+func splitSubpath(target string) (string, string, string) {
+	idx := strings.Index(target, "#")
+	if idx < 0 {
+		return strings.TrimSpace(target), "", ""
+	}
+	file, sub := strings.TrimSpace(target[:idx]), strings.TrimSpace(target[idx+1:])
+	if strings.HasPrefix(sub, "^") {
+		return file, "", strings.TrimPrefix(sub, "^")
+	}
+	return file, sub, ""
+}
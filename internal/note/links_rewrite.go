@@ -2,22 +2,19 @@ package note
 
 import (
 	"os"
+	"path"
 	"path/filepath"
-	"regexp"
 	"strings"
-)
 
-var wikilinkPattern = regexp.MustCompile(`\[\[([^\]]+)\]\]`)
+	"github.com/nightisyang/obsidian-cli/internal/history"
+)
 
-func RewriteLinks(vaultRoot, oldRel, newRel string, dryRun bool) ([]string, error) {
+func RewriteLinks(vaultRoot string, hist *history.Store, oldRel, newRel string, dryRun bool) ([]string, error) {
 	paths, err := listMarkdown(vaultRoot)
 	if err != nil {
 		return nil, err
 	}
 
-	oldKey := normalizeLinkKey(strings.TrimSuffix(filepath.ToSlash(oldRel), ".md"))
-	oldStem := normalizeLinkKey(strings.TrimSuffix(filepath.Base(oldRel), ".md"))
-	newKey := strings.TrimSuffix(filepath.ToSlash(newRel), ".md")
 	changed := []string{}
 
 	for _, abs := range paths {
@@ -28,39 +25,13 @@ func RewriteLinks(vaultRoot, oldRel, newRel string, dryRun bool) ([]string, erro
 			return nil, readErr
 		}
 		content := string(contentBytes)
-		updated := wikilinkPattern.ReplaceAllStringFunc(content, func(match string) string {
-			inner := strings.TrimSuffix(strings.TrimPrefix(match, "[["), "]]")
-			parts := strings.SplitN(inner, "|", 2)
-			targetWithAnchor := strings.TrimSpace(parts[0])
-			alias := ""
-			if len(parts) == 2 {
-				alias = parts[1]
-			}
-
-			target := targetWithAnchor
-			anchor := ""
-			if idx := strings.Index(targetWithAnchor, "#"); idx >= 0 {
-				target = targetWithAnchor[:idx]
-				anchor = targetWithAnchor[idx:]
-			}
-
-			norm := normalizeLinkKey(target)
-			if norm != oldKey && norm != oldStem {
-				return match
-			}
-
-			rewritten := newKey + anchor
-			if alias != "" {
-				return "[[" + rewritten + "|" + alias + "]]"
-			}
-			return "[[" + rewritten + "]]"
-		})
+		updated := RewriteLinkText(content, rel, oldRel, newRel)
 
 		if updated != content {
 			changed = append(changed, rel)
 			if !dryRun {
-				if writeErr := os.WriteFile(abs, []byte(updated), 0o644); writeErr != nil {
-					return nil, writeErr
+				if err := hist.WriteFile(abs, []byte(updated)); err != nil {
+					return nil, err
 				}
 			}
 		}
@@ -68,6 +39,88 @@ func RewriteLinks(vaultRoot, oldRel, newRel string, dryRun bool) ([]string, erro
 	return changed, nil
 }
 
+// RewriteLinkText rewrites links in content, a note at sourceRel, that
+// target oldRel so they point at newRel. Wikilinks and embeds keep their
+// subpath and alias; markdown links keep their text, title, extension
+// style and relative form.
+func RewriteLinkText(content, sourceRel, oldRel, newRel string) string {
+	oldKey := normalizeLinkKey(strings.TrimSuffix(filepath.ToSlash(oldRel), ".md"))
+	oldStem := normalizeLinkKey(strings.TrimSuffix(path.Base(filepath.ToSlash(oldRel)), ".md"))
+	newRel = filepath.ToSlash(newRel)
+
+	var b strings.Builder
+	last := 0
+	for _, l := range ParseLinks(content) {
+		key := l.Key(sourceRel)
+		if key == "" || (key != oldKey && key != oldStem) {
+			continue
+		}
+		b.WriteString(content[last:l.start])
+		if l.wiki {
+			b.WriteString(rewriteWikiLink(l, newRel))
+		} else {
+			b.WriteString(rewriteMarkdownLink(l, sourceRel, newRel))
+		}
+		last = l.end
+	}
+	if last == 0 {
+		return content
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+func rewriteWikiLink(l Link, newRel string) string {
+	out := "[[" + strings.TrimSuffix(newRel, ".md") + l.Subpath()
+	if l.Text != "" {
+		out += "|" + l.Text
+	}
+	out += "]]"
+	if l.Kind == LinkEmbed {
+		out = "!" + out
+	}
+	return out
+}
+
+func rewriteMarkdownLink(l Link, sourceRel, newRel string) string {
+	prefix := "["
+	if l.Kind == LinkEmbed {
+		prefix = "!["
+	}
+	head := prefix + l.Text + "]("
+	dest := strings.TrimSuffix(l.Raw[len(head):], ")")
+
+	// Keep anything after the path, such as a "title".
+	angle := strings.HasPrefix(strings.TrimSpace(dest), "<")
+	title := ""
+	if angle {
+		if idx := strings.Index(dest, ">"); idx >= 0 {
+			title = dest[idx+1:]
+		}
+	} else if idx := strings.Index(dest, ` "`); idx >= 0 && strings.HasSuffix(dest, `"`) {
+		title = dest[idx:]
+	}
+
+	target := newRel
+	old := strings.ReplaceAll(l.Target, "\\", "/")
+	if strings.HasPrefix(old, "./") || strings.HasPrefix(old, "../") {
+		if rel, err := filepath.Rel(path.Dir(sourceRel), newRel); err == nil {
+			target = filepath.ToSlash(rel)
+			if strings.HasPrefix(old, "./") && !strings.HasPrefix(target, "../") {
+				target = "./" + target
+			}
+		}
+	}
+	if !strings.HasSuffix(strings.ToLower(old), ".md") {
+		target = strings.TrimSuffix(target, ".md")
+	}
+	target += l.Subpath()
+	if angle {
+		return head + "<" + target + ">" + title + ")"
+	}
+	return head + strings.ReplaceAll(target, " ", "%20") + title + ")"
+}
+
 func normalizeLinkKey(target string) string {
 	v := strings.TrimSpace(target)
 	v = strings.TrimPrefix(v, "./")
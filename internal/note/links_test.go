@@ -0,0 +1,121 @@
+package note
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLinksKindsAndSubpaths(t *testing.T) {
+	body := strings.Join([]string{
+		"See [[Alpha#Plan|the plan]] and ![[diagram.png]].",
+		"Also [beta](notes/Beta%20Two.md#^b1), [site](https://example.com) and ![img](<assets/my pic.png> \"Pic\").",
+		"`[[not a link]]` and [same](#Local)",
+		"```",
+		"[[inside fence]]",
+		"```",
+		"[rel](../up.md)",
+	}, "\n")
+	links := ParseLinks(body)
+	want := []struct {
+		kind, target, heading, block, text string
+		line                               int
+	}{
+		{LinkWiki, "Alpha", "Plan", "", "the plan", 1},
+		{LinkEmbed, "diagram.png", "", "", "", 1},
+		{LinkMarkdown, "notes/Beta Two.md", "", "b1", "beta", 2},
+		{LinkEmbed, "assets/my pic.png", "", "", "img", 2},
+		{LinkMarkdown, "", "Local", "", "same", 3},
+		{LinkMarkdown, "../up.md", "", "", "rel", 7},
+	}
+	if len(links) != len(want) {
+		t.Fatalf("expected %d links, got %+v", len(want), links)
+	}
+	for i, w := range want {
+		l := links[i]
+		if l.Kind != w.kind || l.Target != w.target || l.Heading != w.heading || l.Block != w.block || l.Text != w.text || l.Line != w.line {
+			t.Fatalf("link %d: got %+v, want %+v", i, l, w)
+		}
+	}
+	if got := links[5].Key("folder/sub/note.md"); got != "folder/up" {
+		t.Fatalf("unexpected relative key: %q", got)
+	}
+	if got := links[2].Key("x.md"); got != "notes/beta two" {
+		t.Fatalf("unexpected markdown key: %q", got)
+	}
+}
+
+func TestRewriteLinkTextAllKinds(t *testing.T) {
+	content := strings.Join([]string{
+		"[[old/target#Top|alias]] ![[target]]",
+		"[md](old/target.md#^blk \"Title\") [enc](old/target) [angle](<old/target.md>)",
+		"[rel](../old/target.md) [other](old/keep.md)",
+	}, "\n")
+	got := RewriteLinkText(content, "notes/source.md", "old/target.md", "new place/target.md")
+	want := strings.Join([]string{
+		"[[new place/target#Top|alias]] ![[new place/target]]",
+		"[md](new%20place/target.md#^blk \"Title\") [enc](new%20place/target) [angle](<new place/target.md>)",
+		"[rel](../new%20place/target.md) [other](old/keep.md)",
+	}, "\n")
+	if got != want {
+		t.Fatalf("unexpected rewrite:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMoveRewritesMarkdownLinks(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"a.md":        "Go to [b](b.md) and ![[b]].",
+		"sub/c.md":    "Up: [b](../b.md)",
+		"b.md":        "target",
+		"sub/keep.md": "[x](https://example.com/b.md)",
+	}
+	for rel, content := range files {
+		abs := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(abs, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+	_, rewritten, err := Move(root, nil, "b.md", "archive/b.md", MoveOptions{UpdateLinks: true})
+	if err != nil {
+		t.Fatalf("Move error: %v", err)
+	}
+	if strings.Join(rewritten, ",") != "a.md,sub/c.md" {
+		t.Fatalf("unexpected rewritten files: %v", rewritten)
+	}
+	a, _ := os.ReadFile(filepath.Join(root, "a.md"))
+	c, _ := os.ReadFile(filepath.Join(root, "sub", "c.md"))
+	if string(a) != "Go to [b](archive/b.md) and ![[archive/b]]." || string(c) != "Up: [b](../archive/b.md)" {
+		t.Fatalf("unexpected rewrites: %q %q", a, c)
+	}
+}
+
+func TestLinkAnchorExists(t *testing.T) {
+	body := "# Plan\n## Next Steps\nship it ^abc123\n### Risks: open\n"
+	headings, blocks := Headings(body), BlockIDs(body)
+	cases := map[string]bool{
+		"[[Plan]]":                    true,
+		"[[Plan#next  steps]]":        true,
+		"[[Plan#Plan#Next Steps]]":    true,
+		"[[Plan#Next Steps#Plan]]":    false,
+		"[[Plan#Risks open]]":         true,
+		"[[Plan#Later]]":              false,
+		"[[Plan#^abc123]]":            true,
+		"[[Plan#^gone99]]":            false,
+		"[p](Plan.md#Next%20Steps)":   true,
+		"[p](Plan.md#Removed%20Part)": false,
+	}
+	for raw, want := range cases {
+		links := ParseLinks(raw)
+		if len(links) != 1 {
+			t.Fatalf("ParseLinks(%q) = %+v", raw, links)
+		}
+		if got := links[0].AnchorExists(headings, blocks); got != want {
+			t.Fatalf("AnchorExists(%q) = %v, want %v", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package note
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nightisyang/obsidian-cli/internal/frontmatter"
+	"github.com/nightisyang/obsidian-cli/internal/history"
+)
+
+// snippetRunes bounds the line context kept for links and mentions.
+const snippetRunes = 120
+
+var bareURLPattern = regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.-]*://\S+`)
+
+// Mention is an unlinked, plain-text occurrence of a note's name. Line and
+// Column are 1-based; Column counts runes.
+type Mention struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Term    string `json:"term"`
+	Text    string `json:"text"`
+	Snippet string `json:"snippet"`
+
+	start int
+	end   int
+}
+
+// MentionTerms returns the names a note can be mentioned by: its file name,
+// frontmatter title and aliases.
+func MentionTerms(n Note) []string {
+	return n.Names()
+}
+
+// FindMentions returns case-insensitive, whole-word occurrences of terms in
+// content. Frontmatter, code, existing links and URLs are skipped, and
+// longer terms win over shorter ones at the same position.
+func FindMentions(rel, content string, terms []string) []Mention {
+	terms = mentionTerms(terms)
+	out := []Mention{}
+	if len(terms) == 0 {
+		return out
+	}
+	_, _, inFrontmatter, _ := frontmatter.ParseDocument(content)
+	inFence := false
+	fence := ""
+	offset := 0
+	for i, line := range strings.SplitAfter(content, "\n") {
+		lineStart := offset
+		offset += len(line)
+		trimmed := strings.TrimSpace(line)
+		if inFrontmatter {
+			if i > 0 && trimmed == "---" {
+				inFrontmatter = false
+			}
+			continue
+		}
+		if marker := fenceMarker(trimmed); marker != "" {
+			if !inFence {
+				inFence, fence = true, marker
+				continue
+			}
+			if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+				continue
+			}
+		}
+		if inFence {
+			continue
+		}
+		masked := maskMentionLine(line)
+		for pos := 0; pos < len(masked); {
+			matched := ""
+			for _, term := range terms {
+				end := pos + len(term)
+				if end <= len(masked) && strings.EqualFold(masked[pos:end], term) && isMentionBoundary(masked, pos, end) {
+					matched = term
+					break
+				}
+			}
+			if matched == "" {
+				_, size := utf8.DecodeRuneInString(masked[pos:])
+				pos += size
+				continue
+			}
+			end := pos + len(matched)
+			out = append(out, Mention{
+				Path:    rel,
+				Line:    i + 1,
+				Column:  utf8.RuneCountInString(line[:pos]) + 1,
+				Term:    matched,
+				Text:    line[pos:end],
+				Snippet: lineSnippet(line, pos, end),
+				start:   lineStart + pos,
+				end:     lineStart + end,
+			})
+			pos = end
+		}
+	}
+	return out
+}
+
+// LinkMentionText replaces the given mentions of content with wikilinks to
+// target, keeping the original text as the alias when it differs.
+func LinkMentionText(content string, mentions []Mention, target string) string {
+	sorted := append([]Mention(nil), mentions...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start < sorted[j].start })
+	var b strings.Builder
+	last := 0
+	for _, m := range sorted {
+		if m.start < last || m.end > len(content) || content[m.start:m.end] != m.Text {
+			continue
+		}
+		b.WriteString(content[last:m.start])
+		b.WriteString("[[" + target)
+		if m.Text != path.Base(target) {
+			b.WriteString("|" + m.Text)
+		}
+		b.WriteString("]]")
+		last = m.end
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// Mentions scans every note except targetRel for unlinked mentions of terms.
+func Mentions(vaultRoot, targetRel string, terms []string) ([]Mention, error) {
+	paths, err := listMarkdown(vaultRoot)
+	if err != nil {
+		return nil, err
+	}
+	out := []Mention{}
+	for _, abs := range paths {
+		rel, _ := filepath.Rel(vaultRoot, abs)
+		rel = filepath.ToSlash(rel)
+		if rel == targetRel {
+			continue
+		}
+		content, err := os.ReadFile(abs)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, FindMentions(rel, string(content), terms)...)
+	}
+	return out, nil
+}
+
+// LinkMentions rewrites the selected mentions into wikilinks to target and
+// returns the notes that changed. Nothing is written when dryRun is set.
+func LinkMentions(vaultRoot string, hist *history.Store, mentions []Mention, target string, dryRun bool) ([]string, error) {
+	bySource := map[string][]Mention{}
+	sources := []string{}
+	for _, m := range mentions {
+		if _, ok := bySource[m.Path]; !ok {
+			sources = append(sources, m.Path)
+		}
+		bySource[m.Path] = append(bySource[m.Path], m)
+	}
+	sort.Strings(sources)
+	changed := []string{}
+	for _, rel := range sources {
+		abs := filepath.Join(vaultRoot, filepath.FromSlash(rel))
+		content, err := os.ReadFile(abs)
+		if err != nil {
+			return nil, err
+		}
+		updated := LinkMentionText(string(content), bySource[rel], target)
+		if updated == string(content) {
+			continue
+		}
+		changed = append(changed, rel)
+		if !dryRun {
+			if err := hist.WriteFile(abs, []byte(updated)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return changed, nil
+}
+
+// mentionTerms de-duplicates terms case-insensitively, longest first.
+func mentionTerms(terms []string) []string {
+	seen := map[string]struct{}{}
+	out := []string{}
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		key := strings.ToLower(term)
+		if term == "" {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, term)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if len(out[i]) != len(out[j]) {
+			return len(out[i]) > len(out[j])
+		}
+		return out[i] < out[j]
+	})
+	return out
+}
+
+// maskMentionLine blanks inline code, links and URLs, keeping byte offsets
+// aligned with line.
+func maskMentionLine(line string) string {
+	masked := []byte(maskInlineCode(line))
+	for _, pattern := range []*regexp.Regexp{wikiLinkPattern, markdownLinkPattern, bareURLPattern} {
+		for _, m := range pattern.FindAllStringIndex(string(masked), -1) {
+			for j := m[0]; j < m[1]; j++ {
+				masked[j] = ' '
+			}
+		}
+	}
+	return string(masked)
+}
+
+func isMentionBoundary(s string, start, end int) bool {
+	if start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(s[:start])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	if end < len(s) {
+		r, _ := utf8.DecodeRuneInString(s[end:])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// lineSnippet returns the trimmed line, windowed around the byte range
+// [start, end) when the line is long.
+func lineSnippet(line string, start, end int) string {
+	return WindowSnippet(line, start, end, snippetRunes)
+}
+
+// WindowSnippet trims line to at most max runes centred on the byte range
+// [start, end), marking cut ends with an ellipsis.
+func WindowSnippet(line string, start, end, max int) string {
+	line = strings.TrimRight(line, "\r\n")
+	if max <= 0 || utf8.RuneCountInString(line) <= max {
+		return strings.TrimSpace(line)
+	}
+	runes := []rune(line)
+	from := utf8.RuneCountInString(line[:start])
+	to := from + utf8.RuneCountInString(line[start:end])
+	pad := (max - (to - from)) / 2
+	if pad < 0 {
+		pad = 0
+	}
+	lo, hi := from-pad, to+pad
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > len(runes) {
+		hi = len(runes)
+	}
+	snippet := strings.TrimSpace(string(runes[lo:hi]))
+	if lo > 0 {
+		snippet = "…" + snippet
+	}
+	if hi < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
@@ -0,0 +1,58 @@
+package note
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindMentionsSkipsLinksCodeAndFrontmatter(t *testing.T) {
+	content := "---\ntitle: Project Plan\n---\nThe project plan is due. See [[Project Plan]] or [plan](Project%20Plan.md).\n```\nproject plan in code\n```\nInline `project plan` and planned PLAN, plan_b, über plan.\nhttps://x.y/plan ok"
+	got := FindMentions("a.md", content, []string{"Plan", "project plan", "PLAN"})
+	want := []struct {
+		line, column int
+		text         string
+	}{{4, 5, "project plan"}, {8, 35, "PLAN"}, {8, 54, "plan"}}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected mentions: %+v", got)
+	}
+	for i, w := range want {
+		if got[i].Line != w.line || got[i].Column != w.column || got[i].Text != w.text {
+			t.Fatalf("mention %d = %+v, want %+v", i, got[i], w)
+		}
+	}
+	if got[0].Term != "project plan" || got[0].Snippet != "The project plan is due. See [[Project Plan]] or [plan](Project%20Plan.md)." {
+		t.Fatalf("unexpected first mention: %+v", got[0])
+	}
+}
+
+func TestLinkMentionsRewritesSelectedMentions(t *testing.T) {
+	root := t.TempDir()
+	content := "Plan first, then plan again.\n"
+	if err := os.WriteFile(filepath.Join(root, "a.md"), []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	mentions, err := Mentions(root, "projects/Plan.md", []string{"Plan"})
+	if err != nil || len(mentions) != 2 {
+		t.Fatalf("Mentions = %+v, %v", mentions, err)
+	}
+
+	changed, err := LinkMentions(root, nil, mentions, "projects/Plan", true)
+	if err != nil || len(changed) != 1 {
+		t.Fatalf("dry run = %v, %v", changed, err)
+	}
+	if raw, _ := os.ReadFile(filepath.Join(root, "a.md")); string(raw) != content {
+		t.Fatalf("dry run wrote the note: %q", raw)
+	}
+
+	if _, err := LinkMentions(root, nil, mentions[1:], "projects/Plan", false); err != nil {
+		t.Fatalf("LinkMentions: %v", err)
+	}
+	raw, _ := os.ReadFile(filepath.Join(root, "a.md"))
+	if string(raw) != "Plan first, then [[projects/Plan|plan]] again.\n" {
+		t.Fatalf("unexpected rewrite: %q", raw)
+	}
+	if got := LinkMentionText("Plan here", FindMentions("a.md", "Plan here", []string{"plan"}), "Plan"); got != "[[Plan]] here" {
+		t.Fatalf("unexpected alias-free rewrite: %q", got)
+	}
+}
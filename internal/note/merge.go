@@ -0,0 +1,215 @@
+package note
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/frontmatter"
+	"github.com/nightisyang/obsidian-cli/internal/history"
+)
+
+// MergeConflict is a scalar frontmatter key both notes set differently.
+type MergeConflict struct {
+	Key         string `json:"key"`
+	Source      any    `json:"source"`
+	Destination any    `json:"destination"`
+	Kept        string `json:"kept"`
+}
+
+// MergeResult reports a note merge and the notes whose links now point at
+// the destination.
+type MergeResult struct {
+	Source      string          `json:"source"`
+	Destination string          `json:"destination"`
+	Conflicts   []MergeConflict `json:"conflicts"`
+	Rewritten   []string        `json:"rewritten"`
+	Bytes       int             `json:"bytes"`
+	DryRun      bool            `json:"dry_run"`
+}
+
+// Merge appends the body of src to dst, unions their frontmatter, points
+// every link to src at dst and removes src, into the trash unless the vault
+// deletes permanently. Nothing is written when opts.DryRun is set.
+func Merge(vaultRoot string, hist *history.Store, src, dst string, resolve LinkResolver, opts MergeOptions) (MergeResult, error) {
+	source, err := Read(vaultRoot, src)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	dest, err := Read(vaultRoot, dst)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	if source.Path == dest.Path {
+		return MergeResult{}, errs.New(errs.ExitValidation, "source and destination are the same note")
+	}
+	switch opts.OnConflict {
+	case "":
+		opts.OnConflict = "dst"
+	case "dst", "src", "error":
+	default:
+		return MergeResult{}, errs.New(errs.ExitValidation, "--on-conflict must be dst, src or error")
+	}
+
+	rewrites, originals, rewritten, err := planLinkRewrites(vaultRoot, map[string]string{source.Path: dest.Path}, resolve)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	// Links inside both notes are merged from their rewritten content.
+	for _, n := range []*Note{&source, &dest} {
+		if content, ok := rewrites[n.Path]; ok {
+			if *n, err = Parse(n.Path, content); err != nil {
+				return MergeResult{}, err
+			}
+			delete(rewrites, n.Path)
+		}
+	}
+	out := MergeResult{Source: source.Path, Destination: dest.Path, Conflicts: []MergeConflict{}, Rewritten: []string{}, DryRun: opts.DryRun}
+	for _, rel := range rewritten {
+		if rel != dest.Path {
+			out.Rewritten = append(out.Rewritten, rel)
+		}
+	}
+
+	values, conflicts := mergeFrontmatter(frontmatter.FrontmatterToMap(dest.Frontmatter), frontmatter.FrontmatterToMap(source.Frontmatter), opts.OnConflict)
+	out.Conflicts = conflicts
+	if opts.OnConflict == "error" && len(conflicts) > 0 {
+		keys := make([]string, 0, len(conflicts))
+		for _, c := range conflicts {
+			keys = append(keys, c.Key)
+		}
+		return MergeResult{}, errs.NewDetailed(
+			errs.ExitValidation,
+			"frontmatter_conflict",
+			"Rerun with --on-conflict dst or src, or align the values first.",
+			"both notes set different values for: "+strings.Join(keys, ", "),
+		)
+	}
+
+	merged := dest
+	merged.Frontmatter = frontmatter.MapToFrontmatter(values)
+	merged.Body = mergeBodies(dest.Body, source.Body, opts.Heading)
+	rendered, err := Render(merged, false, time.Now())
+	if err != nil {
+		return MergeResult{}, err
+	}
+	out.Bytes = len(rendered.Raw)
+	if opts.Guard != nil {
+		if err := opts.Guard(rendered); err != nil {
+			return MergeResult{}, err
+		}
+	}
+	if opts.DryRun {
+		return out, nil
+	}
+
+	destAbs := filepath.Join(vaultRoot, filepath.FromSlash(dest.Path))
+	destRaw, err := os.ReadFile(destAbs)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	if err := applyMove(vaultRoot, hist, rewrites, originals, func() error {
+		if _, err := Write(vaultRoot, hist, dest.Path, merged, false, time.Now()); err != nil {
+			return err
+		}
+		if _, err := Remove(vaultRoot, hist, source.Path); err != nil {
+			_ = os.WriteFile(destAbs, destRaw, 0o644)
+			return err
+		}
+		return nil
+	}); err != nil {
+		return MergeResult{}, err
+	}
+	return out, nil
+}
+
+// mergeBodies appends src to dst, optionally under a level 2 heading.
+func mergeBodies(dst, src, heading string) string {
+	src = strings.Trim(src, "\n")
+	if heading = strings.TrimSpace(heading); heading != "" {
+		src = "## " + heading + "\n\n" + src
+	}
+	dst = strings.TrimRight(dst, "\n")
+	if dst == "" {
+		return src + "\n"
+	}
+	return dst + "\n\n" + src + "\n"
+}
+
+// mergeFrontmatter unions two frontmatter maps. Lists are combined without
+// duplicates; differing scalars are conflicts resolved by strategy, except
+// timestamps, which always keep the destination's.
+func mergeFrontmatter(dst, src map[string]any, strategy string) (map[string]any, []MergeConflict) {
+	out := map[string]any{}
+	for k, v := range dst {
+		out[k] = v
+	}
+	keys := make([]string, 0, len(src))
+	for k := range src {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	conflicts := []MergeConflict{}
+	for _, k := range keys {
+		sv := src[k]
+		dv, ok := out[k]
+		if !ok {
+			out[k] = sv
+			continue
+		}
+		if list, isList := unionLists(dv, sv); isList {
+			out[k] = list
+			continue
+		}
+		if k == "created_at" || k == "updated_at" || reflect.DeepEqual(dv, sv) || fmt.Sprint(dv) == fmt.Sprint(sv) {
+			continue
+		}
+		kept := "dst"
+		if strategy == "src" {
+			kept = "src"
+			out[k] = sv
+		}
+		conflicts = append(conflicts, MergeConflict{Key: k, Source: sv, Destination: dv, Kept: kept})
+	}
+	return out, conflicts
+}
+
+// unionLists combines two list values, keeping first-seen order. It
+// reports false unless both values are lists.
+func unionLists(a, b any) ([]any, bool) {
+	la, okA := toAnyList(a)
+	lb, okB := toAnyList(b)
+	if !okA || !okB {
+		return nil, false
+	}
+	out := []any{}
+	seen := map[string]struct{}{}
+	for _, v := range append(la, lb...) {
+		key := fmt.Sprint(v)
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, v)
+	}
+	return out, true
+}
+
+func toAnyList(v any) ([]any, bool) {
+	switch list := v.(type) {
+	case []any:
+		return list, true
+	case []string:
+		out := make([]any, 0, len(list))
+		for _, s := range list {
+			out = append(out, s)
+		}
+		return out, true
+	}
+	return nil, false
+}
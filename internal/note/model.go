@@ -14,6 +14,16 @@ type Note struct {
 	InlineTags  []string    `json:"inline_tags,omitempty"`
 }
 
+// Names returns what a note can be referred to by: its file name, then its
+// frontmatter title and aliases when set.
+func (n Note) Names() []string {
+	names := []string{n.Title}
+	if n.Frontmatter.Title != "" {
+		names = append(names, n.Frontmatter.Title)
+	}
+	return append(names, n.Frontmatter.Aliases...)
+}
+
 type CreateInput struct {
 	Title    string
 	Dir      string
@@ -34,6 +44,12 @@ type HeadingSection struct {
 	EndLine   int    `json:"end_line"`
 }
 
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	Line  int    `json:"line"`
+}
+
 type Block struct {
 	Path       string `json:"path"`
 	BlockID    string `json:"block_id"`
@@ -52,3 +68,27 @@ type MoveOptions struct {
 	UpdateLinks bool
 	DryRun      bool
 }
+
+// MergeOptions controls note merge. Heading, when set, wraps the source
+// content in a level 2 heading. OnConflict picks the value kept when both
+// notes set a scalar frontmatter key: "dst" (default), "src" or "error".
+// Guard, when set, vets the merged destination note before anything is
+// written, such as enforcing a size limit.
+type MergeOptions struct {
+	Heading    string
+	OnConflict string
+	DryRun     bool
+	Guard      func(Note) error
+}
+
+// SplitOptions selects the sections note split moves out: the section of
+// Heading, or every section at Level. Inherit lists the frontmatter keys
+// copied to the new notes; "all" copies everything except title, aliases
+// and timestamps.
+type SplitOptions struct {
+	Heading string
+	Level   int
+	Embed   bool
+	Inherit []string
+	DryRun  bool
+}
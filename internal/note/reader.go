@@ -21,8 +21,13 @@ func Read(vaultRoot, relPath string) (Note, error) {
 		}
 		return Note{}, err
 	}
+	return Parse(normalized, string(content))
+}
 
-	values, body, _, err := frontmatter.ParseDocument(string(content))
+// Parse builds a Note from raw markdown content without touching the filesystem.
+func Parse(relPath, content string) (Note, error) {
+	normalized := normalizeNotePath(relPath)
+	values, body, _, err := frontmatter.ParseDocument(content)
 	if err != nil {
 		return Note{}, errs.Wrap(errs.ExitValidation, "failed to parse frontmatter", err)
 	}
@@ -32,7 +37,7 @@ func Read(vaultRoot, relPath string) (Note, error) {
 		Title:       titleFromPath(normalized),
 		Frontmatter: fm,
 		Body:        body,
-		Raw:         string(content),
+		Raw:         content,
 	}, nil
 }
 
@@ -0,0 +1,311 @@
+package note
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/frontmatter"
+	"github.com/nightisyang/obsidian-cli/internal/history"
+)
+
+// SplitPart is one section moved into its own note.
+type SplitPart struct {
+	Path    string `json:"path"`
+	Heading string `json:"heading"`
+	Level   int    `json:"level"`
+	Bytes   int    `json:"bytes"`
+}
+
+// SplitResult reports a note split and the other notes whose links into
+// the moved sections now point at the new notes.
+type SplitResult struct {
+	Path      string      `json:"path"`
+	Parts     []SplitPart `json:"parts"`
+	Rewritten []string    `json:"rewritten"`
+	Bytes     int         `json:"bytes"`
+	DryRun    bool        `json:"dry_run"`
+}
+
+// splitSection is a section being moved out, with the headings and block
+// IDs below its own heading that move with it.
+type splitSection struct {
+	heading    Heading
+	start, end int
+	path       string
+	headings   []Heading
+	blocks     []string
+}
+
+// SplitPartPath names the n-th part of a split note, such as
+// "notes/plan-part-2.md".
+func SplitPartPath(currentPath string, n int) string {
+	clean := strings.TrimSpace(currentPath)
+	if clean == "" {
+		clean = "split-note"
+	}
+	return fmt.Sprintf("%s-part-%d.md", strings.TrimSuffix(clean, ".md"), n)
+}
+
+// Split moves the selected heading sections of relPath into new notes. The
+// headings stay in the original, followed by a link or embed of the new
+// note, and each new note links back to its heading. Links anywhere in the
+// vault to headings or blocks inside a moved section are pointed at the
+// new note; resolve says which links reach relPath.
+func Split(vaultRoot string, hist *history.Store, relPath string, resolve LinkResolver, opts SplitOptions) (SplitResult, error) {
+	n, err := Read(vaultRoot, relPath)
+	if err != nil {
+		return SplitResult{}, err
+	}
+	lines := strings.Split(n.Body, "\n")
+	sections, err := selectSplitSections(n, lines, opts)
+	if err != nil {
+		return SplitResult{}, err
+	}
+
+	inherited := inheritFrontmatter(n.Frontmatter, opts.Inherit)
+	parentRef := strings.TrimSuffix(n.Path, ".md")
+	out := SplitResult{Path: n.Path, Parts: []SplitPart{}, Rewritten: []string{}, DryRun: opts.DryRun}
+	next := 2
+	for i := range sections {
+		for ; sections[i].path == ""; next++ {
+			candidate := SplitPartPath(n.Path, next)
+			abs, _, pathErr := resolveNoteAbs(vaultRoot, candidate)
+			if pathErr != nil {
+				return SplitResult{}, pathErr
+			}
+			if _, statErr := os.Stat(abs); os.IsNotExist(statErr) {
+				sections[i].path = candidate
+			}
+		}
+	}
+
+	// Redirect links section by section, before the links between the
+	// parent and its new notes are added.
+	redirect := func(part []string, home int) {
+		copy(part, strings.Split(redirectSplitLinks(strings.Join(part, "\n"), n.Path, n.Path, home, sections, resolve), "\n"))
+	}
+	from := 0
+	for i, sec := range sections {
+		redirect(lines[from:sec.start+1], -1)
+		redirect(lines[sec.start+1:sec.end], i)
+		from = sec.end
+	}
+	redirect(lines[from:], -1)
+
+	children := []Note{}
+	for _, sec := range sections {
+		content := strings.Trim(strings.Join(lines[sec.start+1:sec.end], "\n"), "\n")
+		body := content + "\n\nContext from [[" + parentRef + "#" + linkHeading(sec.heading.Text) + "]]\n"
+		children = append(children, Note{Path: sec.path, Frontmatter: inherited, Body: body})
+		out.Parts = append(out.Parts, SplitPart{Path: sec.path, Heading: sec.heading.Text, Level: sec.heading.Level, Bytes: len(body)})
+	}
+
+	for i := len(sections) - 1; i >= 0; i-- {
+		sec := sections[i]
+		ref := "[[" + strings.TrimSuffix(sec.path, ".md") + "]]"
+		replacement := []string{"Continued in " + ref}
+		if opts.Embed {
+			replacement = []string{"!" + ref}
+		}
+		if sec.end < len(lines) || strings.HasSuffix(n.Body, "\n") {
+			replacement = append(replacement, "")
+		}
+		lines = append(lines[:sec.start+1], append(replacement, lines[sec.end:]...)...)
+	}
+	n.Body = strings.Join(lines, "\n")
+	out.Bytes = len(n.Body)
+
+	paths, err := listMarkdown(vaultRoot)
+	if err != nil {
+		return SplitResult{}, err
+	}
+	rewrites := map[string]string{}
+	originals := map[string]string{}
+	for _, abs := range paths {
+		rel, _ := filepath.Rel(vaultRoot, abs)
+		rel = filepath.ToSlash(rel)
+		if rel == n.Path {
+			continue
+		}
+		raw, readErr := os.ReadFile(abs)
+		if readErr != nil {
+			return SplitResult{}, readErr
+		}
+		if updated := redirectSplitLinks(string(raw), rel, n.Path, -1, sections, resolve); updated != string(raw) {
+			rewrites[rel], originals[rel] = updated, string(raw)
+			out.Rewritten = append(out.Rewritten, rel)
+		}
+	}
+	if opts.DryRun {
+		return out, nil
+	}
+
+	now := time.Now()
+	if err := applyMove(vaultRoot, hist, rewrites, originals, func() error {
+		for _, child := range children {
+			if _, err := Write(vaultRoot, hist, child.Path, child, true, now); err != nil {
+				return err
+			}
+		}
+		_, err := Write(vaultRoot, hist, n.Path, n, false, now)
+		return err
+	}); err != nil {
+		return SplitResult{}, err
+	}
+	return out, nil
+}
+
+// redirectSplitLinks points the links of content, read from sourceRel, that
+// reach a heading or block inside a moved section at that section's new
+// note. home is the section content was taken from, or -1 for text that
+// stays where it is; links without a target in a section refer back to
+// the parent unless their anchor moved along.
+func redirectSplitLinks(content, sourceRel, parentRel string, home int, sections []splitSection, resolve LinkResolver) string {
+	var b strings.Builder
+	last := 0
+	for _, l := range ParseLinks(content) {
+		if l.Target == "" && sourceRel != parentRel {
+			continue
+		}
+		if l.Target != "" && resolve(sourceRel, l) != parentRel {
+			continue
+		}
+		to, own := splitSectionFor(sections, l)
+		target := parentRel
+		switch {
+		case to < 0 && (home < 0 || l.Target != "" || l.Subpath() == ""):
+			continue
+		case to >= 0 && to == home && l.Target == "" && !own:
+			continue
+		case to >= 0:
+			target = sections[to].path
+			if own {
+				l.Heading = ""
+			} else if l.Heading != "" {
+				parts := strings.Split(l.Heading, "#")
+				l.Heading = parts[len(parts)-1]
+			}
+		}
+		b.WriteString(content[last:l.start])
+		if l.wiki {
+			b.WriteString(rewriteWikiLink(l, target))
+		} else {
+			b.WriteString(rewriteMarkdownLink(l, sourceRel, target))
+		}
+		last = l.end
+	}
+	if last == 0 {
+		return content
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// splitSectionFor returns the section holding the anchor of l, a link to
+// the parent note, and whether the anchor is that section's own heading,
+// which stays behind in the parent. It returns -1 when the anchor does not
+// move.
+func splitSectionFor(sections []splitSection, l Link) (int, bool) {
+	if l.Block != "" {
+		for i, sec := range sections {
+			for _, id := range sec.blocks {
+				if id == l.Block {
+					return i, false
+				}
+			}
+		}
+		return -1, false
+	}
+	if l.Heading == "" {
+		return -1, false
+	}
+	parts := strings.Split(l.Heading, "#")
+	want := anchorHeading(parts[len(parts)-1])
+	for i, sec := range sections {
+		if anchorHeading(sec.heading.Text) == want {
+			return i, true
+		}
+		for _, h := range sec.headings {
+			if anchorHeading(h.Text) == want {
+				return i, false
+			}
+		}
+	}
+	return -1, false
+}
+
+// selectSplitSections returns the non-empty sections chosen by opts, each
+// running from its heading line to the next heading at the same or a
+// higher level. Heading-like lines in code fences are not headings.
+func selectSplitSections(n Note, lines []string, opts SplitOptions) ([]splitSection, error) {
+	if (opts.Heading == "") == (opts.Level == 0) {
+		return nil, errs.New(errs.ExitValidation, "pass exactly one of --heading or --level")
+	}
+	if opts.Level < 0 || opts.Level > 6 {
+		return nil, errs.New(errs.ExitValidation, "--level must be between 1 and 6")
+	}
+	headings := fencedHeadings(n.Body)
+	sections := []splitSection{}
+	for i, h := range headings {
+		if opts.Heading != "" && normalizeHeading(h.Text) != normalizeHeading(opts.Heading) {
+			continue
+		}
+		if opts.Level != 0 && h.Level != opts.Level {
+			continue
+		}
+		sec := splitSection{heading: h, start: h.Line - 1, end: len(lines)}
+		for _, later := range headings[i+1:] {
+			if later.Level <= h.Level {
+				sec.end = later.Line - 1
+				break
+			}
+			sec.headings = append(sec.headings, later)
+		}
+		content := strings.Join(lines[sec.start+1:sec.end], "\n")
+		if strings.TrimSpace(content) != "" {
+			sec.blocks = BlockIDs(content)
+			sections = append(sections, sec)
+		}
+		if opts.Heading != "" {
+			break
+		}
+	}
+	if len(sections) == 0 {
+		if opts.Heading != "" {
+			return nil, errs.New(errs.ExitNotFound, "heading not found or section is empty")
+		}
+		return nil, errs.New(errs.ExitNotFound, fmt.Sprintf("no non-empty level %d sections found", opts.Level))
+	}
+	return sections, nil
+}
+
+// inheritFrontmatter copies the requested parent keys. "all" takes every
+// key except title, aliases and timestamps, which belong to the parent.
+func inheritFrontmatter(parent Frontmatter, keys []string) Frontmatter {
+	values := frontmatter.FrontmatterToMap(parent)
+	out := map[string]any{}
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		switch key {
+		case "", "none":
+			continue
+		case "all":
+			for k, v := range values {
+				switch k {
+				case "title", "aliases", "created_at", "updated_at":
+					continue
+				}
+				out[k] = v
+			}
+			continue
+		}
+		if v, ok := values[key]; ok {
+			out[key] = v
+		}
+	}
+	return frontmatter.MapToFrontmatter(out)
+}
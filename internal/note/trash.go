@@ -0,0 +1,285 @@
+package note
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/history"
+)
+
+// TrashDir is the vault-local trash folder Obsidian uses for the "local"
+// trash option.
+const TrashDir = ".trash"
+
+// Trash options from .obsidian/app.json. TrashSystem cannot be reached
+// headlessly, so it falls back to the vault trash.
+const (
+	TrashLocal  = "local"
+	TrashSystem = "system"
+	TrashNone   = "none"
+)
+
+const trashRecordSuffix = ".trash.json"
+
+// TrashItem is one file or folder in the vault trash. OriginalPath is
+// empty for items trashed by Obsidian itself, which keeps no record. Size
+// is the total of every file in a folder.
+type TrashItem struct {
+	ID           string    `json:"id"`
+	OriginalPath string    `json:"original_path,omitempty"`
+	DeletedAt    time.Time `json:"deleted_at"`
+	Size         int64     `json:"size"`
+	Folder       bool      `json:"folder,omitempty"`
+}
+
+// TrashOption returns the trashOption setting from .obsidian/app.json,
+// defaulting to the vault trash.
+func TrashOption(vaultRoot string) string {
+	var cfg struct {
+		TrashOption string `json:"trashOption"`
+	}
+	if payload, err := os.ReadFile(filepath.Join(vaultRoot, ".obsidian", "app.json")); err == nil {
+		_ = json.Unmarshal(payload, &cfg)
+	}
+	switch cfg.TrashOption {
+	case TrashNone, TrashSystem:
+		return cfg.TrashOption
+	}
+	return TrashLocal
+}
+
+// Remove deletes a vault file the way the trashOption setting asks:
+// permanently for "none", otherwise into the vault trash with a record of
+// its original path. The returned item is empty for permanent deletes.
+func Remove(vaultRoot string, hist *history.Store, relPath string) (TrashItem, error) {
+	abs, rel, err := resolveVaultPath(vaultRoot, relPath)
+	if err != nil {
+		return TrashItem{}, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return TrashItem{}, errs.New(errs.ExitNotFound, "file not found")
+		}
+		return TrashItem{}, err
+	}
+	if TrashOption(vaultRoot) == TrashNone {
+		if err := hist.Capture(abs); err != nil {
+			return TrashItem{}, err
+		}
+		return TrashItem{}, os.Remove(abs)
+	}
+
+	dir := filepath.Join(vaultRoot, TrashDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return TrashItem{}, err
+	}
+	item := TrashItem{ID: freeTrashName(dir, path.Base(rel)), OriginalPath: rel, DeletedAt: time.Now().UTC(), Size: info.Size()}
+	payload, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return TrashItem{}, err
+	}
+	if err := hist.Touch(abs, filepath.Join(dir, item.ID), filepath.Join(dir, item.ID+trashRecordSuffix)); err != nil {
+		return TrashItem{}, err
+	}
+	if err := os.Rename(abs, filepath.Join(dir, item.ID)); err != nil {
+		return TrashItem{}, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, item.ID+trashRecordSuffix), payload, 0o644); err != nil {
+		return TrashItem{}, err
+	}
+	return item, nil
+}
+
+// ListTrash returns the files and folders at the top of the vault trash,
+// most recently deleted first.
+func ListTrash(vaultRoot string) ([]TrashItem, error) {
+	dir := filepath.Join(vaultRoot, TrashDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []TrashItem{}, nil
+		}
+		return nil, err
+	}
+	out := []TrashItem{}
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), trashRecordSuffix) {
+			continue
+		}
+		item, err := readTrashItem(dir, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].DeletedAt.Equal(out[j].DeletedAt) {
+			return out[i].DeletedAt.After(out[j].DeletedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// RestoreTrash moves a trash item, named by ID or original path, back to
+// its original path or to dst when set. An existing file at the target is
+// an error unless rename is set, in which case a free name is picked.
+func RestoreTrash(vaultRoot string, hist *history.Store, ref, dst string, rename bool) (TrashItem, string, error) {
+	item, err := findTrashItem(vaultRoot, ref)
+	if err != nil {
+		return TrashItem{}, "", err
+	}
+	target := item.OriginalPath
+	if strings.TrimSpace(dst) != "" {
+		target = dst
+	}
+	if target == "" {
+		return TrashItem{}, "", errs.New(errs.ExitValidation, "trash item "+item.ID+" has no recorded original path; pass --to")
+	}
+	targetAbs, targetRel, err := resolveVaultPath(vaultRoot, target)
+	if err != nil {
+		return TrashItem{}, "", err
+	}
+	if _, statErr := os.Stat(targetAbs); statErr == nil {
+		if !rename {
+			return TrashItem{}, "", errs.NewDetailed(
+				errs.ExitValidation,
+				"restore_target_exists",
+				"Pass --rename to restore under a free name, or --to another path.",
+				"cannot restore "+item.ID+": "+targetRel+" already exists",
+			)
+		}
+		name := freeTrashName(filepath.Dir(targetAbs), path.Base(targetRel))
+		targetRel = path.Join(path.Dir(targetRel), name)
+		targetAbs = filepath.Join(filepath.Dir(targetAbs), name)
+	}
+	if err := os.MkdirAll(filepath.Dir(targetAbs), 0o755); err != nil {
+		return TrashItem{}, "", err
+	}
+	dir := filepath.Join(vaultRoot, TrashDir)
+	if err := hist.Touch(filepath.Join(dir, item.ID), filepath.Join(dir, item.ID+trashRecordSuffix), targetAbs); err != nil {
+		return TrashItem{}, "", err
+	}
+	if err := os.Rename(filepath.Join(dir, item.ID), targetAbs); err != nil {
+		return TrashItem{}, "", err
+	}
+	_ = os.Remove(filepath.Join(dir, item.ID+trashRecordSuffix))
+	return item, targetRel, nil
+}
+
+// EmptyTrash permanently deletes trash items deleted before cutoff, or all
+// of them when cutoff is zero, and returns them.
+func EmptyTrash(vaultRoot string, hist *history.Store, cutoff time.Time, dryRun bool) ([]TrashItem, error) {
+	items, err := ListTrash(vaultRoot)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(vaultRoot, TrashDir)
+	out := []TrashItem{}
+	for _, item := range items {
+		if !cutoff.IsZero() && !item.DeletedAt.Before(cutoff) {
+			continue
+		}
+		out = append(out, item)
+		if dryRun {
+			continue
+		}
+		if err := hist.Touch(filepath.Join(dir, item.ID), filepath.Join(dir, item.ID+trashRecordSuffix)); err != nil {
+			return nil, err
+		}
+		if err := os.RemoveAll(filepath.Join(dir, item.ID)); err != nil {
+			return nil, err
+		}
+		_ = os.Remove(filepath.Join(dir, item.ID+trashRecordSuffix))
+	}
+	return out, nil
+}
+
+func findTrashItem(vaultRoot, ref string) (TrashItem, error) {
+	items, err := ListTrash(vaultRoot)
+	if err != nil {
+		return TrashItem{}, err
+	}
+	ref = strings.TrimPrefix(strings.ReplaceAll(strings.TrimSpace(ref), "\\", "/"), TrashDir+"/")
+	matches := []TrashItem{}
+	for _, item := range items {
+		if item.ID == ref {
+			return item, nil
+		}
+		if item.OriginalPath == ref || item.OriginalPath == normalizeNotePath(ref) {
+			matches = append(matches, item)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return TrashItem{}, errs.New(errs.ExitNotFound, "trash item not found: "+ref)
+	case 1:
+		return matches[0], nil
+	}
+	ids := make([]string, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.ID)
+	}
+	return TrashItem{}, errs.NewDetailed(
+		errs.ExitValidation,
+		"ambiguous_trash_item",
+		"Pass the trash item id instead of the original path.",
+		fmt.Sprintf("%q was deleted %d times: %s", ref, len(matches), strings.Join(ids, ", ")),
+	)
+}
+
+// readTrashItem loads the record kept next to a trashed file or folder,
+// falling back to its own metadata for items Obsidian trashed.
+func readTrashItem(dir, id string) (TrashItem, error) {
+	info, err := os.Stat(filepath.Join(dir, id))
+	if err != nil {
+		return TrashItem{}, err
+	}
+	item := TrashItem{ID: id, DeletedAt: info.ModTime().UTC(), Size: info.Size(), Folder: info.IsDir()}
+	if item.Folder {
+		item.Size = 0
+		err := filepath.WalkDir(filepath.Join(dir, id), func(_ string, d os.DirEntry, walkErr error) error {
+			if walkErr != nil || d.IsDir() {
+				return walkErr
+			}
+			fileInfo, err := d.Info()
+			if err != nil {
+				return err
+			}
+			item.Size += fileInfo.Size()
+			return nil
+		})
+		if err != nil {
+			return TrashItem{}, err
+		}
+	}
+	if payload, err := os.ReadFile(filepath.Join(dir, id+trashRecordSuffix)); err == nil {
+		var record TrashItem
+		if json.Unmarshal(payload, &record) == nil {
+			item.OriginalPath, item.DeletedAt = record.OriginalPath, record.DeletedAt
+		}
+	}
+	return item, nil
+}
+
+// freeTrashName returns name, or "name 1.ext", "name 2.ext", ... when dir
+// already holds a file with that name.
+func freeTrashName(dir, name string) string {
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 1; ; i++ {
+		if _, err := os.Stat(filepath.Join(dir, candidate)); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s %d%s", stem, i, ext)
+	}
+}
@@ -0,0 +1,120 @@
+package note
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTrashRemoveRestoreAndEmpty(t *testing.T) {
+	root := t.TempDir()
+	for _, rel := range []string{"a/plan.md", "b/plan.md"} {
+		abs := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(abs, []byte(rel), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	first, err := Remove(root, nil, "a/plan.md")
+	if err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	second, err := Remove(root, nil, "b/plan.md")
+	if err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if first.ID != "plan.md" || second.ID != "plan 1.md" || second.OriginalPath != "b/plan.md" {
+		t.Fatalf("unexpected trash items %+v %+v", first, second)
+	}
+	items, err := ListTrash(root)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("list: %+v (%v)", items, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "a", "plan.md"), []byte("new"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, _, err := RestoreTrash(root, nil, "plan.md", "", false); err == nil {
+		t.Fatal("expected restore conflict")
+	}
+	if _, restored, err := RestoreTrash(root, nil, "plan.md", "", true); err != nil || restored != "a/plan 1.md" {
+		t.Fatalf("restore with rename: %q (%v)", restored, err)
+	}
+	if _, restored, err := RestoreTrash(root, nil, "b/plan.md", "", false); err != nil || restored != "b/plan.md" {
+		t.Fatalf("restore by original path: %q (%v)", restored, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "c.md"), []byte("c"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := Remove(root, nil, "c.md"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if emptied, err := EmptyTrash(root, nil, time.Now().Add(-time.Hour), false); err != nil || len(emptied) != 0 {
+		t.Fatalf("expected recent items to be kept, got %+v (%v)", emptied, err)
+	}
+	if emptied, err := EmptyTrash(root, nil, time.Time{}, false); err != nil || len(emptied) != 1 {
+		t.Fatalf("empty: %+v (%v)", emptied, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(root, TrashDir)); len(entries) != 0 {
+		t.Fatalf("expected empty trash folder, got %d entries", len(entries))
+	}
+}
+
+func TestTrashOptionNoneDeletesPermanently(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".obsidian"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".obsidian", "app.json"), []byte(`{"trashOption":"none"}`), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "gone.md"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := Delete(root, nil, "gone"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, TrashDir)); !os.IsNotExist(err) {
+		t.Fatalf("expected no trash folder, got %v", err)
+	}
+}
+
+func TestTrashListsRestoresAndEmptiesFolders(t *testing.T) {
+	root := t.TempDir()
+	// Obsidian moves deleted folders into the trash as they are.
+	for _, rel := range []string{".trash/Projects/a.md", ".trash/Projects/sub/b.md", ".trash/Old/c.md"} {
+		abs := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(abs, []byte("xyz"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	items, err := ListTrash(root)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("list: %+v (%v)", items, err)
+	}
+	for _, item := range items {
+		if !item.Folder || (item.ID == "Projects" && item.Size != 6) {
+			t.Fatalf("unexpected folder item %+v", item)
+		}
+	}
+
+	if _, restored, err := RestoreTrash(root, nil, "Projects", "Projects", false); err != nil || restored != "Projects" {
+		t.Fatalf("restore folder: %q (%v)", restored, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "Projects", "sub", "b.md")); err != nil {
+		t.Fatalf("expected restored folder contents: %v", err)
+	}
+	if emptied, err := EmptyTrash(root, nil, time.Time{}, false); err != nil || len(emptied) != 1 || emptied[0].ID != "Old" {
+		t.Fatalf("empty: %+v (%v)", emptied, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(root, TrashDir)); len(entries) != 0 {
+		t.Fatalf("expected empty trash folder, got %d entries", len(entries))
+	}
+}
@@ -1,39 +1,39 @@
 package note
 
 import (
-	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/nightisyang/obsidian-cli/internal/frontmatter"
+	"github.com/nightisyang/obsidian-cli/internal/history"
 )
 
-func Write(vaultRoot, relPath string, n Note, creating bool, now time.Time) (Note, error) {
+func Write(vaultRoot string, hist *history.Store, relPath string, n Note, creating bool, now time.Time) (Note, error) {
 	abs, normalized, err := resolveNoteAbs(vaultRoot, relPath)
 	if err != nil {
 		return Note{}, err
 	}
 
-	if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
-		return Note{}, err
-	}
-
-	applyTimestamps(&n.Frontmatter, creating, now)
-	rendered, err := frontmatter.RenderMarkdown(n.Frontmatter, n.Body)
+	n.Path = normalized
+	n, err = Render(n, creating, now)
 	if err != nil {
 		return Note{}, err
 	}
 
-	tmp := abs + ".tmp"
-	if err := os.WriteFile(tmp, []byte(rendered), 0o644); err != nil {
+	if err := hist.WriteFile(abs, []byte(n.Raw)); err != nil {
 		return Note{}, err
 	}
-	if err := os.Rename(tmp, abs); err != nil {
+	return n, nil
+}
+
+// Render stamps timestamps and renders the note into Raw without writing it.
+func Render(n Note, creating bool, now time.Time) (Note, error) {
+	applyTimestamps(&n.Frontmatter, creating, now)
+	rendered, err := frontmatter.RenderMarkdown(n.Frontmatter, n.Body)
+	if err != nil {
 		return Note{}, err
 	}
-
-	n.Path = normalized
-	n.Title = titleFromPath(normalized)
+	n.Path = normalizeNotePath(n.Path)
+	n.Title = titleFromPath(n.Path)
 	n.Raw = rendered
 	return n, nil
 }
@@ -0,0 +1,378 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+)
+
+// BM25IndexFile is the inverted index written inside the index dir.
+const BM25IndexFile = "search-index.json"
+
+const (
+	bm25IndexVersion = 1
+	bm25K1           = 1.2
+	bm25B            = 0.75
+	titleBoost       = 2.0
+	headingBoost     = 1.0
+)
+
+var bm25HeadingPattern = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*$`)
+
+// BM25Engine ranks notes with Okapi BM25 over an inverted index persisted
+// in IndexDir. Terms found in the note title or a heading get a boost.
+// The index is refreshed incrementally from file mtime and size.
+type BM25Engine struct {
+	VaultRoot string
+	IndexDir  string
+}
+
+type bm25Doc struct {
+	MTime    int64    `json:"mtime"`
+	Size     int64    `json:"size"`
+	Length   int      `json:"length"`
+	Terms    []string `json:"terms"`
+	Title    []string `json:"title"`
+	Headings []string `json:"headings"`
+}
+
+type bm25Index struct {
+	Version  int                       `json:"version"`
+	Docs     map[string]bm25Doc        `json:"docs"`
+	Postings map[string]map[string]int `json:"postings"`
+}
+
+func (e *BM25Engine) Search(_ context.Context, q Query) ([]SearchResult, error) {
+	if q.Type != QueryText {
+		return nil, errs.New(errs.ExitValidation, "bm25 engine supports text queries only")
+	}
+	prefix, err := e.resolvePrefix(q.Path)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := e.refresh()
+	if err != nil {
+		return nil, err
+	}
+
+	terms := uniqueTokens(q.Text)
+	if len(terms) == 0 {
+		return []SearchResult{}, nil
+	}
+	scores := idx.score(terms)
+	paths := make([]string, 0, len(scores))
+	for path := range scores {
+		if prefix != "" && !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if scores[paths[i]] == scores[paths[j]] {
+			return paths[i] < paths[j]
+		}
+		return scores[paths[i]] > scores[paths[j]]
+	})
+
+	results := []SearchResult{}
+	for _, path := range paths {
+		result, ok := e.locate(path, terms, q)
+		if !ok {
+			continue
+		}
+		result.Score = math.Round(scores[path]*1000) / 1000
+		results = append(results, result)
+		if q.Limit > 0 && len(results) >= q.Limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// refresh loads the persisted index, re-indexes changed files and saves it
+// when anything changed.
+func (e *BM25Engine) refresh() (bm25Index, error) {
+	idx := e.load()
+	changed := false
+	seen := map[string]struct{}{}
+	root := filepath.Clean(e.VaultRoot)
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(strings.ToLower(d.Name()), ".md") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		seen[rel] = struct{}{}
+		if prev, ok := idx.Docs[rel]; ok && prev.MTime == info.ModTime().UnixNano() && prev.Size == info.Size() {
+			return nil
+		}
+		payload, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		idx.remove(rel)
+		idx.add(rel, string(payload), info)
+		changed = true
+		return nil
+	})
+	if err != nil {
+		return bm25Index{}, errs.Wrap(errs.ExitGeneric, "failed to index vault for search", err)
+	}
+	for rel := range idx.Docs {
+		if _, ok := seen[rel]; !ok {
+			idx.remove(rel)
+			changed = true
+		}
+	}
+	if changed {
+		if err := e.save(idx); err != nil {
+			return bm25Index{}, err
+		}
+	}
+	return idx, nil
+}
+
+func (e *BM25Engine) indexPath() string {
+	dir := strings.TrimSpace(e.IndexDir)
+	if dir == "" {
+		dir = ".obsidian-cli-index"
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(e.VaultRoot, dir)
+	}
+	return filepath.Join(dir, BM25IndexFile)
+}
+
+func (e *BM25Engine) load() bm25Index {
+	empty := bm25Index{Version: bm25IndexVersion, Docs: map[string]bm25Doc{}, Postings: map[string]map[string]int{}}
+	payload, err := os.ReadFile(e.indexPath())
+	if err != nil {
+		return empty
+	}
+	var idx bm25Index
+	if err := json.Unmarshal(payload, &idx); err != nil || idx.Version != bm25IndexVersion || idx.Docs == nil || idx.Postings == nil {
+		return empty
+	}
+	return idx
+}
+
+func (e *BM25Engine) save(idx bm25Index) error {
+	payload, err := json.Marshal(idx)
+	if err != nil {
+		return errs.Wrap(errs.ExitGeneric, "failed to encode search index", err)
+	}
+	target := e.indexPath()
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return errs.Wrap(errs.ExitGeneric, "failed to create index dir", err)
+	}
+	tmp := target + ".tmp"
+	if err := os.WriteFile(tmp, payload, 0o644); err != nil {
+		return errs.Wrap(errs.ExitGeneric, "failed to write search index", err)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		return errs.Wrap(errs.ExitGeneric, "failed to write search index", err)
+	}
+	return nil
+}
+
+func (e *BM25Engine) resolvePrefix(pathPrefix string) (string, error) {
+	clean := strings.TrimSpace(pathPrefix)
+	if clean == "" {
+		return "", nil
+	}
+	root := filepath.Clean(e.VaultRoot)
+	candidate := filepath.Clean(filepath.Join(root, filepath.FromSlash(clean)))
+	if candidate == root {
+		return "", nil
+	}
+	if !strings.HasPrefix(candidate, root+string(filepath.Separator)) {
+		return "", errs.New(errs.ExitValidation, "search path escapes vault root")
+	}
+	rel, _ := filepath.Rel(root, candidate)
+	return filepath.ToSlash(rel), nil
+}
+
+// locate finds the first line of a ranked note mentioning a query term.
+func (e *BM25Engine) locate(rel string, terms []string, q Query) (SearchResult, bool) {
+	payload, err := os.ReadFile(filepath.Join(e.VaultRoot, filepath.FromSlash(rel)))
+	if err != nil {
+		return SearchResult{}, false
+	}
+	if q.CaseSensitive && !strings.Contains(string(payload), q.Text) {
+		return SearchResult{}, false
+	}
+	result := SearchResult{Path: rel, MatchType: "text"}
+	for i, line := range strings.Split(string(payload), "\n") {
+		lower := strings.ToLower(line)
+		if len(lower) != len(line) {
+			line = lower
+		}
+		for _, term := range terms {
+			col := strings.Index(lower, term)
+			if col < 0 {
+				continue
+			}
+			start := len([]rune(line[:col]))
+			end := start + len([]rune(line[col:col+len(term)]))
+			result.Line = i + 1
+			result.Column = start + 1
+			result.Match = line[col : col+len(term)]
+			result.Snippet = trimContext(strings.TrimRight(line, "\r"), start, end, q.Context)
+			return result, true
+		}
+	}
+	// Title-only hits have no body line to point at.
+	result.Match = q.Text
+	result.Snippet = "title match"
+	return result, true
+}
+
+func (idx *bm25Index) add(rel, content string, info os.FileInfo) {
+	freqs := map[string]int{}
+	length := 0
+	headings := []string{}
+	for _, line := range strings.Split(content, "\n") {
+		if m := bm25HeadingPattern.FindStringSubmatch(strings.TrimRight(line, "\r")); len(m) == 2 {
+			headings = append(headings, tokenize(m[1])...)
+		}
+		for _, token := range tokenize(line) {
+			freqs[token]++
+			length++
+		}
+	}
+	title := tokenize(strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel)))
+	for _, token := range title {
+		if _, ok := freqs[token]; !ok {
+			freqs[token] = 0
+		}
+	}
+
+	terms := make([]string, 0, len(freqs))
+	for term, tf := range freqs {
+		terms = append(terms, term)
+		if idx.Postings[term] == nil {
+			idx.Postings[term] = map[string]int{}
+		}
+		idx.Postings[term][rel] = tf
+	}
+	sort.Strings(terms)
+	idx.Docs[rel] = bm25Doc{
+		MTime:    info.ModTime().UnixNano(),
+		Size:     info.Size(),
+		Length:   length,
+		Terms:    terms,
+		Title:    dedupeTokens(title),
+		Headings: dedupeTokens(headings),
+	}
+}
+
+func (idx *bm25Index) remove(rel string) {
+	doc, ok := idx.Docs[rel]
+	if !ok {
+		return
+	}
+	for _, term := range doc.Terms {
+		delete(idx.Postings[term], rel)
+		if len(idx.Postings[term]) == 0 {
+			delete(idx.Postings, term)
+		}
+	}
+	delete(idx.Docs, rel)
+}
+
+func (idx bm25Index) score(terms []string) map[string]float64 {
+	n := float64(len(idx.Docs))
+	if n == 0 {
+		return map[string]float64{}
+	}
+	total := 0
+	for _, doc := range idx.Docs {
+		total += doc.Length
+	}
+	avgLen := float64(total) / n
+	if avgLen == 0 {
+		avgLen = 1
+	}
+
+	scores := map[string]float64{}
+	for _, term := range terms {
+		postings := idx.Postings[term]
+		df := float64(len(postings))
+		if df == 0 {
+			continue
+		}
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for path, tf := range postings {
+			doc := idx.Docs[path]
+			score := 0.0
+			if tf > 0 {
+				f := float64(tf)
+				score = idf * f * (bm25K1 + 1) / (f + bm25K1*(1-bm25B+bm25B*float64(doc.Length)/avgLen))
+			}
+			if containsToken(doc.Title, term) {
+				score += titleBoost * idf
+			}
+			if containsToken(doc.Headings, term) {
+				score += headingBoost * idf
+			}
+			scores[path] += score
+		}
+	}
+	return scores
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func uniqueTokens(text string) []string {
+	return dedupeTokens(tokenize(text))
+}
+
+func dedupeTokens(tokens []string) []string {
+	seen := map[string]struct{}{}
+	out := []string{}
+	for _, token := range tokens {
+		if _, ok := seen[token]; ok {
+			continue
+		}
+		seen[token] = struct{}{}
+		out = append(out, token)
+	}
+	return out
+}
+
+func containsToken(tokens []string, token string) bool {
+	for _, t := range tokens {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
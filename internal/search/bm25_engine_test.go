@@ -0,0 +1,72 @@
+package search
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBM25EngineRanksByRelevance(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"passing.md":    "Some notes that mention kubernetes once among many other words about cooking and travel.",
+		"dense.md":      "kubernetes kubernetes cluster kubernetes upgrade",
+		"kubernetes.md": "Runbook\n\nsteps here",
+		"heading.md":    "# Kubernetes\nA short note.",
+		"other.md":      "nothing relevant",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	engine := &BM25Engine{VaultRoot: root, IndexDir: ".obsidian-cli-index"}
+	results, err := engine.Search(context.Background(), Query{Type: QueryText, Text: "Kubernetes", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("expected 4 ranked results, got %+v", results)
+	}
+	boosted := map[string]bool{results[0].Path: true, results[1].Path: true}
+	if !boosted["kubernetes.md"] || !boosted["heading.md"] {
+		t.Fatalf("expected title and heading matches first, got %+v", results)
+	}
+	if results[len(results)-1].Path != "passing.md" {
+		t.Fatalf("expected passing mention last, got %+v", results)
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Score > results[i-1].Score {
+			t.Fatalf("results not sorted by score: %+v", results)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, ".obsidian-cli-index", BM25IndexFile)); err != nil {
+		t.Fatalf("expected persisted search index: %v", err)
+	}
+
+	// Changed files are re-indexed on the next search.
+	if err := os.WriteFile(filepath.Join(root, "other.md"), []byte("kubernetes kubernetes kubernetes kubernetes"), 0o644); err != nil {
+		t.Fatalf("rewrite other: %v", err)
+	}
+	future := time.Now().Add(2 * time.Second)
+	if err := os.Chtimes(filepath.Join(root, "other.md"), future, future); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	results, err = engine.Search(context.Background(), Query{Type: QueryText, Text: "kubernetes", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search after change error: %v", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("expected re-indexed file in results, got %+v", results)
+	}
+}
+
+func TestBM25EngineRejectsEscapingPath(t *testing.T) {
+	engine := &BM25Engine{VaultRoot: t.TempDir()}
+	if _, err := engine.Search(context.Background(), Query{Type: QueryText, Text: "x", Path: "../outside"}); err == nil {
+		t.Fatalf("expected escaping path to fail")
+	}
+}
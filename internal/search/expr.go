@@ -0,0 +1,335 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+)
+
+type ExprKind string
+
+const (
+	ExprAnd      ExprKind = "and"
+	ExprOr       ExprKind = "or"
+	ExprNot      ExprKind = "not"
+	ExprTerm     ExprKind = "term"
+	ExprField    ExprKind = "field"
+	ExprProperty ExprKind = "property"
+)
+
+// Expr is a node of a compiled Obsidian search query.
+//
+// Terms carry Value (Phrase when it was quoted). Field nodes carry the
+// operator name in Field and their scoped sub-query in Children[0].
+// Property nodes carry the property name and an optional Value; an empty
+// Value tests for existence.
+type Expr struct {
+	Kind     ExprKind `json:"kind"`
+	Field    string   `json:"field,omitempty"`
+	Property string   `json:"property,omitempty"`
+	Value    string   `json:"value,omitempty"`
+	Phrase   bool     `json:"phrase,omitempty"`
+	Children []*Expr  `json:"children,omitempty"`
+}
+
+var exprFields = map[string]struct{}{
+	"file":      {},
+	"path":      {},
+	"tag":       {},
+	"line":      {},
+	"section":   {},
+	"task":      {},
+	"task-todo": {},
+	"task-done": {},
+	"content":   {},
+}
+
+// ParseExpr compiles a query written in Obsidian's search syntax: implicit
+// AND between terms, OR, leading '-' for negation, quoted phrases,
+// parentheses, field operators such as file:, path:, tag:, line:,
+// section:, task:, task-todo:, task-done:, and [property:value] filters.
+func ParseExpr(raw string) (*Expr, error) {
+	p := &exprParser{src: []rune(raw)}
+	p.skipSpace()
+	if p.eof() {
+		return nil, errs.New(errs.ExitValidation, "search query is empty")
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.eof() {
+		if p.peek() == ')' {
+			return nil, errs.New(errs.ExitValidation, "search query has an unmatched ')'")
+		}
+		return nil, errs.New(errs.ExitValidation, "unexpected input in search query")
+	}
+	return expr, nil
+}
+
+type exprParser struct {
+	src []rune
+	pos int
+}
+
+func (p *exprParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *exprParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *exprParser) skipSpace() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+// keyword reports whether the next bare word is kw (case-sensitive, as in Obsidian).
+func (p *exprParser) keyword(kw string) bool {
+	end := p.pos + len(kw)
+	if end > len(p.src) || string(p.src[p.pos:end]) != kw {
+		return false
+	}
+	return end == len(p.src) || unicode.IsSpace(p.src[end]) || p.src[end] == '('
+}
+
+func (p *exprParser) parseOr() (*Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []*Expr{left}
+	for {
+		p.skipSpace()
+		if !p.keyword("OR") {
+			break
+		}
+		p.pos += len("OR")
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, right)
+	}
+	if len(children) == 1 {
+		return left, nil
+	}
+	return &Expr{Kind: ExprOr, Children: children}, nil
+}
+
+func (p *exprParser) parseAnd() (*Expr, error) {
+	children := []*Expr{}
+	for {
+		p.skipSpace()
+		if p.eof() || p.peek() == ')' || p.keyword("OR") {
+			break
+		}
+		if p.keyword("AND") {
+			p.pos += len("AND")
+			continue
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	switch len(children) {
+	case 0:
+		return nil, errs.New(errs.ExitValidation, "search query is missing a term")
+	case 1:
+		return children[0], nil
+	default:
+		return &Expr{Kind: ExprAnd, Children: children}, nil
+	}
+}
+
+func (p *exprParser) parseUnary() (*Expr, error) {
+	if p.peek() == '-' && p.pos+1 < len(p.src) && !unicode.IsSpace(p.src[p.pos+1]) {
+		p.pos++
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Expr{Kind: ExprNot, Children: []*Expr{child}}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (*Expr, error) {
+	switch p.peek() {
+	case '(':
+		return p.parseGroup()
+	case '"':
+		value, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return &Expr{Kind: ExprTerm, Value: value, Phrase: true}, nil
+	case '[':
+		return p.parseProperty()
+	}
+
+	word := p.readWord()
+	if idx := strings.Index(word, ":"); idx > 0 {
+		field := strings.ToLower(word[:idx])
+		if _, ok := exprFields[field]; ok {
+			rest := word[idx+1:]
+			if rest != "" {
+				return &Expr{Kind: ExprField, Field: field, Children: []*Expr{{Kind: ExprTerm, Value: rest}}}, nil
+			}
+			value, err := p.parseFieldValue(field)
+			if err != nil {
+				return nil, err
+			}
+			return &Expr{Kind: ExprField, Field: field, Children: []*Expr{value}}, nil
+		}
+	}
+	if word == "" {
+		return nil, errs.New(errs.ExitValidation, "search query is missing a term")
+	}
+	return &Expr{Kind: ExprTerm, Value: word}, nil
+}
+
+// parseFieldValue reads the value following "field:" when it is a quoted
+// phrase or a parenthesized sub-query.
+func (p *exprParser) parseFieldValue(field string) (*Expr, error) {
+	switch p.peek() {
+	case '"':
+		value, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return &Expr{Kind: ExprTerm, Value: value, Phrase: true}, nil
+	case '(':
+		return p.parseGroup()
+	default:
+		return nil, errs.New(errs.ExitValidation, field+": requires a value")
+	}
+}
+
+func (p *exprParser) parseGroup() (*Expr, error) {
+	p.pos++ // (
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.peek() != ')' {
+		return nil, errs.New(errs.ExitValidation, "search query has an unclosed '('")
+	}
+	p.pos++
+	return expr, nil
+}
+
+func (p *exprParser) parseQuoted() (string, error) {
+	p.pos++ // opening quote
+	var b strings.Builder
+	for !p.eof() {
+		r := p.src[p.pos]
+		p.pos++
+		switch {
+		case r == '\\' && !p.eof():
+			b.WriteRune(p.src[p.pos])
+			p.pos++
+		case r == '"':
+			return b.String(), nil
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return "", errs.New(errs.ExitValidation, "search query has an unclosed quote")
+}
+
+func (p *exprParser) parseProperty() (*Expr, error) {
+	p.pos++ // [
+	start := p.pos
+	inQuote := false
+	for !p.eof() {
+		r := p.src[p.pos]
+		if r == '"' {
+			inQuote = !inQuote
+		}
+		if r == ']' && !inQuote {
+			break
+		}
+		p.pos++
+	}
+	if p.eof() {
+		return nil, errs.New(errs.ExitValidation, "search query has an unclosed '['")
+	}
+	inner := string(p.src[start:p.pos])
+	p.pos++ // ]
+
+	name, value, _ := strings.Cut(inner, ":")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errs.New(errs.ExitValidation, "property filter requires a property name")
+	}
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		value = value[1 : len(value)-1]
+	}
+	return &Expr{Kind: ExprProperty, Property: name, Value: value}, nil
+}
+
+func (p *exprParser) readWord() string {
+	start := p.pos
+	for !p.eof() {
+		r := p.peek()
+		if unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' {
+			break
+		}
+		p.pos++
+		if r == ':' {
+			// Stop after "field:" so a quoted or grouped value is parsed separately.
+			if next := p.peek(); next == '"' || next == '(' {
+				break
+			}
+		}
+	}
+	return string(p.src[start:p.pos])
+}
+
+// Terms lists the positive (non-negated) search terms of an expression,
+// used to locate a snippet for a matching note.
+func (e *Expr) Terms() []string {
+	out := []string{}
+	var walk func(node *Expr, negated bool)
+	walk = func(node *Expr, negated bool) {
+		if node == nil {
+			return
+		}
+		switch node.Kind {
+		case ExprNot:
+			for _, child := range node.Children {
+				walk(child, !negated)
+			}
+		case ExprTerm:
+			if !negated && node.Value != "" {
+				out = append(out, node.Value)
+			}
+		case ExprField:
+			switch node.Field {
+			case "file", "path", "tag":
+				return
+			}
+			for _, child := range node.Children {
+				walk(child, negated)
+			}
+		default:
+			for _, child := range node.Children {
+				walk(child, negated)
+			}
+		}
+	}
+	walk(e, false)
+	return out
+}
@@ -0,0 +1,273 @@
+package search
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	exprHeadingPattern = regexp.MustCompile(`^#{1,6}\s+`)
+	exprTaskPattern    = regexp.MustCompile(`^\s*[-*+]\s+\[(.)\]\s*(.*)$`)
+)
+
+// Document is the view of a note an Expr is evaluated against.
+type Document struct {
+	Path       string
+	Content    string
+	Tags       []string
+	Properties map[string]any
+}
+
+// Match reports whether doc satisfies the expression.
+func (e *Expr) Match(doc Document, caseSensitive bool) bool {
+	ev := exprEvaluator{doc: doc, caseSensitive: caseSensitive}
+	return ev.evalDoc(e)
+}
+
+// MatchExpr evaluates q.Expr against doc and, on a match, points the
+// result at the first line containing one of the query's positive terms.
+func MatchExpr(doc Document, q Query) (SearchResult, bool) {
+	if q.Expr == nil || !q.Expr.Match(doc, q.CaseSensitive) {
+		return SearchResult{}, false
+	}
+	result := SearchResult{Path: doc.Path, Match: q.Raw, Snippet: "query match", MatchType: "query"}
+	terms := q.Expr.Terms()
+	for i, line := range strings.Split(doc.Content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if !q.CaseSensitive {
+			if lower := strings.ToLower(line); len(lower) != len(line) {
+				// Keep byte offsets aligned with the text being searched.
+				line = lower
+			}
+		}
+		for _, term := range terms {
+			idx := indexFold(line, term, q.CaseSensitive)
+			if idx < 0 {
+				continue
+			}
+			start := len([]rune(line[:idx]))
+			end := start + len([]rune(term))
+			result.Line = i + 1
+			result.Column = start + 1
+			result.Match = line[idx : idx+len(term)]
+			result.Snippet = trimContext(line, start, end, q.Context)
+			return result, true
+		}
+	}
+	return result, true
+}
+
+type exprEvaluator struct {
+	doc           Document
+	caseSensitive bool
+}
+
+func (ev exprEvaluator) evalDoc(e *Expr) bool {
+	switch e.Kind {
+	case ExprAnd:
+		for _, child := range e.Children {
+			if !ev.evalDoc(child) {
+				return false
+			}
+		}
+		return true
+	case ExprOr:
+		for _, child := range e.Children {
+			if ev.evalDoc(child) {
+				return true
+			}
+		}
+		return false
+	case ExprNot:
+		return !ev.evalDoc(e.Children[0])
+	case ExprTerm:
+		return ev.contains(ev.doc.Content, e.Value) || ev.contains(ev.doc.Path, e.Value)
+	case ExprProperty:
+		return ev.evalProperty(e)
+	case ExprField:
+		return ev.evalField(e)
+	default:
+		return false
+	}
+}
+
+// evalText evaluates e against a single scope such as a line or section.
+func (ev exprEvaluator) evalText(e *Expr, text string) bool {
+	switch e.Kind {
+	case ExprAnd:
+		for _, child := range e.Children {
+			if !ev.evalText(child, text) {
+				return false
+			}
+		}
+		return true
+	case ExprOr:
+		for _, child := range e.Children {
+			if ev.evalText(child, text) {
+				return true
+			}
+		}
+		return false
+	case ExprNot:
+		return !ev.evalText(e.Children[0], text)
+	case ExprTerm:
+		return ev.contains(text, e.Value)
+	default:
+		return ev.evalDoc(e)
+	}
+}
+
+func (ev exprEvaluator) evalField(e *Expr) bool {
+	value := e.Children[0]
+	switch e.Field {
+	case "file":
+		return ev.evalText(value, path.Base(ev.doc.Path))
+	case "path":
+		return ev.evalText(value, ev.doc.Path)
+	case "content":
+		return ev.evalText(value, ev.doc.Content)
+	case "tag":
+		return ev.evalTag(value)
+	case "line":
+		return ev.anyScope(value, strings.Split(ev.doc.Content, "\n"))
+	case "section":
+		return ev.anyScope(value, sections(ev.doc.Content))
+	case "task", "task-todo", "task-done":
+		return ev.anyScope(value, taskTexts(ev.doc.Content, e.Field))
+	default:
+		return false
+	}
+}
+
+func (ev exprEvaluator) anyScope(e *Expr, scopes []string) bool {
+	for _, scope := range scopes {
+		if ev.evalText(e, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ev exprEvaluator) evalTag(e *Expr) bool {
+	switch e.Kind {
+	case ExprAnd:
+		for _, child := range e.Children {
+			if !ev.evalTag(child) {
+				return false
+			}
+		}
+		return true
+	case ExprOr:
+		for _, child := range e.Children {
+			if ev.evalTag(child) {
+				return true
+			}
+		}
+		return false
+	case ExprNot:
+		return !ev.evalTag(e.Children[0])
+	case ExprTerm:
+		want := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(e.Value), "#"))
+		for _, tag := range ev.doc.Tags {
+			tag = strings.ToLower(tag)
+			if tag == want || strings.HasPrefix(tag, want+"/") {
+				return true
+			}
+		}
+		return false
+	default:
+		return ev.evalDoc(e)
+	}
+}
+
+func (ev exprEvaluator) evalProperty(e *Expr) bool {
+	value, ok := lookupProperty(ev.doc.Properties, e.Property)
+	if !ok {
+		return false
+	}
+	if e.Value == "" {
+		return true
+	}
+	for _, item := range propertyItems(value) {
+		if ev.contains(item, e.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ev exprEvaluator) contains(haystack, needle string) bool {
+	return indexFold(haystack, needle, ev.caseSensitive) >= 0
+}
+
+func indexFold(haystack, needle string, caseSensitive bool) int {
+	if caseSensitive {
+		return strings.Index(haystack, needle)
+	}
+	return strings.Index(strings.ToLower(haystack), strings.ToLower(needle))
+}
+
+func lookupProperty(props map[string]any, name string) (any, bool) {
+	if value, ok := props[name]; ok {
+		return value, true
+	}
+	for key, value := range props {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func propertyItems(value any) []string {
+	switch v := value.(type) {
+	case nil:
+		return []string{}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			out = append(out, fmt.Sprint(item))
+		}
+		return out
+	case []string:
+		return v
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// sections splits content into heading-delimited chunks, each including
+// its heading line.
+func sections(content string) []string {
+	out := []string{}
+	current := []string{}
+	for _, line := range strings.Split(content, "\n") {
+		if exprHeadingPattern.MatchString(line) && len(current) > 0 {
+			out = append(out, strings.Join(current, "\n"))
+			current = current[:0]
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		out = append(out, strings.Join(current, "\n"))
+	}
+	return out
+}
+
+func taskTexts(content, field string) []string {
+	out := []string{}
+	for _, line := range strings.Split(content, "\n") {
+		m := exprTaskPattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if len(m) != 3 {
+			continue
+		}
+		done := m[1] != " "
+		if (field == "task-todo" && done) || (field == "task-done" && !done) {
+			continue
+		}
+		out = append(out, m[2])
+	}
+	return out
+}
@@ -0,0 +1,89 @@
+package search
+
+import "testing"
+
+func TestParseExprStructure(t *testing.T) {
+	expr, err := ParseExpr(`meeting -draft (alpha OR "beta gamma") tag:#work [status:active]`)
+	if err != nil {
+		t.Fatalf("ParseExpr error: %v", err)
+	}
+	if expr.Kind != ExprAnd || len(expr.Children) != 5 {
+		t.Fatalf("expected AND of 5 children, got %+v", expr)
+	}
+	if c := expr.Children[1]; c.Kind != ExprNot || c.Children[0].Value != "draft" {
+		t.Fatalf("expected negated draft, got %+v", c)
+	}
+	if c := expr.Children[2]; c.Kind != ExprOr || len(c.Children) != 2 || !c.Children[1].Phrase {
+		t.Fatalf("expected OR with phrase, got %+v", c)
+	}
+	if c := expr.Children[3]; c.Kind != ExprField || c.Field != "tag" || c.Children[0].Value != "#work" {
+		t.Fatalf("expected tag field, got %+v", c)
+	}
+	if c := expr.Children[4]; c.Kind != ExprProperty || c.Property != "status" || c.Value != "active" {
+		t.Fatalf("expected property filter, got %+v", c)
+	}
+}
+
+func TestParseExprErrors(t *testing.T) {
+	for _, raw := range []string{"", "(alpha", "alpha)", `"open`, "[status", "a OR", "line:"} {
+		if _, err := ParseExpr(raw); err == nil {
+			t.Fatalf("expected parse error for %q", raw)
+		}
+	}
+}
+
+func TestExprMatch(t *testing.T) {
+	doc := Document{
+		Path:       "projects/Plan.md",
+		Content:    "# Goals\nship the alpha release\n\n# Tasks\n- [ ] write docs\n- [x] fix bug\n",
+		Tags:       []string{"work/q3"},
+		Properties: map[string]any{"status": "active", "owners": []any{"ana", "bo"}},
+	}
+	cases := map[string]bool{
+		"alpha":                           true,
+		"alpha -release":                  false,
+		"missing OR alpha":                true,
+		`"alpha release"`:                 true,
+		`"release alpha"`:                 false,
+		"file:plan":                       true,
+		"path:projects/":                  true,
+		"tag:#work":                       true,
+		"tag:wor":                         false,
+		"line:(ship alpha)":               true,
+		"line:(ship docs)":                false,
+		"section:(goals alpha)":           true,
+		"section:(goals docs)":            false,
+		"task:docs":                       true,
+		"task-todo:bug":                   false,
+		"task-done:bug":                   true,
+		"[status:active]":                 true,
+		"[status]":                        true,
+		"[owners:bo]":                     true,
+		"[due]":                           false,
+		"-[status:draft] (docs OR other)": true,
+	}
+	for raw, want := range cases {
+		expr, err := ParseExpr(raw)
+		if err != nil {
+			t.Fatalf("ParseExpr(%q) error: %v", raw, err)
+		}
+		if got := expr.Match(doc, false); got != want {
+			t.Fatalf("Match(%q) = %v, want %v", raw, got, want)
+		}
+	}
+}
+
+func TestMatchExprLocatesSnippet(t *testing.T) {
+	q, err := BuildExprQuery("tag:work release", 0, 10, "", false)
+	if err != nil {
+		t.Fatalf("BuildExprQuery error: %v", err)
+	}
+	doc := Document{Path: "a.md", Content: "intro\nship the Release today", Tags: []string{"work"}}
+	result, ok := MatchExpr(doc, q)
+	if !ok {
+		t.Fatalf("expected match")
+	}
+	if result.Line != 2 || result.Match != "Release" || result.MatchType != "query" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
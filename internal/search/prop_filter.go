@@ -0,0 +1,268 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+)
+
+type PropOp string
+
+const (
+	PropEq       PropOp = "="
+	PropNe       PropOp = "!="
+	PropGt       PropOp = ">"
+	PropGte      PropOp = ">="
+	PropLt       PropOp = "<"
+	PropLte      PropOp = "<="
+	PropLike     PropOp = "~"
+	PropContains PropOp = "contains"
+	PropHas      PropOp = "has"
+	PropMissing  PropOp = "missing"
+)
+
+// PropFilter is a single frontmatter property test.
+type PropFilter struct {
+	Key   string `json:"key"`
+	Op    PropOp `json:"op"`
+	Value string `json:"value,omitempty"`
+}
+
+// Operators are tried longest first so "!=" is not read as "=".
+var propOperators = []PropOp{PropNe, PropGte, PropLte, PropEq, PropGt, PropLt, PropLike}
+
+var propDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParsePropFilter parses one of key=v, key!=v, key>n, key>=n, key<n,
+// key<=n, key~substr, "key contains v", has:key or missing:key.
+func ParsePropFilter(raw string) (PropFilter, error) {
+	clean := strings.TrimSpace(raw)
+	lower := strings.ToLower(clean)
+	for _, op := range []PropOp{PropHas, PropMissing} {
+		prefix := string(op) + ":"
+		if strings.HasPrefix(lower, prefix) {
+			key := strings.TrimSpace(clean[len(prefix):])
+			if key == "" {
+				return PropFilter{}, errs.New(errs.ExitValidation, "property key is required")
+			}
+			return PropFilter{Key: key, Op: op}, nil
+		}
+	}
+
+	if idx := strings.Index(lower, " contains "); idx > 0 {
+		return newPropFilter(clean[:idx], PropContains, clean[idx+len(" contains "):])
+	}
+	for i := 0; i < len(clean); i++ {
+		for _, op := range propOperators {
+			if strings.HasPrefix(clean[i:], string(op)) {
+				return newPropFilter(clean[:i], op, clean[i+len(op):])
+			}
+		}
+	}
+	return PropFilter{}, errs.NewDetailed(
+		errs.ExitValidation,
+		"invalid_prop_filter",
+		"Use key=value, key!=value, key>n, key<=date, key~text, 'key contains value', has:key or missing:key.",
+		"property filter must be key=value or use a supported operator",
+	)
+}
+
+func newPropFilter(key string, op PropOp, value string) (PropFilter, error) {
+	key = strings.TrimSpace(key)
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	if key == "" {
+		return PropFilter{}, errs.New(errs.ExitValidation, "property key is required")
+	}
+	if value == "" {
+		return PropFilter{}, errs.New(errs.ExitValidation, "property value is required")
+	}
+	return PropFilter{Key: key, Op: op, Value: value}, nil
+}
+
+func (f PropFilter) String() string {
+	switch f.Op {
+	case PropHas, PropMissing:
+		return string(f.Op) + ":" + f.Key
+	case PropContains:
+		return f.Key + " contains " + f.Value
+	default:
+		return f.Key + string(f.Op) + f.Value
+	}
+}
+
+// Match reports whether props satisfy the filter. Comparisons follow the
+// YAML type of the property: numbers compare numerically, dates
+// chronologically and everything else as case-insensitive text. A list
+// property matches when any of its items does.
+func (f PropFilter) Match(props map[string]any) bool {
+	value, ok := lookupProperty(props, f.Key)
+	switch f.Op {
+	case PropHas:
+		return ok
+	case PropMissing:
+		return !ok
+	case PropNe:
+		return !ok || !anyItem(value, func(item any) bool { return propEqual(item, f.Value) })
+	}
+	if !ok {
+		return false
+	}
+	switch f.Op {
+	case PropEq:
+		return anyItem(value, func(item any) bool { return propEqual(item, f.Value) })
+	case PropLike:
+		return anyItem(value, func(item any) bool {
+			return strings.Contains(strings.ToLower(propText(item)), strings.ToLower(f.Value))
+		})
+	case PropContains:
+		if _, isList := value.([]any); isList {
+			return anyItem(value, func(item any) bool { return propEqual(item, f.Value) })
+		}
+		return strings.Contains(strings.ToLower(propText(value)), strings.ToLower(f.Value))
+	default:
+		return anyItem(value, func(item any) bool {
+			cmp, ok := propCompare(item, f.Value)
+			if !ok {
+				return false
+			}
+			switch f.Op {
+			case PropGt:
+				return cmp > 0
+			case PropGte:
+				return cmp >= 0
+			case PropLt:
+				return cmp < 0
+			case PropLte:
+				return cmp <= 0
+			}
+			return false
+		})
+	}
+}
+
+// MatchPropFilters reports whether props satisfy every filter.
+func MatchPropFilters(filters []PropFilter, props map[string]any) bool {
+	for _, f := range filters {
+		if !f.Match(props) {
+			return false
+		}
+	}
+	return true
+}
+
+func anyItem(value any, fn func(any) bool) bool {
+	if list, ok := value.([]any); ok {
+		for _, item := range list {
+			if fn(item) {
+				return true
+			}
+		}
+		return false
+	}
+	return fn(value)
+}
+
+func propEqual(item any, want string) bool {
+	if n, ok := propNumber(item); ok {
+		if w, err := strconv.ParseFloat(want, 64); err == nil {
+			return n == w
+		}
+	}
+	if t, ok := propDate(item); ok {
+		if w, ok := parsePropDate(want); ok {
+			return t.Equal(w)
+		}
+	}
+	return strings.EqualFold(propText(item), want)
+}
+
+func propCompare(item any, want string) (int, bool) {
+	if n, ok := propNumber(item); ok {
+		w, err := strconv.ParseFloat(want, 64)
+		if err != nil {
+			return 0, false
+		}
+		switch {
+		case n < w:
+			return -1, true
+		case n > w:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+	if t, ok := propDate(item); ok {
+		w, ok := parsePropDate(want)
+		if !ok {
+			return 0, false
+		}
+		return t.Compare(w), true
+	}
+	if _, isBool := item.(bool); isBool || item == nil {
+		return 0, false
+	}
+	return strings.Compare(strings.ToLower(propText(item)), strings.ToLower(want)), true
+}
+
+func propNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func propDate(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		return parsePropDate(v)
+	default:
+		return time.Time{}, false
+	}
+}
+
+func parsePropDate(raw string) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	for _, layout := range propDateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func propText(value any) string {
+	if t, ok := value.(time.Time); ok {
+		if t.Equal(t.Truncate(24 * time.Hour)) {
+			return t.Format("2006-01-02")
+		}
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
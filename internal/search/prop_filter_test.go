@@ -0,0 +1,90 @@
+package search
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParsePropFilterOperators(t *testing.T) {
+	cases := map[string]PropFilter{
+		"status=done":             {Key: "status", Op: PropEq, Value: "done"},
+		"status != done":          {Key: "status", Op: PropNe, Value: "done"},
+		"priority>2":              {Key: "priority", Op: PropGt, Value: "2"},
+		"due<=2024-05-01":         {Key: "due", Op: PropLte, Value: "2024-05-01"},
+		"title~plan":              {Key: "title", Op: PropLike, Value: "plan"},
+		`tags contains "q3 goal"`: {Key: "tags", Op: PropContains, Value: "q3 goal"},
+		"has:owner":               {Key: "owner", Op: PropHas},
+		"missing:owner":           {Key: "owner", Op: PropMissing},
+	}
+	for raw, want := range cases {
+		got, err := ParsePropFilter(raw)
+		if err != nil {
+			t.Fatalf("ParsePropFilter(%q) error: %v", raw, err)
+		}
+		if got != want {
+			t.Fatalf("ParsePropFilter(%q) = %+v, want %+v", raw, got, want)
+		}
+	}
+	for _, raw := range []string{"broken", "=value", "key=", "has:"} {
+		if _, err := ParsePropFilter(raw); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestPropFilterMatchTyped(t *testing.T) {
+	props := map[string]any{
+		"status":   "Active",
+		"priority": 3,
+		"effort":   json.Number("2.5"),
+		"due":      time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		"updated":  "2024-06-10T08:00:00Z",
+		"code":     "10",
+		"tags":     []any{"work", "q3"},
+	}
+	cases := map[string]bool{
+		"status=active":       true,
+		"status!=active":      false,
+		"owner!=bob":          true,
+		"priority>2":          true,
+		"priority>=3":         true,
+		"priority<3":          false,
+		"priority=3.0":        true,
+		"effort<=2.5":         true,
+		"effort>10":           false,
+		"due<=2024-05-01":     true,
+		"due>2024-04-30":      true,
+		"due=2024-05-01":      true,
+		"updated>2024-06-01":  true,
+		"updated<2024-06-10":  false,
+		"code<9":              true, // quoted in YAML, so compared as text
+		"status~tiv":          true,
+		"tags contains q3":    true,
+		"tags contains q":     false,
+		"status contains act": true,
+		"has:status":          true,
+		"missing:owner":       true,
+		"missing:status":      false,
+		"priority>high":       false,
+		"tags=work":           true,
+		"tags!=work":          false,
+	}
+	for raw, want := range cases {
+		f, err := ParsePropFilter(raw)
+		if err != nil {
+			t.Fatalf("ParsePropFilter(%q) error: %v", raw, err)
+		}
+		if got := f.Match(props); got != want {
+			t.Fatalf("%q Match = %v, want %v", raw, got, want)
+		}
+	}
+
+	filters := []PropFilter{
+		{Key: "status", Op: PropEq, Value: "active"},
+		{Key: "priority", Op: PropGt, Value: "5"},
+	}
+	if MatchPropFilters(filters, props) {
+		t.Fatalf("expected combined filters to require every match")
+	}
+}
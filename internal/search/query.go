@@ -1,7 +1,6 @@
 package search
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/nightisyang/obsidian-cli/internal/errs"
@@ -13,33 +12,37 @@ const (
 	QueryText QueryType = "text"
 	QueryTag  QueryType = "tag"
 	QueryProp QueryType = "prop"
+	QueryExpr QueryType = "expr"
 )
 
 type Query struct {
-	Type          QueryType `json:"type"`
-	Text          string    `json:"text,omitempty"`
-	Tag           string    `json:"tag,omitempty"`
-	PropKey       string    `json:"prop_key,omitempty"`
-	PropValue     string    `json:"prop_value,omitempty"`
-	Limit         int       `json:"limit,omitempty"`
-	Context       int       `json:"context,omitempty"`
-	Path          string    `json:"path,omitempty"`
-	CaseSensitive bool      `json:"case_sensitive,omitempty"`
+	Type          QueryType    `json:"type"`
+	Text          string       `json:"text,omitempty"`
+	Tag           string       `json:"tag,omitempty"`
+	Props         []PropFilter `json:"props,omitempty"`
+	Limit         int          `json:"limit,omitempty"`
+	Context       int          `json:"context,omitempty"`
+	Path          string       `json:"path,omitempty"`
+	CaseSensitive bool         `json:"case_sensitive,omitempty"`
+	Ranked        bool         `json:"ranked,omitempty"`
+	Raw           string       `json:"raw,omitempty"`
+	Expr          *Expr        `json:"expr,omitempty"`
 }
 
 type SearchResult struct {
-	Path      string `json:"path"`
-	Line      int    `json:"line,omitempty"`
-	Column    int    `json:"column,omitempty"`
-	Match     string `json:"match"`
-	Snippet   string `json:"snippet"`
-	MatchType string `json:"match_type"`
+	Path      string  `json:"path"`
+	Line      int     `json:"line,omitempty"`
+	Column    int     `json:"column,omitempty"`
+	Match     string  `json:"match"`
+	Snippet   string  `json:"snippet"`
+	MatchType string  `json:"match_type"`
+	Score     float64 `json:"score,omitempty"`
 }
 
-func BuildQuery(text, tag, prop string, limit, context int, path string, caseSensitive bool) (Query, error) {
+func BuildQuery(text, tag string, props []string, limit, context int, path string, caseSensitive bool) (Query, error) {
 	hasText := strings.TrimSpace(text) != ""
 	hasTag := strings.TrimSpace(tag) != ""
-	hasProp := strings.TrimSpace(prop) != ""
+	hasProp := len(props) > 0
 	count := 0
 	if hasText {
 		count++
@@ -73,30 +76,31 @@ func BuildQuery(text, tag, prop string, limit, context int, path string, caseSen
 		return q, nil
 	}
 
-	key, value, err := ParsePropFilter(prop)
-	if err != nil {
-		return Query{}, err
-	}
 	q.Type = QueryProp
-	q.PropKey = key
-	q.PropValue = value
+	for _, raw := range props {
+		filter, err := ParsePropFilter(raw)
+		if err != nil {
+			return Query{}, err
+		}
+		q.Props = append(q.Props, filter)
+	}
 	return q, nil
 }
 
-func ParsePropFilter(raw string) (string, string, error) {
-	parts := strings.SplitN(strings.TrimSpace(raw), "=", 2)
-	if len(parts) != 2 {
-		return "", "", errs.New(errs.ExitValidation, "property filter must be key=value")
+// BuildExprQuery compiles raw Obsidian search syntax into an expression query.
+func BuildExprQuery(raw string, limit, context int, path string, caseSensitive bool) (Query, error) {
+	expr, err := ParseExpr(raw)
+	if err != nil {
+		return Query{}, err
 	}
-	key := strings.TrimSpace(parts[0])
-	value := strings.TrimSpace(parts[1])
-	if key == "" {
-		return "", "", errs.New(errs.ExitValidation, "property key is required")
+	q := Query{Type: QueryExpr, Raw: strings.TrimSpace(raw), Expr: expr, Limit: limit, Context: context, Path: strings.TrimSpace(path), CaseSensitive: caseSensitive}
+	if q.Limit <= 0 {
+		q.Limit = 20
 	}
-	if value == "" {
-		return "", "", errs.New(errs.ExitValidation, "property value is required")
+	if q.Context < 0 {
+		q.Context = 80
 	}
-	return key, value, nil
+	return q, nil
 }
 
 func (q Query) String() string {
@@ -106,7 +110,13 @@ func (q Query) String() string {
 	case QueryTag:
 		return "#" + q.Tag
 	case QueryProp:
-		return fmt.Sprintf("%s=%s", q.PropKey, q.PropValue)
+		parts := make([]string, 0, len(q.Props))
+		for _, f := range q.Props {
+			parts = append(parts, f.String())
+		}
+		return strings.Join(parts, " AND ")
+	case QueryExpr:
+		return q.Raw
 	default:
 		return ""
 	}
@@ -3,7 +3,7 @@ package search
 import "testing"
 
 func TestBuildQueryModes(t *testing.T) {
-	textQ, err := BuildQuery("hello", "", "", 10, 50, "", false)
+	textQ, err := BuildQuery("hello", "", nil, 10, 50, "", false)
 	if err != nil {
 		t.Fatalf("BuildQuery text error: %v", err)
 	}
@@ -11,7 +11,7 @@ func TestBuildQueryModes(t *testing.T) {
 		t.Fatalf("unexpected text query: %+v", textQ)
 	}
 
-	tagQ, err := BuildQuery("", "#project", "", 20, 80, "notes", false)
+	tagQ, err := BuildQuery("", "#project", nil, 20, 80, "notes", false)
 	if err != nil {
 		t.Fatalf("BuildQuery tag error: %v", err)
 	}
@@ -19,20 +19,20 @@ func TestBuildQueryModes(t *testing.T) {
 		t.Fatalf("unexpected tag query: %+v", tagQ)
 	}
 
-	propQ, err := BuildQuery("", "", "status=done", 5, 80, "", true)
+	propQ, err := BuildQuery("", "", []string{"status=done"}, 5, 80, "", true)
 	if err != nil {
 		t.Fatalf("BuildQuery prop error: %v", err)
 	}
-	if propQ.Type != QueryProp || propQ.PropKey != "status" || propQ.PropValue != "done" {
+	if propQ.Type != QueryProp || len(propQ.Props) != 1 || propQ.Props[0] != (PropFilter{Key: "status", Op: PropEq, Value: "done"}) {
 		t.Fatalf("unexpected prop query: %+v", propQ)
 	}
 }
 
 func TestBuildQueryValidation(t *testing.T) {
-	if _, err := BuildQuery("a", "b", "", 0, 0, "", false); err == nil {
+	if _, err := BuildQuery("a", "b", nil, 0, 0, "", false); err == nil {
 		t.Fatalf("expected validation error for multiple query modes")
 	}
-	if _, _, err := ParsePropFilter("broken"); err == nil {
+	if _, err := ParsePropFilter("broken"); err == nil {
 		t.Fatalf("expected ParsePropFilter error")
 	}
 }
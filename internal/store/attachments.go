@@ -0,0 +1,154 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/index"
+	"github.com/nightisyang/obsidian-cli/internal/note"
+)
+
+// Attachment is an image, audio, video or PDF file in the vault, with the
+// notes and canvases that reference it.
+type Attachment struct {
+	Path       string   `json:"path"`
+	Size       int64    `json:"size"`
+	EmbeddedBy []string `json:"embedded_by"`
+	LinkedBy   []string `json:"linked_by"`
+}
+
+// Used reports whether anything embeds or links to the attachment.
+func (a Attachment) Used() bool {
+	return len(a.EmbeddedBy) > 0 || len(a.LinkedBy) > 0
+}
+
+// attachmentExts are the file types Obsidian opens as attachments.
+var attachmentExts = map[string]struct{}{
+	".avif": {}, ".bmp": {}, ".gif": {}, ".jpeg": {}, ".jpg": {}, ".png": {}, ".svg": {}, ".webp": {},
+	".3gp": {}, ".flac": {}, ".m4a": {}, ".mp3": {}, ".ogg": {}, ".wav": {}, ".webm": {},
+	".mkv": {}, ".mov": {}, ".mp4": {}, ".ogv": {},
+	".pdf": {},
+}
+
+// IsAttachment reports whether a vault path or link target names an
+// image, audio, video or PDF attachment rather than a note, canvas, base
+// or other file.
+func IsAttachment(rel string) bool {
+	_, ok := attachmentExts[strings.ToLower(path.Ext(rel))]
+	return ok
+}
+
+// AttachmentFolder returns the attachmentFolderPath setting from
+// .obsidian/app.json. "/" (the default) is the vault root and values
+// starting with "./" are relative to each note's folder.
+func AttachmentFolder(vaultRoot string) string {
+	var cfg struct {
+		AttachmentFolderPath string `json:"attachmentFolderPath"`
+	}
+	payload, err := os.ReadFile(filepath.Join(vaultRoot, ".obsidian", "app.json"))
+	if err == nil {
+		_ = json.Unmarshal(payload, &cfg)
+	}
+	folder := strings.TrimSpace(strings.ReplaceAll(cfg.AttachmentFolderPath, "\\", "/"))
+	if folder == "" {
+		return "/"
+	}
+	return folder
+}
+
+// AttachmentScope returns the folder holding every attachment for the
+// given setting, or "" when attachments can live anywhere in the vault.
+func AttachmentScope(setting string) string {
+	if setting == "." || strings.HasPrefix(setting, "./") {
+		return ""
+	}
+	return strings.Trim(setting, "/")
+}
+
+// Attachments lists the attachments under prefix with the notes that embed
+// or link to them and the canvases that reference them.
+func (s *Store) Attachments(snap Snapshot, r *index.Resolver, prefix string) ([]Attachment, error) {
+	files, err := index.ListVaultFiles(s.vaultRoot)
+	if err != nil {
+		return nil, errs.Wrap(errs.ExitGeneric, "failed to scan vault files", err)
+	}
+	byPath := map[string]*Attachment{}
+	out := []*Attachment{}
+	for _, abs := range files {
+		rel, err := filepath.Rel(s.vaultRoot, abs)
+		if err != nil {
+			return nil, errs.Wrap(errs.ExitGeneric, "failed to scan vault files", err)
+		}
+		rel = filepath.ToSlash(rel)
+		if !IsAttachment(rel) || !hasPathPrefix(rel, prefix) || s.inIndexDir(abs) {
+			continue
+		}
+		a := &Attachment{Path: rel, EmbeddedBy: []string{}, LinkedBy: []string{}}
+		if info, statErr := os.Stat(abs); statErr == nil {
+			a.Size = info.Size()
+		}
+		byPath[rel] = a
+		out = append(out, a)
+	}
+
+	for _, rel := range snap.Paths() {
+		for _, link := range r.ResolveLinks(rel, append([]note.Link(nil), snap.Files[rel].Refs...)) {
+			a, ok := byPath[link.Path]
+			if !ok {
+				continue
+			}
+			if link.Kind == note.LinkEmbed {
+				a.EmbeddedBy = appendSource(a.EmbeddedBy, rel)
+			} else {
+				a.LinkedBy = appendSource(a.LinkedBy, rel)
+			}
+		}
+	}
+	for _, rel := range snap.CanvasPaths() {
+		for _, key := range snap.Canvases[rel].Links {
+			if a, ok := byPath[r.Resolve(rel, key).Path]; ok {
+				a.LinkedBy = appendSource(a.LinkedBy, rel)
+			}
+		}
+	}
+
+	result := make([]Attachment, 0, len(out))
+	for _, a := range out {
+		sort.Strings(a.LinkedBy)
+		result = append(result, *a)
+	}
+	return result, nil
+}
+
+// MissingAttachments reports embeds and links from notes and canvases under
+// prefix whose attachment target does not exist.
+func (s Snapshot) MissingAttachments(r *index.Resolver, prefix string) []UnresolvedTarget {
+	out := []UnresolvedTarget{}
+	for _, target := range s.Unresolved(r, prefix) {
+		if IsAttachment(target.Target) {
+			out = append(out, target)
+		}
+	}
+	return out
+}
+
+// inIndexDir reports whether abs lies inside the store's own folder,
+// which is never part of the vault content.
+func (s *Store) inIndexDir(abs string) bool {
+	rel, err := filepath.Rel(s.dir, abs)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// appendSource adds rel unless it was the last source added; sources are
+// visited in order so this is enough to de-duplicate.
+func appendSource(sources []string, rel string) []string {
+	if len(sources) > 0 && sources[len(sources)-1] == rel {
+		return sources
+	}
+	return append(sources, rel)
+}
@@ -0,0 +1,271 @@
+package store
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/index"
+	"github.com/nightisyang/obsidian-cli/internal/note"
+)
+
+// LinkOccurrence locates one link in a source file. Canvas links carry no
+// line number.
+type LinkOccurrence struct {
+	Source string `json:"source"`
+	Line   int    `json:"line,omitempty"`
+	Kind   string `json:"kind"`
+	Raw    string `json:"raw,omitempty"`
+}
+
+// UnresolvedTarget groups the links whose target matches no vault file.
+type UnresolvedTarget struct {
+	Target      string           `json:"target"`
+	Count       int              `json:"count"`
+	Occurrences []LinkOccurrence `json:"occurrences"`
+}
+
+// BrokenAnchor is a link whose note exists but whose #heading or #^block
+// subpath does not.
+type BrokenAnchor struct {
+	LinkOccurrence
+	Path    string `json:"path"`
+	Subpath string `json:"subpath"`
+}
+
+// Alias maps one alias to the notes declaring it. Conflict is set when
+// several notes share the alias or another note's file name shadows it.
+type Alias struct {
+	Alias    string   `json:"alias"`
+	Paths    []string `json:"paths"`
+	Conflict bool     `json:"conflict"`
+	Shadowed string   `json:"shadowed_by,omitempty"`
+}
+
+// Resolver resolves links against snap plus every attachment in the vault,
+// so embeds of images and PDFs count as resolved.
+func (s *Store) Resolver(snap Snapshot) (*index.Resolver, error) {
+	r := snap.Resolver()
+	files, err := index.ListVaultFiles(s.vaultRoot)
+	if err != nil {
+		return nil, errs.Wrap(errs.ExitGeneric, "failed to scan vault files", err)
+	}
+	for _, abs := range files {
+		rel, err := filepath.Rel(s.vaultRoot, abs)
+		if err != nil {
+			return nil, errs.Wrap(errs.ExitGeneric, "failed to scan vault files", err)
+		}
+		r.Add(filepath.ToSlash(rel))
+	}
+	return r, nil
+}
+
+// BacklinkOccurrences returns the notes and canvases whose links resolve to
+// relPath, each with its linking occurrences, sorted by source.
+func (s Snapshot) BacklinkOccurrences(r *index.Resolver, relPath string) []index.Backlink {
+	out := []index.Backlink{}
+	for _, rel := range s.Paths() {
+		links := []note.Link{}
+		for _, link := range r.ResolveLinks(rel, append([]note.Link(nil), s.Files[rel].Refs...)) {
+			if link.Path == relPath && rel != relPath {
+				links = append(links, link)
+			}
+		}
+		if len(links) > 0 {
+			out = append(out, index.Backlink{Source: rel, Links: links})
+		}
+	}
+	for _, rel := range s.CanvasPaths() {
+		for _, key := range s.Canvases[rel].Links {
+			if r.Resolve(rel, key).Path == relPath {
+				out = append(out, index.Backlink{Source: rel, Links: []note.Link{}})
+				break
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Source < out[j].Source })
+	return out
+}
+
+// Unresolved reports links from notes and canvases under prefix that match
+// no file, grouped by normalized target. Ambiguous links are not included.
+func (s Snapshot) Unresolved(r *index.Resolver, prefix string) []UnresolvedTarget {
+	groups := map[string]*UnresolvedTarget{}
+	add := func(target string, occ LinkOccurrence) {
+		g, ok := groups[target]
+		if !ok {
+			g = &UnresolvedTarget{Target: target, Occurrences: []LinkOccurrence{}}
+			groups[target] = g
+		}
+		g.Count++
+		g.Occurrences = append(g.Occurrences, occ)
+	}
+	for _, rel := range s.Paths() {
+		if !hasPathPrefix(rel, prefix) {
+			continue
+		}
+		for _, link := range s.Files[rel].Refs {
+			key := link.Key(rel)
+			res := r.Resolve(rel, key)
+			if res.Path != "" || res.Ambiguous() {
+				continue
+			}
+			add(key, LinkOccurrence{Source: rel, Line: link.Line, Kind: link.Kind, Raw: link.Raw})
+		}
+	}
+	for _, rel := range s.CanvasPaths() {
+		if !hasPathPrefix(rel, prefix) {
+			continue
+		}
+		for _, key := range s.Canvases[rel].Links {
+			if res := r.Resolve(rel, key); res.Path == "" && !res.Ambiguous() {
+				add(key, LinkOccurrence{Source: rel, Kind: "canvas"})
+			}
+		}
+	}
+	out := make([]UnresolvedTarget, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Target < out[j].Target
+	})
+	return out
+}
+
+// CheckAnchors flags resolved links to indexed notes whose subpath is
+// missing from the target note.
+func (s Snapshot) CheckAnchors(links []note.Link) []note.Link {
+	for i, link := range links {
+		entry, ok := s.Files[link.Path]
+		links[i].BrokenAnchor = ok && !link.AnchorExists(entry.Headings, entry.Blocks)
+	}
+	return links
+}
+
+// BrokenAnchors reports links from notes under prefix whose heading or
+// block subpath no longer exists in the target note.
+func (s Snapshot) BrokenAnchors(r *index.Resolver, prefix string) []BrokenAnchor {
+	out := []BrokenAnchor{}
+	for _, rel := range s.Paths() {
+		if !hasPathPrefix(rel, prefix) {
+			continue
+		}
+		links := s.CheckAnchors(r.ResolveLinks(rel, append([]note.Link(nil), s.Files[rel].Refs...)))
+		for _, link := range links {
+			if !link.BrokenAnchor {
+				continue
+			}
+			out = append(out, BrokenAnchor{
+				LinkOccurrence: LinkOccurrence{Source: rel, Line: link.Line, Kind: link.Kind, Raw: link.Raw},
+				Path:           link.Path,
+				Subpath:        link.Subpath(),
+			})
+		}
+	}
+	return out
+}
+
+// Aliases returns every frontmatter alias in the vault, sorted
+// case-insensitively.
+func (s Snapshot) Aliases() []Alias {
+	byKey := map[string]*Alias{}
+	for _, rel := range s.Paths() {
+		for _, alias := range s.Files[rel].Aliases {
+			key := strings.ToLower(strings.TrimSpace(alias))
+			if key == "" {
+				continue
+			}
+			a, ok := byKey[key]
+			if !ok {
+				a = &Alias{Alias: alias, Paths: []string{}}
+				byKey[key] = a
+			}
+			if len(a.Paths) == 0 || a.Paths[len(a.Paths)-1] != rel {
+				a.Paths = append(a.Paths, rel)
+			}
+		}
+	}
+	files := index.NewResolver(s.Paths())
+	out := make([]Alias, 0, len(byKey))
+	for key, a := range byKey {
+		if res := files.Resolve("", key); res.Path != "" && (len(a.Paths) != 1 || res.Path != a.Paths[0]) {
+			a.Shadowed = res.Path
+		}
+		a.Conflict = len(a.Paths) > 1 || a.Shadowed != ""
+		out = append(out, *a)
+	}
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i].Alias) < strings.ToLower(out[j].Alias) })
+	return out
+}
+
+// Lookup returns the notes whose file name, frontmatter title or alias
+// equals name, ignoring case.
+func (s Snapshot) Lookup(name string) []string {
+	want := strings.TrimSpace(name)
+	out := []string{}
+	for _, rel := range s.Paths() {
+		entry := s.Files[rel]
+		names := append([]string{entry.Title}, entry.Aliases...)
+		if title, ok := entry.Properties["title"].(string); ok {
+			names = append(names, title)
+		}
+		for _, candidate := range names {
+			if strings.EqualFold(strings.TrimSpace(candidate), want) {
+				out = append(out, rel)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Orphans lists notes under prefix that no other note or canvas links to.
+func (s Snapshot) Orphans(r *index.Resolver, prefix string) []string {
+	linked := map[string]struct{}{}
+	for rel, targets := range s.LinkIndex().SourceToTarget {
+		for _, key := range targets {
+			if target := r.Resolve(rel, key).Path; target != "" && target != rel {
+				linked[target] = struct{}{}
+			}
+		}
+	}
+	out := []string{}
+	for _, rel := range s.Paths() {
+		if _, ok := linked[rel]; !ok && hasPathPrefix(rel, prefix) {
+			out = append(out, rel)
+		}
+	}
+	return out
+}
+
+// DeadEnds lists notes under prefix with no outgoing link to another
+// existing file.
+func (s Snapshot) DeadEnds(r *index.Resolver, prefix string) []string {
+	out := []string{}
+	for _, rel := range s.Paths() {
+		if !hasPathPrefix(rel, prefix) {
+			continue
+		}
+		dead := true
+		for _, key := range s.Files[rel].Links {
+			if target := r.Resolve(rel, key).Path; target != "" && target != rel {
+				dead = false
+				break
+			}
+		}
+		if dead {
+			out = append(out, rel)
+		}
+	}
+	return out
+}
+
+func hasPathPrefix(rel, prefix string) bool {
+	clean := strings.Trim(strings.ReplaceAll(strings.TrimSpace(prefix), "\\", "/"), "/")
+	return clean == "" || rel == clean || strings.HasPrefix(rel, clean+"/")
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/index"
+	"github.com/nightisyang/obsidian-cli/internal/tasks"
+)
+
+// Paths returns the indexed note paths in sorted order.
+func (s Snapshot) Paths() []string {
+	out := make([]string, 0, len(s.Files))
+	for rel := range s.Files {
+		out = append(out, rel)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// CanvasPaths returns the indexed canvas paths in sorted order.
+func (s Snapshot) CanvasPaths() []string {
+	out := make([]string, 0, len(s.Canvases))
+	for rel := range s.Canvases {
+		out = append(out, rel)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// LinkIndex projects the snapshot onto the backlink index shape. Canvases
+// count as link sources so they show up in backlinks.
+func (s Snapshot) LinkIndex() index.BacklinkIndex {
+	idx := index.BacklinkIndex{
+		BuiltAt:        s.RefreshedAt,
+		SourceToTarget: make(map[string][]string, len(s.Files)+len(s.Canvases)),
+		TargetToSource: map[string][]string{},
+	}
+	for _, rel := range append(s.Paths(), s.CanvasPaths()...) {
+		entry, ok := s.Files[rel]
+		if !ok {
+			entry = s.Canvases[rel]
+		}
+		idx.SourceToTarget[rel] = entry.Links
+		for _, target := range entry.Links {
+			idx.TargetToSource[target] = append(idx.TargetToSource[target], rel)
+		}
+		if mtime := time.Unix(0, entry.MTime).UTC(); mtime.After(idx.FileMTimeMax) {
+			idx.FileMTimeMax = mtime
+		}
+	}
+	return idx
+}
+
+// Resolver resolves link targets against the indexed notes, their aliases
+// and canvases.
+func (s Snapshot) Resolver() *index.Resolver {
+	r := index.NewResolver(append(s.Paths(), s.CanvasPaths()...))
+	for rel, entry := range s.Files {
+		for _, alias := range entry.Aliases {
+			r.AddAlias(alias, rel)
+		}
+	}
+	return r
+}
+
+// Backlinks lists the notes and canvases linking to relPath.
+func (s Snapshot) Backlinks(relPath string) []string {
+	r := s.Resolver()
+	r.Add(relPath)
+	out := []string{}
+	for _, backlink := range s.BacklinkOccurrences(r, relPath) {
+		out = append(out, backlink.Source)
+	}
+	return out
+}
+
+func (s Snapshot) TagCounts() map[string]int {
+	counts := map[string]int{}
+	for _, entry := range s.Files {
+		for _, tag := range entry.Tags {
+			counts[tag]++
+		}
+	}
+	return counts
+}
+
+// TaggedPaths lists notes carrying tag, sorted by path.
+func (s Snapshot) TaggedPaths(tag string) []string {
+	norm := index.NormalizeTag(tag)
+	out := []string{}
+	if norm == "" {
+		return out
+	}
+	for _, rel := range s.Paths() {
+		for _, t := range s.Files[rel].Tags {
+			if t == norm {
+				out = append(out, rel)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Tasks applies the same filters as tasks.List to the indexed tasks.
+// opts.Path, when set, must already be a normalized vault-relative path.
+func (s Snapshot) Tasks(opts tasks.ListOptions) []tasks.Task {
+	paths := s.Paths()
+	if opts.Path != "" {
+		paths = []string{opts.Path}
+	}
+	out := []tasks.Task{}
+	for _, rel := range paths {
+		for _, t := range s.Files[rel].Tasks {
+			if !tasks.Matches(t, opts) {
+				continue
+			}
+			out = append(out, t)
+			if opts.Limit > 0 && len(out) >= opts.Limit {
+				return out
+			}
+		}
+	}
+	tasks.Sort(out)
+	return out
+}
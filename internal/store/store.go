@@ -0,0 +1,362 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/canvas"
+	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/frontmatter"
+	"github.com/nightisyang/obsidian-cli/internal/index"
+	"github.com/nightisyang/obsidian-cli/internal/note"
+	"github.com/nightisyang/obsidian-cli/internal/tasks"
+)
+
+// FileName is the snapshot file written inside the configured index dir.
+const FileName = "vault-index.json"
+
+const formatVersion = 7
+
+// Entry is everything the index remembers about a single markdown file.
+type Entry struct {
+	Path       string         `json:"path"`
+	MTime      int64          `json:"mtime"`
+	Size       int64          `json:"size"`
+	Title      string         `json:"title"`
+	Aliases    []string       `json:"aliases,omitempty"`
+	Links      []string       `json:"links"`
+	Refs       []note.Link    `json:"refs,omitempty"`
+	Tags       []string       `json:"tags"`
+	Properties map[string]any `json:"properties"`
+	Fields     map[string]any `json:"fields,omitempty"`
+	Headings   []note.Heading `json:"headings"`
+	Blocks     []string       `json:"blocks"`
+	Tasks      []tasks.Task   `json:"tasks"`
+}
+
+// Snapshot is the persisted index. Canvases hold .canvas files, whose
+// entries only carry path, mtime, size and links.
+type Snapshot struct {
+	Version     int              `json:"version"`
+	BuiltAt     time.Time        `json:"built_at"`
+	RefreshedAt time.Time        `json:"refreshed_at"`
+	Files       map[string]Entry `json:"files"`
+	Canvases    map[string]Entry `json:"canvases"`
+}
+
+type RefreshStats struct {
+	Added     int `json:"added"`
+	Updated   int `json:"updated"`
+	Removed   int `json:"removed"`
+	Unchanged int `json:"unchanged"`
+}
+
+func (s RefreshStats) Changed() int {
+	return s.Added + s.Updated + s.Removed
+}
+
+type Status struct {
+	Path        string       `json:"path"`
+	Exists      bool         `json:"exists"`
+	Version     int          `json:"version,omitempty"`
+	BuiltAt     string       `json:"built_at,omitempty"`
+	RefreshedAt string       `json:"refreshed_at,omitempty"`
+	Files       int          `json:"files"`
+	SizeBytes   int64        `json:"size_bytes"`
+	Stale       bool         `json:"stale"`
+	Pending     RefreshStats `json:"pending"`
+}
+
+// Store persists a Snapshot of the vault under IndexDir.
+type Store struct {
+	vaultRoot string
+	dir       string
+}
+
+// Open returns the store for a vault; a relative indexDir is resolved
+// against the vault root.
+func Open(vaultRoot, indexDir string) *Store {
+	dir := strings.TrimSpace(indexDir)
+	if dir == "" {
+		dir = ".obsidian-cli-index"
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(vaultRoot, dir)
+	}
+	return &Store{vaultRoot: vaultRoot, dir: dir}
+}
+
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+func (s *Store) Path() string {
+	return filepath.Join(s.dir, FileName)
+}
+
+// Load reads the persisted snapshot. A missing, unreadable or outdated
+// snapshot yields an empty one so the next refresh rebuilds it.
+func (s *Store) Load() (Snapshot, bool, error) {
+	payload, err := os.ReadFile(s.Path())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return emptySnapshot(), false, nil
+		}
+		return Snapshot{}, false, errs.Wrap(errs.ExitGeneric, "failed to read vault index", err)
+	}
+	var snap Snapshot
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(&snap); err != nil || snap.Version != formatVersion {
+		return emptySnapshot(), false, nil
+	}
+	if snap.Files == nil {
+		snap.Files = map[string]Entry{}
+	}
+	if snap.Canvases == nil {
+		snap.Canvases = map[string]Entry{}
+	}
+	return snap, true, nil
+}
+
+// Refresh brings the snapshot up to date, re-parsing only files whose
+// mtime or size changed, and persists it when anything moved.
+func (s *Store) Refresh() (Snapshot, RefreshStats, error) {
+	snap, persisted, err := s.Load()
+	if err != nil {
+		return Snapshot{}, RefreshStats{}, err
+	}
+	stats, err := s.update(&snap)
+	if err != nil {
+		return Snapshot{}, RefreshStats{}, err
+	}
+	if persisted && stats.Changed() == 0 {
+		return snap, stats, nil
+	}
+	if err := s.save(&snap); err != nil {
+		return Snapshot{}, RefreshStats{}, err
+	}
+	return snap, stats, nil
+}
+
+// Rebuild discards the persisted snapshot and indexes every file again.
+func (s *Store) Rebuild() (Snapshot, RefreshStats, error) {
+	snap := emptySnapshot()
+	stats, err := s.update(&snap)
+	if err != nil {
+		return Snapshot{}, RefreshStats{}, err
+	}
+	if err := s.save(&snap); err != nil {
+		return Snapshot{}, RefreshStats{}, err
+	}
+	return snap, stats, nil
+}
+
+// Clear removes the persisted snapshot. It reports whether one existed.
+func (s *Store) Clear() (bool, error) {
+	err := os.Remove(s.Path())
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return false, errs.Wrap(errs.ExitGeneric, "failed to clear vault index", err)
+}
+
+// Status describes the persisted snapshot and how far it lags the vault,
+// without updating it.
+func (s *Store) Status() (Status, error) {
+	snap, persisted, err := s.Load()
+	if err != nil {
+		return Status{}, err
+	}
+	status := Status{Path: s.Path(), Exists: persisted}
+	if persisted {
+		status.Version = snap.Version
+		status.BuiltAt = snap.BuiltAt.UTC().Format(time.RFC3339)
+		status.RefreshedAt = snap.RefreshedAt.UTC().Format(time.RFC3339)
+		status.Files = len(snap.Files)
+		if info, statErr := os.Stat(s.Path()); statErr == nil {
+			status.SizeBytes = info.Size()
+		}
+	}
+	pending, err := s.diff(snap, nil)
+	if err != nil {
+		return Status{}, err
+	}
+	status.Pending = pending
+	status.Stale = !persisted || pending.Changed() > 0
+	return status, nil
+}
+
+func (s *Store) update(snap *Snapshot) (RefreshStats, error) {
+	return s.diff(*snap, func(rel string, info os.FileInfo) error {
+		if strings.HasSuffix(strings.ToLower(rel), canvas.Ext) {
+			entry, err := buildCanvasEntry(s.vaultRoot, rel, info)
+			if err != nil {
+				return err
+			}
+			snap.Canvases[rel] = entry
+			return nil
+		}
+		entry, err := buildEntry(s.vaultRoot, rel, info)
+		if err != nil {
+			return err
+		}
+		snap.Files[rel] = entry
+		return nil
+	})
+}
+
+// diff compares the vault with snap, calling reindex for each added or
+// changed file and dropping entries for removed ones when reindex is set.
+func (s *Store) diff(snap Snapshot, reindex func(rel string, info os.FileInfo) error) (RefreshStats, error) {
+	notes, err := index.ListMarkdownFiles(s.vaultRoot)
+	if err != nil {
+		return RefreshStats{}, err
+	}
+	canvases, err := index.ListCanvasFiles(s.vaultRoot)
+	if err != nil {
+		return RefreshStats{}, err
+	}
+	stats := RefreshStats{}
+	if err := s.diffFiles(notes, snap.Files, &stats, reindex); err != nil {
+		return RefreshStats{}, err
+	}
+	if err := s.diffFiles(canvases, snap.Canvases, &stats, reindex); err != nil {
+		return RefreshStats{}, err
+	}
+	return stats, nil
+}
+
+func (s *Store) diffFiles(files []string, entries map[string]Entry, stats *RefreshStats, reindex func(rel string, info os.FileInfo) error) error {
+	seen := make(map[string]struct{}, len(files))
+	for _, abs := range files {
+		info, err := os.Stat(abs)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.vaultRoot, abs)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		seen[rel] = struct{}{}
+
+		prev, ok := entries[rel]
+		switch {
+		case ok && prev.MTime == info.ModTime().UnixNano() && prev.Size == info.Size():
+			stats.Unchanged++
+			continue
+		case ok:
+			stats.Updated++
+		default:
+			stats.Added++
+		}
+		if reindex != nil {
+			if err := reindex(rel, info); err != nil {
+				return err
+			}
+		}
+	}
+	for rel := range entries {
+		if _, ok := seen[rel]; ok {
+			continue
+		}
+		stats.Removed++
+		if reindex != nil {
+			delete(entries, rel)
+		}
+	}
+	return nil
+}
+
+func (s *Store) save(snap *Snapshot) error {
+	stamp := time.Now().UTC()
+	if snap.BuiltAt.IsZero() {
+		snap.BuiltAt = stamp
+	}
+	snap.RefreshedAt = stamp
+
+	payload, err := json.Marshal(snap)
+	if err != nil {
+		return errs.Wrap(errs.ExitGeneric, "failed to encode vault index", err)
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return errs.Wrap(errs.ExitGeneric, "failed to create index dir", err)
+	}
+	tmp := s.Path() + ".tmp"
+	if err := os.WriteFile(tmp, payload, 0o644); err != nil {
+		return errs.Wrap(errs.ExitGeneric, "failed to write vault index", err)
+	}
+	if err := os.Rename(tmp, s.Path()); err != nil {
+		return errs.Wrap(errs.ExitGeneric, "failed to write vault index", err)
+	}
+	return nil
+}
+
+func buildEntry(vaultRoot, rel string, info os.FileInfo) (Entry, error) {
+	raw, err := os.ReadFile(filepath.Join(vaultRoot, filepath.FromSlash(rel)))
+	if err != nil {
+		return Entry{}, err
+	}
+	n, err := note.Parse(rel, string(raw))
+	if err != nil {
+		return Entry{}, err
+	}
+	return Entry{
+		Path:       rel,
+		MTime:      info.ModTime().UnixNano(),
+		Size:       info.Size(),
+		Title:      n.Title,
+		Aliases:    n.Frontmatter.Aliases,
+		Links:      index.LinkTargets(rel, n.Body),
+		Refs:       note.ParseLinks(n.Body),
+		Tags:       index.NoteTags(n),
+		Properties: jsonProperties(frontmatter.FrontmatterToMap(n.Frontmatter)),
+		Fields:     note.InlineFields(n.Body),
+		Headings:   note.Headings(n.Body),
+		Blocks:     note.BlockIDs(n.Body),
+		Tasks:      tasks.FromContent(rel, string(raw)),
+	}, nil
+}
+
+// buildCanvasEntry indexes the links of a canvas. A malformed canvas is
+// indexed without links rather than failing the refresh.
+func buildCanvasEntry(vaultRoot, rel string, info os.FileInfo) (Entry, error) {
+	raw, err := os.ReadFile(filepath.Join(vaultRoot, filepath.FromSlash(rel)))
+	if err != nil {
+		return Entry{}, err
+	}
+	entry := Entry{Path: rel, MTime: info.ModTime().UnixNano(), Size: info.Size(), Links: []string{}}
+	if c, err := canvas.Parse(raw); err == nil {
+		entry.Links = c.Links()
+	}
+	return entry, nil
+}
+
+// jsonProperties round-trips properties through JSON so freshly indexed
+// entries hold the same value types as ones loaded from disk.
+func jsonProperties(props map[string]any) map[string]any {
+	payload, err := json.Marshal(props)
+	if err != nil {
+		return props
+	}
+	out := map[string]any{}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(&out); err != nil {
+		return props
+	}
+	return out
+}
+
+func emptySnapshot() Snapshot {
+	return Snapshot{Version: formatVersion, Files: map[string]Entry{}, Canvases: map[string]Entry{}}
+}
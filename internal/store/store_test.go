@@ -0,0 +1,208 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nightisyang/obsidian-cli/internal/tasks"
+)
+
+func writeNote(t *testing.T, root, rel, content string) {
+	t.Helper()
+	abs := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(abs, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", rel, err)
+	}
+}
+
+func TestRefreshIndexesAndPersists(t *testing.T) {
+	root := t.TempDir()
+	writeNote(t, root, "a.md", "---\nstatus: active\npriority: 3\ntags: [work]\n---\n\n# Intro\nSee [[b]] #inline\n- [ ] first ^task-one\n")
+	writeNote(t, root, "notes/b.md", "## Target\nbody")
+
+	s := Open(root, ".obsidian-cli-index")
+	snap, stats, err := s.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh error: %v", err)
+	}
+	if stats.Added != 2 || stats.Changed() != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".obsidian-cli-index", FileName)); err != nil {
+		t.Fatalf("expected index file: %v", err)
+	}
+
+	entry := snap.Files["a.md"]
+	if !reflect.DeepEqual(entry.Tags, []string{"inline", "work"}) {
+		t.Fatalf("unexpected tags: %v", entry.Tags)
+	}
+	if len(entry.Headings) != 1 || entry.Headings[0].Text != "Intro" {
+		t.Fatalf("unexpected headings: %+v", entry.Headings)
+	}
+	if !reflect.DeepEqual(entry.Blocks, []string{"task-one"}) {
+		t.Fatalf("unexpected blocks: %v", entry.Blocks)
+	}
+	if got := snap.Backlinks("notes/b.md"); !reflect.DeepEqual(got, []string{"a.md"}) {
+		t.Fatalf("unexpected backlinks: %v", got)
+	}
+	if got := snap.Tasks(tasks.ListOptions{Todo: true}); len(got) != 1 || got[0].Line != 9 {
+		t.Fatalf("unexpected tasks: %+v", got)
+	}
+
+	reloaded, persisted, err := s.Load()
+	if err != nil || !persisted {
+		t.Fatalf("Load = persisted %v, err %v", persisted, err)
+	}
+	if !reflect.DeepEqual(reloaded.Files["a.md"].Properties, entry.Properties) {
+		t.Fatalf("properties changed across reload: %v vs %v", reloaded.Files["a.md"].Properties, entry.Properties)
+	}
+}
+
+func TestRefreshIsIncremental(t *testing.T) {
+	root := t.TempDir()
+	writeNote(t, root, "a.md", "[[b]]")
+	writeNote(t, root, "b.md", "target")
+	writeNote(t, root, "c.md", "other")
+
+	s := Open(root, ".obsidian-cli-index")
+	if _, _, err := s.Refresh(); err != nil {
+		t.Fatalf("initial Refresh error: %v", err)
+	}
+
+	writeNote(t, root, "a.md", "no links")
+	future := time.Now().Add(2 * time.Second)
+	if err := os.Chtimes(filepath.Join(root, "a.md"), future, future); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if err := os.Remove(filepath.Join(root, "c.md")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	status, err := s.Status()
+	if err != nil {
+		t.Fatalf("Status error: %v", err)
+	}
+	if !status.Stale || status.Pending.Updated != 1 || status.Pending.Removed != 1 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+
+	snap, stats, err := s.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh error: %v", err)
+	}
+	want := RefreshStats{Updated: 1, Removed: 1, Unchanged: 1}
+	if stats != want {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if got := snap.Backlinks("b.md"); len(got) != 0 {
+		t.Fatalf("expected backlinks to clear, got %v", got)
+	}
+
+	removed, err := s.Clear()
+	if err != nil || !removed {
+		t.Fatalf("Clear = %v, %v", removed, err)
+	}
+	if status, err := s.Status(); err != nil || status.Exists {
+		t.Fatalf("expected cleared index, got %+v, %v", status, err)
+	}
+}
+
+func TestRefreshIndexesCanvasLinks(t *testing.T) {
+	root := t.TempDir()
+	writeNote(t, root, "a.md", "alpha")
+	writeNote(t, root, "b.md", "beta")
+	writeNote(t, root, "board.canvas", `{"nodes":[{"id":"1","type":"file","file":"a.md","x":0,"y":0,"width":10,"height":10},{"id":"2","type":"text","text":"[[b]]","x":0,"y":0,"width":10,"height":10}],"edges":[]}`)
+	writeNote(t, root, "broken.canvas", "{")
+
+	s := Open(root, "")
+	snap, stats, err := s.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh error: %v", err)
+	}
+	if stats.Added != 4 || len(snap.Files) != 2 || len(snap.Canvases) != 2 {
+		t.Fatalf("unexpected snapshot: %+v %+v", stats, snap.Canvases)
+	}
+	if got := snap.Backlinks("a.md"); !reflect.DeepEqual(got, []string{"board.canvas"}) {
+		t.Fatalf("unexpected backlinks for a.md: %v", got)
+	}
+	if got := snap.Backlinks("b.md"); !reflect.DeepEqual(got, []string{"board.canvas"}) {
+		t.Fatalf("unexpected backlinks for b.md: %v", got)
+	}
+
+	if err := os.Remove(filepath.Join(root, "board.canvas")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	snap, stats, err = s.Refresh()
+	if err != nil || stats.Removed != 1 || len(snap.Backlinks("a.md")) != 0 {
+		t.Fatalf("expected canvas removal, got %+v %v", stats, err)
+	}
+}
+
+func TestLinkReports(t *testing.T) {
+	root := t.TempDir()
+	writeNote(t, root, "hub.md", "[[a]] [[missing]]\n![[img/chart.png]] [doc](docs/gone.md)")
+	writeNote(t, root, "a.md", "back to [[hub]]")
+	writeNote(t, root, "docs/lonely.md", "see [[missing]] and [[lonely#Top]]")
+	writeNote(t, root, "docs/linked.md", "nothing")
+	writeNote(t, root, "img/chart.png", "png")
+	writeNote(t, root, "board.canvas", `{"nodes":[{"id":"1","type":"file","file":"docs/linked.md","x":0,"y":0,"width":1,"height":1},{"id":"2","type":"file","file":"nowhere.md","x":0,"y":0,"width":1,"height":1}],"edges":[]}`)
+
+	s := Open(root, "")
+	snap, _, err := s.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh error: %v", err)
+	}
+	r, err := s.Resolver(snap)
+	if err != nil {
+		t.Fatalf("Resolver error: %v", err)
+	}
+
+	unresolved := snap.Unresolved(r, "")
+	if len(unresolved) != 3 || unresolved[0].Target != "missing" || unresolved[0].Count != 2 {
+		t.Fatalf("unexpected unresolved: %+v", unresolved)
+	}
+	if occ := unresolved[0].Occurrences[0]; occ.Source != "docs/lonely.md" || occ.Line != 1 || occ.Raw != "[[missing]]" {
+		t.Fatalf("unexpected occurrence: %+v", occ)
+	}
+	if unresolved[1].Target != "docs/gone" || unresolved[1].Occurrences[0].Line != 2 || unresolved[2].Occurrences[0].Kind != "canvas" {
+		t.Fatalf("unexpected unresolved order: %+v", unresolved)
+	}
+	if got := snap.Unresolved(r, "docs"); len(got) != 1 || got[0].Count != 1 {
+		t.Fatalf("unexpected scoped unresolved: %+v", got)
+	}
+	if got := snap.Orphans(r, ""); !reflect.DeepEqual(got, []string{"docs/lonely.md"}) {
+		t.Fatalf("unexpected orphans: %v", got)
+	}
+	if got := snap.DeadEnds(r, "docs/"); !reflect.DeepEqual(got, []string{"docs/linked.md", "docs/lonely.md"}) {
+		t.Fatalf("unexpected dead ends: %v", got)
+	}
+}
+
+func TestBrokenAnchors(t *testing.T) {
+	root := t.TempDir()
+	writeNote(t, root, "plan.md", "# Plan\n## Next Steps\nship ^abc123\n")
+	writeNote(t, root, "notes/a.md", "[[plan#Next Steps]] [[plan#Later]]\n[[plan#^abc123]] [[plan#^gone99]] [[#Intro]]\n# Intro")
+
+	s := Open(root, "")
+	snap, _, err := s.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh error: %v", err)
+	}
+	r, err := s.Resolver(snap)
+	if err != nil {
+		t.Fatalf("Resolver error: %v", err)
+	}
+	got := snap.BrokenAnchors(r, "notes")
+	if len(got) != 2 || got[0].Subpath != "#Later" || got[0].Path != "plan.md" || got[1].Subpath != "#^gone99" || got[1].Line != 2 {
+		t.Fatalf("unexpected broken anchors: %+v", got)
+	}
+	if got := snap.BrokenAnchors(r, "other"); len(got) != 0 {
+		t.Fatalf("expected scoped report to be empty, got %+v", got)
+	}
+}
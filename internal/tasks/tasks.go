@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/nightisyang/obsidian-cli/internal/errs"
+	"github.com/nightisyang/obsidian-cli/internal/history"
 	"github.com/nightisyang/obsidian-cli/internal/index"
 	"github.com/nightisyang/obsidian-cli/internal/vault"
 )
@@ -96,9 +97,9 @@ func List(vaultRoot string, opts ListOptions) ([]Task, error) {
 		if err != nil {
 			return nil, err
 		}
-		tasks := tasksFromContent(path, string(raw))
+		tasks := FromContent(path, string(raw))
 		for _, t := range tasks {
-			if !matchesFilter(t, opts) {
+			if !Matches(t, opts) {
 				continue
 			}
 			out = append(out, t)
@@ -108,13 +109,18 @@ func List(vaultRoot string, opts ListOptions) ([]Task, error) {
 		}
 	}
 
-	sort.Slice(out, func(i, j int) bool {
-		if out[i].Path == out[j].Path {
-			return out[i].Line < out[j].Line
+	Sort(out)
+	return out, nil
+}
+
+// Sort orders tasks by path and then line.
+func Sort(items []Task) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Path == items[j].Path {
+			return items[i].Line < items[j].Line
 		}
-		return out[i].Path < out[j].Path
+		return items[i].Path < items[j].Path
 	})
-	return out, nil
 }
 
 func Get(vaultRoot string, ref Ref) (Task, error) {
@@ -130,7 +136,7 @@ func Get(vaultRoot string, ref Ref) (Task, error) {
 	return Task{}, errs.New(errs.ExitNotFound, "task not found")
 }
 
-func Update(vaultRoot string, ref Ref, in UpdateInput) (Task, error) {
+func Update(vaultRoot string, hist *history.Store, ref Ref, in UpdateInput) (Task, error) {
 	abs, rel, err := vault.ResolveNoteAbs(vaultRoot, ref.Path)
 	if err != nil {
 		return Task{}, err
@@ -143,46 +149,56 @@ func Update(vaultRoot string, ref Ref, in UpdateInput) (Task, error) {
 		return Task{}, err
 	}
 
-	raw := strings.ReplaceAll(string(payload), "\r\n", "\n")
+	updated, task, err := ApplyUpdate(filepath.ToSlash(rel), string(payload), ref.Line, in)
+	if err != nil {
+		return Task{}, err
+	}
+	if err := hist.WriteFile(abs, []byte(updated)); err != nil {
+		return Task{}, err
+	}
+	return task, nil
+}
+
+// ApplyUpdate rewrites the task on the given 1-based line of raw note content
+// and returns the updated content together with the resulting task.
+func ApplyUpdate(path, raw string, line int, in UpdateInput) (string, Task, error) {
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
 	hasTrailingNewline := strings.HasSuffix(raw, "\n")
 	lines := strings.Split(raw, "\n")
 	if hasTrailingNewline {
 		lines = lines[:len(lines)-1]
 	}
-	if ref.Line < 1 || ref.Line > len(lines) {
-		return Task{}, errs.New(errs.ExitNotFound, "task line out of range")
+	if line < 1 || line > len(lines) {
+		return "", Task{}, errs.New(errs.ExitNotFound, "task line out of range")
 	}
 
-	m := taskLinePattern.FindStringSubmatch(lines[ref.Line-1])
+	m := taskLinePattern.FindStringSubmatch(lines[line-1])
 	if len(m) != 5 {
-		return Task{}, errs.New(errs.ExitNotFound, "task not found at line")
+		return "", Task{}, errs.New(errs.ExitNotFound, "task not found at line")
 	}
 
 	status := m[2]
 	nextStatus, err := nextTaskStatus(status, in)
 	if err != nil {
-		return Task{}, err
+		return "", Task{}, err
 	}
 
-	lines[ref.Line-1] = m[1] + nextStatus + m[3] + m[4]
+	lines[line-1] = m[1] + nextStatus + m[3] + m[4]
 	updated := strings.Join(lines, "\n")
 	if hasTrailingNewline {
 		updated += "\n"
 	}
-	if err := os.WriteFile(abs, []byte(updated), 0o644); err != nil {
-		return Task{}, err
-	}
-
-	return Task{
-		Path:   filepath.ToSlash(rel),
-		Line:   ref.Line,
+	return updated, Task{
+		Path:   path,
+		Line:   line,
 		Status: nextStatus,
 		Text:   strings.TrimSpace(m[4]),
-		Raw:    lines[ref.Line-1],
+		Raw:    lines[line-1],
 	}, nil
 }
 
-func tasksFromContent(path, raw string) []Task {
+// FromContent extracts checkbox tasks from raw note content.
+func FromContent(path, raw string) []Task {
 	raw = strings.ReplaceAll(raw, "\r\n", "\n")
 	lines := strings.Split(raw, "\n")
 	items := []Task{}
@@ -202,7 +218,8 @@ func tasksFromContent(path, raw string) []Task {
 	return items
 }
 
-func matchesFilter(task Task, opts ListOptions) bool {
+// Matches reports whether a task passes the status filters in opts.
+func Matches(task Task, opts ListOptions) bool {
 	if opts.Status != "" && task.Status != opts.Status {
 		return false
 	}
@@ -45,7 +45,7 @@ func TestListFiltersAndUpdate(t *testing.T) {
 		t.Fatalf("unexpected custom results: %+v", custom)
 	}
 
-	updated, err := Update(root, Ref{Path: "notes/a.md", Line: 1}, UpdateInput{Toggle: true})
+	updated, err := Update(root, nil, Ref{Path: "notes/a.md", Line: 1}, UpdateInput{Toggle: true})
 	if err != nil {
 		t.Fatalf("Update toggle error: %v", err)
 	}
@@ -0,0 +1,424 @@
+package vault
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CheckpointEnv names the folder of the checkpoint an atomic ops batch
+// runs against. Commands started with it set save each path there, with
+// SaveToCheckpoint, before first changing it.
+const CheckpointEnv = "OBSIDIAN_CLI_CHECKPOINT"
+
+const checkpointManifest = "manifest.jsonl"
+
+// FileState is what a Checkpoint compares to notice that a file changed.
+type FileState struct {
+	Size    int64
+	ModTime time.Time
+	Mode    fs.FileMode
+}
+
+// Checkpoint records the vault before a batch of changes. Only the paths
+// the batch touches are copied, when they are first touched; every other
+// file is tracked by size and modification time so that edits made
+// outside the batch are noticed.
+type Checkpoint struct {
+	Dir      string
+	root     string
+	exclude  []string
+	Files    map[string]FileState
+	dirs     map[string]struct{}
+	expected map[string]string
+}
+
+// checkpointEntry is one saved path, copied under the name checkpointKey
+// gives it. Hash is empty when the path did not exist.
+type checkpointEntry struct {
+	Path    string      `json:"path"`
+	Hash    string      `json:"hash"`
+	Mode    fs.FileMode `json:"mode"`
+	ModTime time.Time   `json:"mod_time"`
+}
+
+// RollbackReport lists what Restore put back. Conflicts are changed files
+// that were left alone because someone else modified them.
+type RollbackReport struct {
+	Restored  []string `json:"restored"`
+	Removed   []string `json:"removed"`
+	Conflicts []string `json:"conflicts"`
+}
+
+// NewCheckpoint creates an empty checkpoint folder and records the state
+// of every vault file. exclude lists vault-relative paths, such as the
+// index dir, that are never compared.
+func NewCheckpoint(vaultRoot string, exclude ...string) (*Checkpoint, error) {
+	dir, err := os.MkdirTemp("", "obsidian-cli-checkpoint-")
+	if err != nil {
+		return nil, err
+	}
+	c := &Checkpoint{Dir: dir, root: vaultRoot, exclude: exclude, dirs: map[string]struct{}{}, expected: map[string]string{}}
+	c.Files, err = c.scan(func(rel, _ string, d fs.DirEntry) error {
+		if d.IsDir() {
+			c.dirs[rel] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	return c, nil
+}
+
+// SaveToCheckpoint copies the file or folder at rel into the checkpoint
+// folder dir, together with its content hash, unless an earlier change of
+// the batch already saved it. The checkpoint so keeps each path as it was
+// before the batch first touched it.
+func SaveToCheckpoint(dir, vaultRoot, rel string) error {
+	key := checkpointKey(rel)
+	marker := filepath.Join(dir, key+".saved")
+	if _, err := os.Lstat(marker); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	abs := filepath.Join(vaultRoot, filepath.FromSlash(rel))
+	entry := checkpointEntry{Path: rel}
+	if info, statErr := os.Stat(abs); statErr == nil {
+		var err error
+		if entry.Hash, err = hashPath(abs); err != nil {
+			return err
+		}
+		entry.Mode, entry.ModTime = info.Mode(), info.ModTime()
+		if err := copyPath(abs, filepath.Join(dir, key)); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(statErr) {
+		return statErr
+	}
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, checkpointManifest), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(payload, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(marker, nil, 0o644)
+}
+
+// Expect records the current content of paths, which an operation of the
+// batch just changed, as what Restore should find there.
+func (c *Checkpoint) Expect(paths []string) error {
+	for _, rel := range paths {
+		hash, err := hashPath(filepath.Join(c.root, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		c.expected[rel] = hash
+	}
+	return nil
+}
+
+// Unexpected lists the paths the batch changed earlier whose content no
+// longer matches what the batch left there, skipping paths in or under
+// skip. It catches outside edits that keep a file's size and modification
+// time.
+func (c *Checkpoint) Unexpected(skip []string) ([]string, error) {
+	out := []string{}
+	for rel, expected := range c.expected {
+		if underAny(rel, skip) {
+			continue
+		}
+		current, err := hashPath(filepath.Join(c.root, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, err
+		}
+		if current != expected {
+			out = append(out, rel)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// Scan returns the current state of every file the checkpoint covers.
+func (c *Checkpoint) Scan() (map[string]FileState, error) {
+	return c.scan(nil)
+}
+
+// Changed lists the paths added, removed or modified between two scans.
+func Changed(before, after map[string]FileState) []string {
+	out := []string{}
+	for rel, b := range before {
+		if a, ok := after[rel]; !ok || a.Size != b.Size || !a.ModTime.Equal(b.ModTime) || a.Mode != b.Mode {
+			out = append(out, rel)
+		}
+	}
+	for rel := range after {
+		if _, ok := before[rel]; !ok {
+			out = append(out, rel)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Restore puts every saved path back as it was, latest saved first, and
+// removes folders created since the checkpoint. A path whose content
+// differs from what the batch left there was changed by someone else; it
+// is reported as a conflict and never overwritten.
+func (c *Checkpoint) Restore() (RollbackReport, error) {
+	report := RollbackReport{Restored: []string{}, Removed: []string{}, Conflicts: []string{}}
+	entries, err := readCheckpointEntries(c.Dir)
+	if err != nil {
+		return report, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		abs := filepath.Join(c.root, filepath.FromSlash(e.Path))
+		current, err := hashPath(abs)
+		if err != nil {
+			return report, err
+		}
+		if current == e.Hash {
+			continue
+		}
+		if expected, ok := c.expected[e.Path]; !ok || current != expected {
+			report.Conflicts = append(report.Conflicts, e.Path)
+			continue
+		}
+		if err := os.RemoveAll(abs); err != nil {
+			return report, err
+		}
+		if e.Hash == "" {
+			report.Removed = append(report.Removed, e.Path)
+			continue
+		}
+		if err := copyPath(filepath.Join(c.Dir, checkpointKey(e.Path)), abs); err != nil {
+			return report, err
+		}
+		if err := os.Chmod(abs, e.Mode); err != nil {
+			return report, err
+		}
+		if err := os.Chtimes(abs, e.ModTime, e.ModTime); err != nil {
+			return report, err
+		}
+		report.Restored = append(report.Restored, e.Path)
+	}
+	sort.Strings(report.Restored)
+	sort.Strings(report.Removed)
+	sort.Strings(report.Conflicts)
+
+	// Drop empty folders created since the checkpoint, deepest first, and
+	// bring back folders that were removed.
+	dirs := []string{}
+	if _, err := c.scan(func(rel, _ string, d fs.DirEntry) error {
+		if _, ok := c.dirs[rel]; d.IsDir() && !ok {
+			dirs = append(dirs, rel)
+		}
+		return nil
+	}); err != nil {
+		return report, err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+	for _, rel := range dirs {
+		_ = os.Remove(filepath.Join(c.root, filepath.FromSlash(rel)))
+	}
+	for rel := range c.dirs {
+		if err := os.MkdirAll(filepath.Join(c.root, filepath.FromSlash(rel)), 0o755); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// Close removes the checkpoint folder.
+func (c *Checkpoint) Close() error {
+	return os.RemoveAll(c.Dir)
+}
+
+// scan walks the covered vault files, calling visit for each file and
+// folder below the root when set.
+func (c *Checkpoint) scan(visit func(rel, abs string, d fs.DirEntry) error) (map[string]FileState, error) {
+	files := map[string]FileState{}
+	err := filepath.WalkDir(c.root, func(abs string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if abs == c.root {
+			return nil
+		}
+		rel, err := filepath.Rel(c.root, abs)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if c.excluded(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if visit != nil {
+			if err := visit(rel, abs, d); err != nil {
+				return err
+			}
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files[rel] = FileState{Size: info.Size(), ModTime: info.ModTime(), Mode: info.Mode()}
+		return nil
+	})
+	return files, err
+}
+
+func (c *Checkpoint) excluded(rel string) bool {
+	for _, ex := range c.exclude {
+		if rel == ex || strings.HasPrefix(rel, ex+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkpointKey names the copy of rel in the checkpoint folder.
+func checkpointKey(rel string) string {
+	digest := sha256.Sum256([]byte(rel))
+	return hex.EncodeToString(digest[:])
+}
+
+// underAny reports whether rel is one of paths or inside one of them.
+func underAny(rel string, paths []string) bool {
+	for _, p := range paths {
+		if rel == p || strings.HasPrefix(rel, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func readCheckpointEntries(dir string) ([]checkpointEntry, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, checkpointManifest))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []checkpointEntry{}, nil
+		}
+		return nil, err
+	}
+	entries := []checkpointEntry{}
+	for _, line := range strings.Split(strings.TrimSpace(string(raw)), "\n") {
+		if line == "" {
+			continue
+		}
+		var e checkpointEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// hashPath hashes the content of a file, or of every file below a folder
+// together with its path. A missing path hashes to "".
+func hashPath(abs string) (string, error) {
+	info, err := os.Stat(abs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	sum := sha256.New()
+	if !info.IsDir() {
+		raw, err := os.ReadFile(abs)
+		if err != nil {
+			return "", err
+		}
+		sum.Write(raw)
+		return hex.EncodeToString(sum.Sum(nil)), nil
+	}
+	err = filepath.WalkDir(abs, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil || !d.Type().IsRegular() {
+			return walkErr
+		}
+		rel, err := filepath.Rel(abs, path)
+		if err != nil {
+			return err
+		}
+		fileHash, err := hashPath(path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(sum, "%s\x00%s\n", filepath.ToSlash(rel), fileHash)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return "dir:" + hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// copyPath copies a file, or a folder with every file below it.
+func copyPath(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,112 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheckpointRestoresTouchedFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"a.md":                  "alpha",
+		"notes/b.md":            "[[a]]",
+		"keep.md":               "untouched",
+		".obsidian-cli-index/x": "index",
+	}
+	for rel, content := range files {
+		abs := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(abs, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	c, err := NewCheckpoint(root, ".obsidian-cli-index")
+	if err != nil {
+		t.Fatalf("checkpoint: %v", err)
+	}
+	defer c.Close()
+
+	// A batch saves each path before first changing it; saving again keeps
+	// the first copy.
+	save := func(rels ...string) {
+		t.Helper()
+		for _, rel := range rels {
+			if err := SaveToCheckpoint(c.Dir, root, rel); err != nil {
+				t.Fatalf("save %s: %v", rel, err)
+			}
+		}
+	}
+	save("a.md", "moved/a.md", "notes/b.md", "keep.md")
+	if err := os.MkdirAll(filepath.Join(root, "moved"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.Rename(filepath.Join(root, "a.md"), filepath.Join(root, "moved", "a.md")); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	for rel, content := range map[string]string{"notes/b.md": "[[moved/a]]", "keep.md": "batch edit"} {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(rel)), []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	save("notes/b.md")
+	if saved, _ := os.ReadDir(c.Dir); len(saved) != 8 {
+		t.Fatalf("expected the manifest, four markers and three copies, got %d entries", len(saved))
+	}
+	if err := c.Expect([]string{"a.md", "moved/a.md", "notes/b.md", "keep.md"}); err != nil {
+		t.Fatalf("expect: %v", err)
+	}
+	before, err := c.Scan()
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	// An outside edit that keeps size and mtime is only seen by its hash.
+	keep := filepath.Join(root, "keep.md")
+	info, _ := os.Stat(keep)
+	if err := os.WriteFile(keep, []byte("BATCH EDIT"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Chtimes(keep, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".obsidian-cli-index", "x"), []byte("rebuilt"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	after, err := c.Scan()
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if changed := Changed(before, after); len(changed) != 0 {
+		t.Fatalf("expected the edit to keep size and mtime, got %v", changed)
+	}
+	if unexpected, err := c.Unexpected([]string{"notes"}); err != nil || !reflect.DeepEqual(unexpected, []string{"keep.md"}) {
+		t.Fatalf("unexpected = %v (%v)", unexpected, err)
+	}
+
+	report, err := c.Restore()
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	want := RollbackReport{Restored: []string{"a.md", "notes/b.md"}, Removed: []string{"moved/a.md"}, Conflicts: []string{"keep.md"}}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("report = %+v, want %+v", report, want)
+	}
+	for rel, content := range map[string]string{"a.md": "alpha", "notes/b.md": "[[a]]", "keep.md": "BATCH EDIT", ".obsidian-cli-index/x": "rebuilt"} {
+		if raw, _ := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel))); string(raw) != content {
+			t.Fatalf("%s = %q, want %q", rel, raw, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "moved")); !os.IsNotExist(err) {
+		t.Fatalf("expected created folder to be removed, got %v", err)
+	}
+	now, err := c.Scan()
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if changed := Changed(c.Files, now); !reflect.DeepEqual(changed, []string{"keep.md"}) {
+		t.Fatalf("changed after restore = %v", changed)
+	}
+}
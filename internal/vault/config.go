@@ -11,21 +11,27 @@ import (
 )
 
 type Config struct {
-	VaultPath    string        `yaml:"vault_path" json:"vault_path"`
-	ModeDefault  string        `yaml:"mode_default" json:"mode_default"`
-	APIBaseURL   string        `yaml:"api_base_url" json:"api_base_url"`
-	APITimeout   time.Duration `yaml:"-" json:"api_timeout"`
-	TemplatesDir string        `yaml:"templates_dir" json:"templates_dir"`
-	IndexDir     string        `yaml:"index_dir" json:"index_dir"`
+	VaultPath     string        `yaml:"vault_path" json:"vault_path"`
+	ModeDefault   string        `yaml:"mode_default" json:"mode_default"`
+	APIBaseURL    string        `yaml:"api_base_url" json:"api_base_url"`
+	APIToken      string        `yaml:"api_token" json:"-"`
+	APITimeout    time.Duration `yaml:"-" json:"api_timeout"`
+	TemplatesDir  string        `yaml:"templates_dir" json:"templates_dir"`
+	IndexDir      string        `yaml:"index_dir" json:"index_dir"`
+	HistoryKeep   int           `yaml:"history_keep" json:"history_keep"`
+	GitAutoCommit bool          `yaml:"git_auto_commit" json:"git_auto_commit"`
 }
 
 type fileConfig struct {
-	VaultPath    string `yaml:"vault_path"`
-	ModeDefault  string `yaml:"mode_default"`
-	APIBaseURL   string `yaml:"api_base_url"`
-	APITimeout   string `yaml:"api_timeout"`
-	TemplatesDir string `yaml:"templates_dir"`
-	IndexDir     string `yaml:"index_dir"`
+	VaultPath     string `yaml:"vault_path"`
+	ModeDefault   string `yaml:"mode_default"`
+	APIBaseURL    string `yaml:"api_base_url"`
+	APIToken      string `yaml:"api_token,omitempty"`
+	APITimeout    string `yaml:"api_timeout"`
+	TemplatesDir  string `yaml:"templates_dir"`
+	IndexDir      string `yaml:"index_dir"`
+	HistoryKeep   int    `yaml:"history_keep,omitempty"`
+	GitAutoCommit *bool  `yaml:"git_auto_commit,omitempty"`
 }
 
 type Resolved struct {
@@ -43,6 +49,7 @@ func DefaultConfig() Config {
 		APITimeout:   5 * time.Second,
 		TemplatesDir: ".obsidian/templates",
 		IndexDir:     ".obsidian-cli-index",
+		HistoryKeep:  50,
 	}
 }
 
@@ -114,9 +121,14 @@ func WriteConfig(path string, cfg Config, force bool) error {
 		VaultPath:    cfg.VaultPath,
 		ModeDefault:  cfg.ModeDefault,
 		APIBaseURL:   cfg.APIBaseURL,
+		APIToken:     cfg.APIToken,
 		APITimeout:   cfg.APITimeout.String(),
 		TemplatesDir: cfg.TemplatesDir,
 		IndexDir:     cfg.IndexDir,
+		HistoryKeep:  cfg.HistoryKeep,
+	}
+	if cfg.GitAutoCommit {
+		fc.GitAutoCommit = &cfg.GitAutoCommit
 	}
 	payload, err := yaml.Marshal(fc)
 	if err != nil {
@@ -148,6 +160,9 @@ func mergeConfig(base Config, override fileConfig) Config {
 	if override.APIBaseURL != "" {
 		cfg.APIBaseURL = override.APIBaseURL
 	}
+	if override.APIToken != "" {
+		cfg.APIToken = override.APIToken
+	}
 	if override.APITimeout != "" {
 		if d, err := time.ParseDuration(override.APITimeout); err == nil {
 			cfg.APITimeout = d
@@ -159,5 +174,13 @@ func mergeConfig(base Config, override fileConfig) Config {
 	if override.IndexDir != "" {
 		cfg.IndexDir = override.IndexDir
 	}
+	// A negative history_keep turns note history off.
+	if override.HistoryKeep != 0 {
+		cfg.HistoryKeep = override.HistoryKeep
+	}
+	// A pointer, so an explicit false overrides a true from a lower layer.
+	if override.GitAutoCommit != nil {
+		cfg.GitAutoCommit = *override.GitAutoCommit
+	}
 	return cfg
 }
@@ -0,0 +1,26 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMergeConfigGitAutoCommitFalseOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".obsidian-cli.yaml")
+	if err := os.WriteFile(path, []byte("git_auto_commit: false\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	loaded, err := loadFileConfig(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	base := DefaultConfig()
+	base.GitAutoCommit = true
+	if cfg := mergeConfig(base, loaded); cfg.GitAutoCommit {
+		t.Fatal("expected git_auto_commit: false to turn auto-commit off")
+	}
+	if cfg := mergeConfig(base, fileConfig{}); !cfg.GitAutoCommit {
+		t.Fatal("expected an unset git_auto_commit to keep the base value")
+	}
+}
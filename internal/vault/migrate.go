@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/nightisyang/obsidian-cli/internal/frontmatter"
+	"github.com/nightisyang/obsidian-cli/internal/history"
 )
 
 type MigrateOptions struct {
@@ -27,7 +28,7 @@ type MigrationResult struct {
 	Files    []MigrationFile `json:"files"`
 }
 
-func MigrateFrontmatter(vaultRoot string, opts MigrateOptions) (MigrationResult, error) {
+func MigrateFrontmatter(vaultRoot string, hist *history.Store, opts MigrateOptions) (MigrationResult, error) {
 	kind := strings.TrimSpace(opts.Kind)
 	if kind == "" {
 		kind = "note"
@@ -87,7 +88,7 @@ func MigrateFrontmatter(vaultRoot string, opts MigrateOptions) (MigrationResult,
 		}
 		createdAt := info.ModTime().UTC().Format(time.RFC3339)
 		updated := migrationHeader(kind, createdAt) + string(payload)
-		if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+		if err := hist.WriteFile(path, []byte(updated)); err != nil {
 			return err
 		}
 
@@ -18,7 +18,7 @@ func TestMigrateFrontmatterDryRun(t *testing.T) {
 		t.Fatalf("write frontmatter note: %v", err)
 	}
 
-	result, err := MigrateFrontmatter(root, MigrateOptions{DryRun: true, Kind: "task"})
+	result, err := MigrateFrontmatter(root, nil, MigrateOptions{DryRun: true, Kind: "task"})
 	if err != nil {
 		t.Fatalf("MigrateFrontmatter dry-run error: %v", err)
 	}
@@ -53,7 +53,7 @@ func TestMigrateFrontmatterWritesHeader(t *testing.T) {
 		t.Fatalf("chtimes: %v", err)
 	}
 
-	result, err := MigrateFrontmatter(root, MigrateOptions{Kind: "idea"})
+	result, err := MigrateFrontmatter(root, nil, MigrateOptions{Kind: "idea"})
 	if err != nil {
 		t.Fatalf("MigrateFrontmatter error: %v", err)
 	}
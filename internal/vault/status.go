@@ -10,7 +10,10 @@ type Status struct {
 	Root          string `json:"root"`
 	ConfigPath    string `json:"config_path,omitempty"`
 	ConfigSource  string `json:"config_source"`
+	RequestedMode string `json:"requested_mode,omitempty"`
 	EffectiveMode string `json:"effective_mode"`
+	APIReachable  bool   `json:"api_reachable"`
+	ModeReason    string `json:"mode_reason,omitempty"`
 	NoteCount     int    `json:"note_count"`
 	TagCount      int    `json:"tag_count"`
 }